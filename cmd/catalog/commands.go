@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/deployment/migrations"
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	ingest "github.com/eduardojabes/data-integration-challenge/internal/pkg/ingest/company"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+// SeedReport tells how many companies the seed loaded
+type SeedReport struct {
+	File   string `json:"file"`
+	Before int    `json:"before"`
+	Seeded int    `json:"seeded"`
+}
+
+// ExportReport tells how many companies the export wrote
+type ExportReport struct {
+	Format    string `json:"format"`
+	Output    string `json:"output"`
+	Companies int    `json:"companies"`
+}
+
+// DedupeReport lists the duplicate candidate pairs found
+type DedupeReport struct {
+	Candidates []entity.DuplicateCandidate `json:"candidates"`
+}
+
+// CanonicalizeReport tells how many stored websites were rewritten
+type CanonicalizeReport struct {
+	Updated int `json:"updated"`
+}
+
+// MigrateReport lists the migrations applied
+type MigrateReport struct {
+	Applied []entity.Migration `json:"applied"`
+}
+
+// MigrationStatusReport lists every migration telling whether it is applied
+type MigrationStatusReport struct {
+	Migrations []entity.Migration `json:"migrations"`
+}
+
+// seed loads the catalog file, only when the database has no company
+func seed(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "seed", "")
+	path := flags.String("file", "./data/q1_catalog.csv", "catalog file loaded into the empty database")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	before, err := env.service.GetCompanies()
+	if err != nil {
+		return err
+	}
+	if err := env.service.InitializeDataBase(ctx, *path); err != nil {
+		return err
+	}
+	after, err := env.service.GetCompanies()
+	if err != nil {
+		return err
+	}
+
+	seedReport := SeedReport{File: *path, Before: len(before), Seeded: len(after) - len(before)}
+	return report(env.stdout, *asJSON, seedReport, func(w io.Writer) {
+		if seedReport.Before > 0 {
+			fmt.Fprintf(w, "The catalog already has %d companies, nothing was seeded\n", seedReport.Before)
+			return
+		}
+		fmt.Fprintf(w, "Seeded %d companies from %s\n", seedReport.Seeded, seedReport.File)
+	})
+}
+
+// readOptions tells how a file is read from the values of the flags,
+// loading the import profile when one is named
+func readOptions(ctx context.Context, env *environment, format *string, sheet *string, profileName *string) (ingest.Options, error) {
+	options := ingest.Options{Format: *format, Sheet: *sheet}
+	if *profileName == "" {
+		return options, nil
+	}
+
+	profile, err := env.service.GetImportProfile(ctx, *profileName)
+	if err != nil {
+		return options, err
+	}
+	if profile == nil {
+		return options, fmt.Errorf("%w: %s", companyService.ERR_IMPORT_PROFILE_NOT_FOUND, *profileName)
+	}
+	options.Profile = profile
+	return options, nil
+}
+
+// merge merges a file as one import job. It fails when a record was rejected
+// or could not be merged.
+func merge(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "merge", "<file>")
+	format := flags.String("format", "", "csv, json, ndjson or xlsx, detected from the file name when empty")
+	sheet := flags.String("sheet", "", "sheet of a workbook, the first one when empty")
+	profileName := flags.String("profile", "", "import profile the file is read with")
+	system := flags.String("source", companyService.SOURCE_CLIENT, "source system of the file")
+	priority := flags.Int("priority", 0, "priority of the source, the one of the profile or the source system when 0")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	options, err := readOptions(ctx, env, format, sheet, profileName)
+	if err != nil {
+		return err
+	}
+	companies, err := ingest.ReadFile(ctx, flags.Arg(0), options)
+	if err != nil {
+		return err
+	}
+
+	// the operator running the command may set the source, unlike uploads
+	source := companyService.ProfileSource(*system, filepath.Base(flags.Arg(0)), options.Profile)
+	if *priority > 0 {
+		source.Priority = *priority
+	}
+
+	mergeReport := env.service.MergeCompanies(ctx, companies, source)
+	err = report(env.stdout, *asJSON, mergeReport, func(w io.Writer) {
+		fmt.Fprintf(w, "Import job %s of %s\n", mergeReport.ImportJob, mergeReport.SourceFile)
+		for _, result := range mergeReport.Results {
+			if result.Status == entity.MERGE_STATUS_REJECTED || result.Status == entity.MERGE_STATUS_FAILED {
+				fmt.Fprintf(w, "  line %d %s %s: %s, %s\n", result.Line, result.Name, result.Zip, result.Status, result.Error)
+			}
+		}
+		fmt.Fprintf(w, "%d records: %d merged, %d unchanged, %d not found, %d rejected, %d failed\n",
+			mergeReport.Total, mergeReport.Merged, mergeReport.Unchanged, mergeReport.NotFound, mergeReport.Rejected, mergeReport.Failed)
+	})
+	if err != nil {
+		return err
+	}
+
+	if mergeReport.Rejected > 0 || mergeReport.Failed > 0 {
+		return fmt.Errorf("%d records rejected and %d failed", mergeReport.Rejected, mergeReport.Failed)
+	}
+	return nil
+}
+
+// export writes the companies of the filter to a file or the standard
+// output, the report then going to the standard error
+func export(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "export", "")
+	format := flags.String("format", recordsRepository.FORMAT_CSV, "csv, ndjson or parquet")
+	output := flags.String("output", "", "file written, the standard output when empty")
+	var filter entity.CompanyFilter
+	flags.StringVar(&filter.Name, "name", "", "only the companies whose name contains the value")
+	flags.StringVar(&filter.Zip, "zip", "", "only the companies of the zip code")
+	flags.StringVar(&filter.Country, "country", "", "only the companies of the country")
+	flags.BoolVar(&filter.ZipUnknown, "zip-unknown", false, "only the companies whose zip code is not in the reference")
+	flags.BoolVar(&filter.IncludeDeleted, "include-deleted", false, "include the deleted companies")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if *format != recordsRepository.FORMAT_CSV && *format != recordsRepository.FORMAT_NDJSON && *format != recordsRepository.FORMAT_PARQUET {
+		fmt.Fprintf(env.stderr, "format must be csv, ndjson or parquet\n")
+		return errUsage
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	var out io.Writer = env.stdout
+	reportOut := env.stderr
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out, reportOut = file, env.stdout
+	}
+
+	attributes := []string{}
+	for _, definition := range env.service.AttributeSchema() {
+		attributes = append(attributes, definition.Name)
+	}
+	recordWriter, err := recordsRepository.NewRecordWriter(*format, out, attributes)
+	if err != nil {
+		return err
+	}
+
+	exportReport := ExportReport{Format: *format, Output: *output}
+	err = env.service.ExportCompanies(ctx, filter, func(company *entity.Companies) error {
+		exportReport.Companies++
+		return recordWriter.Write(company)
+	})
+	if err != nil {
+		return err
+	}
+	if err := recordWriter.Close(); err != nil {
+		return err
+	}
+
+	return report(reportOut, *asJSON, exportReport, func(w io.Writer) {
+		destination := exportReport.Output
+		if destination == "" {
+			destination = "the standard output"
+		}
+		fmt.Fprintf(w, "Exported %d companies as %s to %s\n", exportReport.Companies, exportReport.Format, destination)
+	})
+}
+
+// validate checks the records of a file the way a merge would, storing
+// nothing. It fails when a record is not valid.
+func validate(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "validate", "<file>")
+	format := flags.String("format", "", "csv, json, ndjson or xlsx, detected from the file name when empty")
+	sheet := flags.String("sheet", "", "sheet of a workbook, the first one when empty")
+	profileName := flags.String("profile", "", "import profile the file is read with")
+	match := flags.Bool("match", false, "match the records to the stored companies, without writing anything")
+	system := flags.String("source", companyService.SOURCE_CLIENT, "source system the records are matched as")
+	if err := parseFlags(flags, args, 1); err != nil {
+		return err
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	options, err := readOptions(ctx, env, format, sheet, profileName)
+	if err != nil {
+		return err
+	}
+	companies, err := ingest.ReadFile(ctx, flags.Arg(0), options)
+	if err != nil {
+		return err
+	}
+
+	var source *entity.Source
+	if *match {
+		matchSource := companyService.ProfileSource(*system, filepath.Base(flags.Arg(0)), options.Profile)
+		source = &matchSource
+	}
+	validationReport, err := env.service.ValidateCompanies(ctx, companies, source)
+	if err != nil {
+		return err
+	}
+	validationReport.SourceFile = filepath.Base(flags.Arg(0))
+	err = report(env.stdout, *asJSON, validationReport, func(w io.Writer) {
+		for _, result := range validationReport.Results {
+			problems := append(append([]string{}, result.Errors...), result.Warnings...)
+			if len(problems) > 0 {
+				fmt.Fprintf(w, "  line %d %s %s: %s\n", result.Line, result.Name, result.Zip, strings.Join(problems, "; "))
+			}
+		}
+		fmt.Fprintf(w, "%d records of %s: %d valid, %d not valid\n", validationReport.Total, validationReport.SourceFile, validationReport.Valid, validationReport.Invalid)
+		if source != nil {
+			fmt.Fprintf(w, "%d matched a stored company, %d did not\n", validationReport.Matched, validationReport.NotFound)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if validationReport.Invalid > 0 {
+		return fmt.Errorf("%d records are not valid", validationReport.Invalid)
+	}
+	return nil
+}
+
+// dedupe records the pairs of companies that likely are the same, for an
+// operator to review through the API
+func dedupe(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "dedupe", "")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	candidates, err := env.service.DetectDuplicates(ctx)
+	if err != nil {
+		return err
+	}
+
+	dedupeReport := DedupeReport{Candidates: candidates}
+	return report(env.stdout, *asJSON, dedupeReport, func(w io.Writer) {
+		for _, candidate := range dedupeReport.Candidates {
+			fmt.Fprintf(w, "  %s and %s: %.2f\n", candidate.CompanyID, candidate.DuplicateID, candidate.Score)
+		}
+		fmt.Fprintf(w, "Found %d duplicate candidate pairs\n", len(dedupeReport.Candidates))
+	})
+}
+
+// canonicalize rewrites the websites stored before they were kept in
+// canonical form. It is run once after upgrading, companies written since
+// already have canonical websites.
+func canonicalize(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "canonicalize", "")
+	if err := parseFlags(flags, args, 0); err != nil {
+		return err
+	}
+	if err := env.connect(ctx, true); err != nil {
+		return err
+	}
+
+	updated, err := env.service.CanonicalizeWebsites(ctx)
+	if err != nil {
+		return err
+	}
+
+	canonicalizeReport := CanonicalizeReport{Updated: updated}
+	return report(env.stdout, *asJSON, canonicalizeReport, func(w io.Writer) {
+		fmt.Fprintf(w, "Canonicalized the website of %d companies\n", canonicalizeReport.Updated)
+	})
+}
+
+// migrate applies the migrations that are not applied yet, or lists them
+// all with their status. The migrations embedded in the binary are used
+// unless a directory is given.
+func migrate(ctx context.Context, env *environment, args []string) error {
+	flags, asJSON := newFlagSet(env, "migrate", "[up|status]")
+	dir := flags.String("dir", "", "directory of the migrations, the ones of the binary when empty")
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	action := "up"
+	if flags.NArg() > 0 {
+		action = flags.Arg(0)
+	}
+	if flags.NArg() > 1 || (action != "up" && action != "status") {
+		flags.Usage()
+		return errUsage
+	}
+	if err := env.connect(ctx, false); err != nil {
+		return err
+	}
+
+	var fsys fs.FS = migrations.FS
+	if *dir != "" {
+		fsys = os.DirFS(*dir)
+	}
+
+	if action == "status" {
+		status, err := env.repository.MigrationStatus(ctx, fsys)
+		if err != nil {
+			return err
+		}
+		return report(env.stdout, *asJSON, MigrationStatusReport{Migrations: status}, func(w io.Writer) {
+			for _, migration := range status {
+				state := "pending"
+				if migration.Applied {
+					state = "applied"
+					if migration.AppliedAt != nil {
+						state += " " + migration.AppliedAt.Format("2006-01-02 15:04:05")
+					}
+				}
+				fmt.Fprintf(w, "%-27s %d_%s\n", state, migration.Version, migration.Name)
+			}
+		})
+	}
+
+	applied, err := env.repository.Migrate(ctx, fsys)
+	migrateReport := MigrateReport{Applied: applied}
+	if reportErr := report(env.stdout, *asJSON, migrateReport, func(w io.Writer) {
+		for _, migration := range migrateReport.Applied {
+			fmt.Fprintf(w, "Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if len(migrateReport.Applied) == 0 && err == nil {
+			fmt.Fprintf(w, "The schema is up to date\n")
+		}
+	}); reportErr != nil {
+		return reportErr
+	}
+	return err
+}
@@ -2,16 +2,21 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/eduardojabes/data-integration-challenge/deployment/migrations"
 	csvRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/csv"
 	dbRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/postgreSQL"
 	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	watcher "github.com/eduardojabes/data-integration-challenge/internal/pkg/watcher/company"
 	routes "github.com/eduardojabes/data-integration-challenge/module/features/routes/company"
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 var (
@@ -20,17 +25,66 @@ var (
 )
 
 func main() {
+	// Several replicas can start with the flag, the migrations are applied
+	// under an advisory lock by the first one
+	migrate := flag.Bool("migrate", os.Getenv("MIGRATE_ON_START") == "true", "apply the pending schema migrations on start up")
+	flag.Parse()
+
 	ctx, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 
-	// Set up connection to DB
-	conn, err := pgx.Connect(context.Background(), DatabaseUrl)
+	// Set up the pool of connections to DB, shared by the handlers and the
+	// background jobs
+	conn, err := pgxpool.Connect(context.Background(), DatabaseUrl)
 	if err != nil {
 		log.Fatalf("Unable to connect to database: %v\n", err)
 	}
 
+	// Field merge policies such as "website=fill-if-empty,zipCode=overwrite"
+	mergePolicies, err := companyService.ParseMergePolicies(os.Getenv("MERGE_POLICIES"))
+	if err != nil {
+		log.Fatalf("Unable to read merge policies: %v\n", err)
+	}
+
+	// Order of the strategies matching merged records such as "crosswalk,website,name"
+	matchStrategies, err := companyService.ParseMatchStrategies(os.Getenv("MATCH_STRATEGIES"))
+	if err != nil {
+		log.Fatalf("Unable to read match strategies: %v\n", err)
+	}
+
 	dbRepository := dbRepository.NewPostgreCompanyRepository(conn)
+	if *migrate {
+		applied, err := dbRepository.Migrate(ctx, migrations.FS)
+		if err != nil {
+			log.Fatalf("Unable to apply the schema migrations: %v\n", err)
+		}
+		for _, migration := range applied {
+			log.Printf("Applied migration %d_%s\n", migration.Version, migration.Name)
+		}
+	} else {
+		status, err := dbRepository.MigrationStatus(ctx, migrations.FS)
+		if err != nil {
+			log.Fatalf("Unable to read the schema migrations: %v\n", err)
+		}
+		for _, migration := range status {
+			if !migration.Applied {
+				log.Fatalf("Migration %d_%s is pending, start with -migrate or run go run ./cmd/catalog migrate\n", migration.Version, migration.Name)
+			}
+		}
+	}
 	csvRepository := csvRepository.NewCompanyCSVRepository()
 	companyService := companyService.NewCompanyService(dbRepository, csvRepository)
+	if err := companyService.LoadAttributeSchema(ctx); err != nil {
+		log.Fatalf("Unable to load the company attribute schema: %v\n", err)
+	}
+	if err := companyService.LoadZipReference(ctx); err != nil {
+		log.Fatalf("Unable to load the zip code reference: %v\n", err)
+	}
+	if err := companyService.SetMergePolicies(mergePolicies); err != nil {
+		log.Fatalf("Unable to set merge policies: %v\n", err)
+	}
+	if err := companyService.SetMatchStrategies(matchStrategies); err != nil {
+		log.Fatalf("Unable to set match strategies: %v\n", err)
+	}
 
 	httpConector := routes.NewHandler()
 	httpConector.ImplementConnector(companyService)
@@ -38,6 +92,41 @@ func main() {
 	path := "./data/q1_catalog.csv"
 	companyService.InitializeDataBase(ctx, path)
 
+	// Periodic duplicate detection such as "24h", off when unset
+	if interval := os.Getenv("DUPLICATE_DETECTION_INTERVAL"); interval != "" {
+		every, err := time.ParseDuration(interval)
+		if err != nil || every <= 0 {
+			log.Fatalf("Unable to read duplicate detection interval: %v\n", interval)
+		}
+		go detectDuplicates(ctx, companyService, every)
+	}
+
+	// Interval between two rounds of webhook deliveries, 10s when unset
+	webhookInterval := 10 * time.Second
+	if interval := os.Getenv("WEBHOOK_INTERVAL"); interval != "" {
+		webhookInterval, err = time.ParseDuration(interval)
+		if err != nil || webhookInterval <= 0 {
+			log.Fatalf("Unable to read webhook interval: %v\n", interval)
+		}
+	}
+	go deliverWebhooks(ctx, companyService, webhookInterval)
+
+	// Directory partners drop CSV files in, not watched when unset
+	if dir := os.Getenv("WATCH_DIR"); dir != "" {
+		options := watcher.Options{Interval: 30 * time.Second, Profile: os.Getenv("WATCH_PROFILE")}
+		if interval := os.Getenv("WATCH_INTERVAL"); interval != "" {
+			options.Interval, err = time.ParseDuration(interval)
+			if err != nil || options.Interval <= 0 {
+				log.Fatalf("Unable to read watch interval: %v\n", interval)
+			}
+		}
+		go func() {
+			if err := watcher.NewWatcher(companyService, dir, options).Run(ctx); err != nil {
+				log.Printf("Unable to watch %s: %v", dir, err)
+			}
+		}()
+	}
+
 	router := httpConector.NewRouter()
 
 	log.Print("The server has started")
@@ -53,9 +142,53 @@ func main() {
 	<-ctx.Done()
 
 	log.Print("The server has been closed")
-	shutdownCtx := context.Background()
-	err = conn.Close(shutdownCtx)
-	if err != nil {
-		panic(err)
+	conn.Close()
+}
+
+func detectDuplicates(ctx context.Context, service *companyService.CompanyService, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		candidates, err := service.DetectDuplicates(ctx)
+		if err != nil {
+			log.Printf("Duplicate detection failed: %v", err)
+		} else {
+			log.Printf("Duplicate detection found %d candidate pairs", len(candidates))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func deliverWebhooks(ctx context.Context, service *companyService.CompanyService, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		// a full batch leaves more events or deliveries waiting
+		for {
+			dispatched, err := service.DispatchWebhookEvents(ctx)
+			if err != nil {
+				log.Printf("Webhook dispatch failed: %v", err)
+			}
+			delivered, err := service.DeliverWebhooks(ctx)
+			if err != nil {
+				log.Printf("Webhook delivery failed: %v", err)
+			}
+			if err != nil || (dispatched < companyService.WEBHOOK_BATCH_SIZE && delivered < companyService.WEBHOOK_BATCH_SIZE) {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
@@ -7,7 +7,7 @@ CREATE TABLE IF NOT EXISTS companies_catalog_table (
     cc_website TEXT
 );
 
-ALTER TABLE IF EXISTS companies_catalog_table ADD cc_website TEXT;
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_website TEXT;
 -- +goose StatementEnd
 
 -- +goose Down
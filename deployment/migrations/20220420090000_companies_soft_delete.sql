@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_active_idx ON companies_catalog_table (cc_name, cc_zip) WHERE cc_deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_active_idx;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table
+    ADD COLUMN IF NOT EXISTS cc_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS cc_updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS cc_version INTEGER NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table
+    DROP COLUMN IF EXISTS cc_created_at,
+    DROP COLUMN IF EXISTS cc_updated_at,
+    DROP COLUMN IF EXISTS cc_version;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS company_provenance (
+    cp_company_id UUID NOT NULL REFERENCES companies_catalog_table (cc_company_id) ON DELETE CASCADE,
+    cp_field TEXT NOT NULL,
+    cp_source_system TEXT NOT NULL,
+    cp_source_file TEXT NOT NULL DEFAULT '',
+    cp_import_job TEXT NOT NULL DEFAULT '',
+    cp_recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (cp_company_id, cp_field)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS company_provenance;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS company_provenance ADD COLUMN IF NOT EXISTS cp_source_priority INTEGER NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS company_provenance DROP COLUMN IF EXISTS cp_source_priority;
+-- +goose StatementEnd
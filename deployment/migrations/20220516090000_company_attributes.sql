@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE TABLE IF NOT EXISTS company_attribute_schema (
+    as_name TEXT PRIMARY KEY,
+    as_type TEXT NOT NULL DEFAULT 'string',
+    as_pattern TEXT NOT NULL DEFAULT '',
+    as_description TEXT NOT NULL DEFAULT '',
+    as_merge_policy TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO company_attribute_schema(as_name, as_type, as_pattern, as_description) VALUES
+    ('phone', 'string', '^\+?[0-9 ().-]{7,20}$', 'Main phone number'),
+    ('address', 'string', '', 'Street address'),
+    ('city', 'string', '', 'City name'),
+    ('state', 'string', '^[A-Z]{2}$', 'Two letter state code'),
+    ('industry_code', 'string', '^[0-9]{2,6}$', 'NAICS industry code')
+ON CONFLICT (as_name) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS company_attribute_schema;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_attributes;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS parent_companies_table (
+    pc_parent_id UUID PRIMARY KEY,
+    pc_name TEXT NOT NULL UNIQUE,
+    pc_created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO parent_companies_table (pc_parent_id, pc_name)
+SELECT gen_random_uuid(), cc_name FROM companies_catalog_table GROUP BY cc_name
+ON CONFLICT (pc_name) DO NOTHING;
+
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_parent_id UUID REFERENCES parent_companies_table (pc_parent_id);
+
+UPDATE companies_catalog_table SET cc_parent_id = pc_parent_id
+FROM parent_companies_table WHERE pc_name = cc_name AND cc_parent_id IS NULL;
+
+ALTER TABLE IF EXISTS companies_catalog_table ALTER COLUMN cc_parent_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_parent_idx ON companies_catalog_table (cc_parent_id, cc_zip);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_parent_idx;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_parent_id;
+
+DROP TABLE IF EXISTS parent_companies_table;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS duplicate_candidates (
+    dc_candidate_id UUID PRIMARY KEY,
+    dc_company_id UUID NOT NULL REFERENCES companies_catalog_table (cc_company_id) ON DELETE CASCADE,
+    dc_duplicate_id UUID NOT NULL REFERENCES companies_catalog_table (cc_company_id) ON DELETE CASCADE,
+    dc_score DOUBLE PRECISION NOT NULL,
+    dc_status TEXT NOT NULL DEFAULT 'pending',
+    dc_detected_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    dc_resolved_at TIMESTAMPTZ,
+    UNIQUE (dc_company_id, dc_duplicate_id)
+);
+
+CREATE INDEX IF NOT EXISTS duplicate_candidates_status_idx ON duplicate_candidates (dc_status, dc_score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS duplicate_candidates;
+-- +goose StatementEnd
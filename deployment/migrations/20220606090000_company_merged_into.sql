@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_merged_into UUID REFERENCES companies_catalog_table (cc_company_id);
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_merged_into_idx ON companies_catalog_table (cc_merged_into) WHERE cc_merged_into IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_merged_into_idx;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_merged_into;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS company_crosswalk (
+    cx_source_system TEXT NOT NULL,
+    cx_external_key TEXT NOT NULL,
+    cx_company_id UUID NOT NULL REFERENCES companies_catalog_table (cc_company_id) ON DELETE CASCADE,
+    cx_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    cx_updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (cx_source_system, cx_external_key)
+);
+
+CREATE INDEX IF NOT EXISTS company_crosswalk_company_idx ON company_crosswalk (cx_company_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS company_crosswalk;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_domain TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_domain_idx ON companies_catalog_table (cc_domain) WHERE cc_domain <> '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_domain_idx;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_domain;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS zip_reference_table (
+    zr_zip VARCHAR(5) PRIMARY KEY,
+    zr_city TEXT NOT NULL,
+    zr_state VARCHAR(2) NOT NULL,
+    zr_latitude DOUBLE PRECISION NOT NULL,
+    zr_longitude DOUBLE PRECISION NOT NULL
+);
+
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_city TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_state TEXT NOT NULL DEFAULT '';
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_zip_unknown BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_zip_unknown;
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_state;
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_city;
+
+DROP TABLE IF EXISTS zip_reference_table;
+-- +goose StatementEnd
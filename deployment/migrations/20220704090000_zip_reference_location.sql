@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS zip_reference_table_location_idx ON zip_reference_table (zr_latitude, zr_longitude);
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_zip_idx ON companies_catalog_table (cc_zip) WHERE cc_deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_zip_idx;
+
+DROP INDEX IF EXISTS zip_reference_table_location_idx;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE IF EXISTS companies_catalog_table ALTER COLUMN cc_zip TYPE VARCHAR(10);
+
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_country VARCHAR(2) NOT NULL DEFAULT 'US';
+
+-- ZIP+4 codes match on their first five digits, other postal codes as a whole
+ALTER TABLE IF EXISTS companies_catalog_table ADD COLUMN IF NOT EXISTS cc_zip_base VARCHAR(10)
+    GENERATED ALWAYS AS (CASE WHEN cc_country = 'US' THEN left(cc_zip, 5) ELSE cc_zip END) STORED;
+
+CREATE INDEX IF NOT EXISTS companies_catalog_table_zip_base_idx ON companies_catalog_table (cc_zip_base) WHERE cc_deleted_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS companies_catalog_table_zip_base_idx;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_zip_base;
+
+ALTER TABLE IF EXISTS companies_catalog_table DROP COLUMN IF EXISTS cc_country;
+
+ALTER TABLE IF EXISTS companies_catalog_table ALTER COLUMN cc_zip TYPE VARCHAR(5) USING left(cc_zip, 5);
+-- +goose StatementEnd
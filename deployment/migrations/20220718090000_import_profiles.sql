@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS import_profiles (
+    ip_name TEXT PRIMARY KEY,
+    ip_delimiter TEXT NOT NULL DEFAULT ';',
+    ip_header_aliases JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ip_columns JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ip_transforms JSONB NOT NULL DEFAULT '{}'::jsonb,
+    ip_priority INTEGER NOT NULL DEFAULT 0,
+    ip_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ip_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS import_profiles;
+-- +goose StatementEnd
@@ -0,0 +1,52 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every write to the catalog is logged by a trigger. The sequence is handed
+-- out when the change is read rather than when it is written, so changes
+-- committed late still come after the ones a consumer has already seen.
+CREATE SEQUENCE IF NOT EXISTS company_changes_sequence;
+
+CREATE TABLE IF NOT EXISTS company_changes (
+    ch_id BIGSERIAL PRIMARY KEY,
+    ch_sequence BIGINT UNIQUE,
+    ch_company_id UUID NOT NULL,
+    ch_operation TEXT NOT NULL,
+    ch_changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS company_changes_pending_idx ON company_changes (ch_id) WHERE ch_sequence IS NULL;
+
+CREATE OR REPLACE FUNCTION company_changes_record() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO company_changes (ch_company_id, ch_operation) VALUES (NEW.cc_company_id, 'insert');
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.cc_deleted_at IS NULL THEN
+            INSERT INTO company_changes (ch_company_id, ch_operation) VALUES (OLD.cc_company_id, 'delete');
+        END IF;
+    ELSIF NEW.cc_deleted_at IS NOT NULL THEN
+        -- soft deleted or merged into another company
+        IF OLD.cc_deleted_at IS NULL THEN
+            INSERT INTO company_changes (ch_company_id, ch_operation) VALUES (NEW.cc_company_id, 'delete');
+        END IF;
+    ELSIF OLD IS DISTINCT FROM NEW THEN
+        INSERT INTO company_changes (ch_company_id, ch_operation) VALUES (NEW.cc_company_id, 'update');
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS company_changes_trigger ON companies_catalog_table;
+CREATE TRIGGER company_changes_trigger AFTER INSERT OR UPDATE OR DELETE ON companies_catalog_table
+    FOR EACH ROW EXECUTE FUNCTION company_changes_record();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS company_changes_trigger ON companies_catalog_table;
+
+DROP FUNCTION IF EXISTS company_changes_record();
+
+DROP TABLE IF EXISTS company_changes;
+
+DROP SEQUENCE IF EXISTS company_changes_sequence;
+-- +goose StatementEnd
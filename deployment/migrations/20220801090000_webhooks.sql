@@ -0,0 +1,92 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    ws_id UUID PRIMARY KEY,
+    ws_url TEXT NOT NULL,
+    ws_secret TEXT NOT NULL,
+    ws_events TEXT[] NOT NULL,
+    ws_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ws_updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- The outbox is written by a trigger in the transaction of the company write,
+-- so an event exists if and only if the write was committed.
+CREATE TABLE IF NOT EXISTS webhook_outbox (
+    wo_id BIGSERIAL PRIMARY KEY,
+    wo_event TEXT NOT NULL,
+    wo_company_id UUID NOT NULL,
+    wo_merged_into UUID,
+    wo_changed_fields TEXT[] NOT NULL DEFAULT '{}',
+    wo_occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    wo_dispatched_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS webhook_outbox_pending_idx ON webhook_outbox (wo_id) WHERE wo_dispatched_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    wd_id UUID PRIMARY KEY,
+    wd_subscription_id UUID NOT NULL REFERENCES webhook_subscriptions (ws_id) ON DELETE CASCADE,
+    wd_event_id BIGINT NOT NULL REFERENCES webhook_outbox (wo_id),
+    wd_event TEXT NOT NULL,
+    wd_payload JSONB NOT NULL,
+    wd_status TEXT NOT NULL DEFAULT 'pending',
+    wd_attempts INTEGER NOT NULL DEFAULT 0,
+    wd_next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    wd_last_error TEXT NOT NULL DEFAULT '',
+    wd_created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    wd_delivered_at TIMESTAMPTZ,
+    UNIQUE (wd_subscription_id, wd_event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (wd_next_attempt_at) WHERE wd_status = 'pending';
+
+CREATE OR REPLACE FUNCTION webhook_outbox_record() RETURNS TRIGGER AS $$
+DECLARE
+    fields TEXT[] := '{}';
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('created', NEW.cc_company_id);
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.cc_deleted_at IS NULL THEN
+            INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', OLD.cc_company_id);
+        END IF;
+    ELSIF OLD.cc_deleted_at IS NOT NULL AND NEW.cc_deleted_at IS NOT NULL THEN
+        -- a deleted company redirected to another survivor
+        RETURN NULL;
+    ELSIF NEW.cc_merged_into IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_merged_into) VALUES ('merged', NEW.cc_company_id, NEW.cc_merged_into);
+    ELSIF NEW.cc_deleted_at IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', NEW.cc_company_id);
+    ELSIF OLD IS DISTINCT FROM NEW THEN
+        IF NEW.cc_name IS DISTINCT FROM OLD.cc_name THEN fields := fields || 'name'::TEXT; END IF;
+        IF NEW.cc_zip IS DISTINCT FROM OLD.cc_zip THEN fields := fields || 'zipCode'::TEXT; END IF;
+        IF NEW.cc_country IS DISTINCT FROM OLD.cc_country THEN fields := fields || 'country'::TEXT; END IF;
+        IF NEW.cc_city IS DISTINCT FROM OLD.cc_city THEN fields := fields || 'city'::TEXT; END IF;
+        IF NEW.cc_state IS DISTINCT FROM OLD.cc_state THEN fields := fields || 'state'::TEXT; END IF;
+        IF NEW.cc_website IS DISTINCT FROM OLD.cc_website THEN fields := fields || 'website'::TEXT; END IF;
+        fields := fields || ARRAY(
+            SELECT key FROM jsonb_each_text(NEW.cc_attributes) n FULL JOIN jsonb_each_text(OLD.cc_attributes) o USING (key)
+            WHERE n.value IS DISTINCT FROM o.value ORDER BY key);
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_changed_fields) VALUES ('updated', NEW.cc_company_id, fields);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS webhook_outbox_trigger ON companies_catalog_table;
+CREATE TRIGGER webhook_outbox_trigger AFTER INSERT OR UPDATE OR DELETE ON companies_catalog_table
+    FOR EACH ROW EXECUTE FUNCTION webhook_outbox_record();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TRIGGER IF EXISTS webhook_outbox_trigger ON companies_catalog_table;
+
+DROP FUNCTION IF EXISTS webhook_outbox_record();
+
+DROP TABLE IF EXISTS webhook_deliveries;
+
+DROP TABLE IF EXISTS webhook_outbox;
+
+DROP TABLE IF EXISTS webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS ingested_files (
+    if_checksum TEXT PRIMARY KEY,
+    if_file_name TEXT NOT NULL,
+    if_processed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS ingested_files;
+-- +goose StatementEnd
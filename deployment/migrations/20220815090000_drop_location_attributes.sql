@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- city and state are set from the zip code reference, in cc_city and cc_state
+DELETE FROM company_attribute_schema WHERE as_name IN ('city', 'state');
+
+UPDATE companies_catalog_table SET cc_attributes = cc_attributes - 'city' - 'state'
+WHERE cc_attributes - 'city' - 'state' <> cc_attributes;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+INSERT INTO company_attribute_schema(as_name, as_type, as_pattern, as_description) VALUES
+    ('city', 'string', '', 'City name'),
+    ('state', 'string', '^[A-Z]{2}$', 'Two letter state code')
+ON CONFLICT (as_name) DO NOTHING;
+-- +goose StatementEnd
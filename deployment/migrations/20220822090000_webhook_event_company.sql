@@ -0,0 +1,87 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the state of the company when a created or updated event occurred, sent
+-- with the event however late it is dispatched
+ALTER TABLE IF EXISTS webhook_outbox ADD COLUMN IF NOT EXISTS wo_company JSONB;
+
+CREATE OR REPLACE FUNCTION webhook_outbox_record() RETURNS TRIGGER AS $$
+DECLARE
+    fields TEXT[] := '{}';
+    company JSONB;
+BEGIN
+    IF TG_OP <> 'DELETE' THEN
+        -- the company as the API returns it, at the time of the event
+        company := jsonb_build_object(
+            '_id', NEW.cc_company_id, 'parentId', NEW.cc_parent_id, 'name', NEW.cc_name, 'zipCode', NEW.cc_zip,
+            'country', NEW.cc_country, 'city', NEW.cc_city, 'state', NEW.cc_state, 'zipUnknown', NEW.cc_zip_unknown,
+            'website', NEW.cc_website, 'domain', NEW.cc_domain, 'attributes', NEW.cc_attributes,
+            'createdAt', NEW.cc_created_at, 'updatedAt', NEW.cc_updated_at, 'version', NEW.cc_version);
+    END IF;
+
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_company) VALUES ('created', NEW.cc_company_id, company);
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.cc_deleted_at IS NULL THEN
+            INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', OLD.cc_company_id);
+        END IF;
+    ELSIF OLD.cc_deleted_at IS NOT NULL AND NEW.cc_deleted_at IS NOT NULL THEN
+        -- a deleted company redirected to another survivor
+        RETURN NULL;
+    ELSIF NEW.cc_merged_into IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_merged_into) VALUES ('merged', NEW.cc_company_id, NEW.cc_merged_into);
+    ELSIF NEW.cc_deleted_at IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', NEW.cc_company_id);
+    ELSIF OLD IS DISTINCT FROM NEW THEN
+        IF NEW.cc_name IS DISTINCT FROM OLD.cc_name THEN fields := fields || 'name'::TEXT; END IF;
+        IF NEW.cc_zip IS DISTINCT FROM OLD.cc_zip THEN fields := fields || 'zipCode'::TEXT; END IF;
+        IF NEW.cc_country IS DISTINCT FROM OLD.cc_country THEN fields := fields || 'country'::TEXT; END IF;
+        IF NEW.cc_city IS DISTINCT FROM OLD.cc_city THEN fields := fields || 'city'::TEXT; END IF;
+        IF NEW.cc_state IS DISTINCT FROM OLD.cc_state THEN fields := fields || 'state'::TEXT; END IF;
+        IF NEW.cc_website IS DISTINCT FROM OLD.cc_website THEN fields := fields || 'website'::TEXT; END IF;
+        fields := fields || ARRAY(
+            SELECT key FROM jsonb_each_text(NEW.cc_attributes) n FULL JOIN jsonb_each_text(OLD.cc_attributes) o USING (key)
+            WHERE n.value IS DISTINCT FROM o.value ORDER BY key);
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_changed_fields, wo_company) VALUES ('updated', NEW.cc_company_id, fields, company);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION webhook_outbox_record() RETURNS TRIGGER AS $$
+DECLARE
+    fields TEXT[] := '{}';
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('created', NEW.cc_company_id);
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.cc_deleted_at IS NULL THEN
+            INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', OLD.cc_company_id);
+        END IF;
+    ELSIF OLD.cc_deleted_at IS NOT NULL AND NEW.cc_deleted_at IS NOT NULL THEN
+        -- a deleted company redirected to another survivor
+        RETURN NULL;
+    ELSIF NEW.cc_merged_into IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_merged_into) VALUES ('merged', NEW.cc_company_id, NEW.cc_merged_into);
+    ELSIF NEW.cc_deleted_at IS NOT NULL THEN
+        INSERT INTO webhook_outbox (wo_event, wo_company_id) VALUES ('deleted', NEW.cc_company_id);
+    ELSIF OLD IS DISTINCT FROM NEW THEN
+        IF NEW.cc_name IS DISTINCT FROM OLD.cc_name THEN fields := fields || 'name'::TEXT; END IF;
+        IF NEW.cc_zip IS DISTINCT FROM OLD.cc_zip THEN fields := fields || 'zipCode'::TEXT; END IF;
+        IF NEW.cc_country IS DISTINCT FROM OLD.cc_country THEN fields := fields || 'country'::TEXT; END IF;
+        IF NEW.cc_city IS DISTINCT FROM OLD.cc_city THEN fields := fields || 'city'::TEXT; END IF;
+        IF NEW.cc_state IS DISTINCT FROM OLD.cc_state THEN fields := fields || 'state'::TEXT; END IF;
+        IF NEW.cc_website IS DISTINCT FROM OLD.cc_website THEN fields := fields || 'website'::TEXT; END IF;
+        fields := fields || ARRAY(
+            SELECT key FROM jsonb_each_text(NEW.cc_attributes) n FULL JOIN jsonb_each_text(OLD.cc_attributes) o USING (key)
+            WHERE n.value IS DISTINCT FROM o.value ORDER BY key);
+        INSERT INTO webhook_outbox (wo_event, wo_company_id, wo_changed_fields) VALUES ('updated', NEW.cc_company_id, fields);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE IF EXISTS webhook_outbox DROP COLUMN IF EXISTS wo_company;
+-- +goose StatementEnd
@@ -0,0 +1,8 @@
+// Package migrations holds the schema migrations, in the goose format, and
+// embeds them so the binaries apply them without the goose CLI
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
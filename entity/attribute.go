@@ -0,0 +1,19 @@
+package entity
+
+// Types an attribute value can be validated against
+const (
+	ATTRIBUTE_TYPE_STRING  = "string"
+	ATTRIBUTE_TYPE_INTEGER = "integer"
+	ATTRIBUTE_TYPE_NUMBER  = "number"
+	ATTRIBUTE_TYPE_BOOLEAN = "boolean"
+)
+
+// AttributeDefinition registers an additional company attribute. Values are
+// checked against its type and, when set, its pattern.
+type AttributeDefinition struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Pattern     string `json:"pattern,omitempty"`
+	Description string `json:"description,omitempty"`
+	MergePolicy string `json:"mergePolicy,omitempty"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operations of the change feed. A restored company comes back as an update,
+// so consumers upsert inserts and updates and remove deletes.
+const (
+	CHANGE_INSERT = "insert"
+	CHANGE_UPDATE = "update"
+	CHANGE_DELETE = "delete"
+)
+
+// CompanyChange is a write to the catalog. Company holds the current state of
+// the company, and is left out for deletes.
+type CompanyChange struct {
+	Sequence  int64      `json:"sequence"`
+	Operation string     `json:"operation"`
+	CompanyID uuid.UUID  `json:"companyId"`
+	ChangedAt time.Time  `json:"changedAt"`
+	Company   *Companies `json:"company,omitempty"`
+}
+
+// ChangePage is a page of the change feed. NextCursor is the since value of
+// the next page, to be stored by the consumer once the page is applied.
+type ChangePage struct {
+	Changes    []CompanyChange `json:"changes"`
+	NextCursor string          `json:"nextCursor"`
+	HasMore    bool            `json:"hasMore"`
+}
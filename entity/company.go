@@ -1,10 +1,54 @@
 package entity
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Companies struct {
-	ID      uuid.UUID `json:"_id"`
-	Name    string    `json:"name"`
-	Zip     string    `json:"zipCode"`
-	Website string    `json:"website"`
+	ID         uuid.UUID         `json:"_id"`
+	ParentID   uuid.UUID         `json:"parentId"`
+	Name       string            `json:"name"`
+	Zip        string            `json:"zipCode"`
+	Country    string            `json:"country"`
+	City       string            `json:"city,omitempty"`
+	State      string            `json:"state,omitempty"`
+	ZipUnknown bool              `json:"zipUnknown,omitempty"`
+	Website    string            `json:"website"`
+	Domain     string            `json:"domain,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	DeletedAt  *time.Time        `json:"deletedAt,omitempty"`
+	MergedInto *uuid.UUID        `json:"mergedInto,omitempty"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	UpdatedAt  time.Time         `json:"updatedAt"`
+	Version    int               `json:"version"`
+	// ExternalID is the ID a source system gives to the company, only set on
+	// incoming records
+	ExternalID string `json:"externalId,omitempty"`
+}
+
+// Countries whose postal codes are supported
+const (
+	COUNTRY_US = "US"
+	COUNTRY_CA = "CA"
+	COUNTRY_GB = "GB"
+)
+
+// CompanyFilter holds the optional criteria used when listing companies.
+// Deleted companies are left out unless IncludeDeleted is set.
+type CompanyFilter struct {
+	Name           string
+	Zip            string
+	Country        string
+	ParentID       uuid.UUID
+	ZipUnknown     bool
+	IncludeDeleted bool
+}
+
+// ParentCompany groups the locations of a company, one per address
+type ParentCompany struct {
+	ID        uuid.UUID   `json:"_id"`
+	Name      string      `json:"name"`
+	Locations []Companies `json:"locations"`
 }
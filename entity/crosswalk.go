@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Crosswalk maps the key a source system uses for a company to the company
+type Crosswalk struct {
+	SourceSystem string    `json:"sourceSystem"`
+	ExternalKey  string    `json:"externalKey"`
+	CompanyID    uuid.UUID `json:"companyId"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status of a duplicate candidate pair
+const (
+	DUPLICATE_STATUS_PENDING   = "pending"
+	DUPLICATE_STATUS_CONFIRMED = "confirmed"
+	DUPLICATE_STATUS_REJECTED  = "rejected"
+)
+
+// DuplicateCandidate is a pair of companies that likely are the same one,
+// waiting for an operator to confirm or reject it
+type DuplicateCandidate struct {
+	ID          uuid.UUID  `json:"_id"`
+	CompanyID   uuid.UUID  `json:"companyId"`
+	DuplicateID uuid.UUID  `json:"duplicateId"`
+	Score       float64    `json:"score"`
+	Status      string     `json:"status"`
+	DetectedAt  time.Time  `json:"detectedAt"`
+	ResolvedAt  *time.Time `json:"resolvedAt,omitempty"`
+}
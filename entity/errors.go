@@ -0,0 +1,9 @@
+package entity
+
+import "errors"
+
+// Errors shared between the repositories and the services
+var (
+	ERR_VERSION_CONFLICT   = errors.New("Error: company was modified by another request")
+	ERR_COMPANY_NOT_EXISTS = errors.New("Erro: there is no company with this name")
+)
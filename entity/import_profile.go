@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+// Fields an import profile can map a column to, besides the registered
+// attributes
+const (
+	IMPORT_FIELD_NAME        = FIELD_NAME
+	IMPORT_FIELD_ZIP         = FIELD_ZIP
+	IMPORT_FIELD_WEBSITE     = FIELD_WEBSITE
+	IMPORT_FIELD_COUNTRY     = "country"
+	IMPORT_FIELD_EXTERNAL_ID = "externalId"
+)
+
+// ImportProfile describes the file layout of a source. Columns maps a header
+// to the field it fills, HeaderAliases lists other spellings of a header and
+// Transforms the transforms applied in order to the value of a field.
+// Priority, when set, is the default priority of the source.
+type ImportProfile struct {
+	Name          string              `json:"name"`
+	Delimiter     string              `json:"delimiter"`
+	HeaderAliases map[string][]string `json:"headerAliases,omitempty"`
+	Columns       map[string]string   `json:"columns"`
+	Transforms    map[string][]string `json:"transforms,omitempty"`
+	Priority      int                 `json:"priority,omitempty"`
+	CreatedAt     time.Time           `json:"createdAt"`
+	UpdatedAt     time.Time           `json:"updatedAt"`
+}
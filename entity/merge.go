@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Status of a single record after a merge
+const (
+	MERGE_STATUS_MERGED    = "merged"
+	MERGE_STATUS_UNCHANGED = "unchanged"
+	MERGE_STATUS_NOT_FOUND = "not_found"
+	MERGE_STATUS_REJECTED  = "rejected"
+	MERGE_STATUS_FAILED    = "failed"
+)
+
+// How an incoming record was matched to a stored company
+const (
+	MATCH_CROSSWALK = "crosswalk"
+	MATCH_NAME      = "name"
+	MATCH_WEBSITE   = "website"
+)
+
+// FieldDecision tells how the merge policy of a field handled the incoming value
+type FieldDecision struct {
+	Field   string `json:"field"`
+	Policy  string `json:"policy"`
+	Applied bool   `json:"applied"`
+	Reason  string `json:"reason"`
+}
+
+// MergeResult is the outcome of merging one incoming record
+type MergeResult struct {
+	Line      int             `json:"line,omitempty"`
+	CompanyID uuid.UUID       `json:"companyId,omitempty"`
+	Name      string          `json:"name"`
+	Zip       string          `json:"zipCode"`
+	Status    string          `json:"status"`
+	MatchedBy string          `json:"matchedBy,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	Fields    []FieldDecision `json:"fields,omitempty"`
+}
+
+// MergeReport summarizes an import job
+type MergeReport struct {
+	ImportJob    string        `json:"importJob"`
+	SourceSystem string        `json:"sourceSystem"`
+	SourceFile   string        `json:"sourceFile,omitempty"`
+	Total        int           `json:"total"`
+	Merged       int           `json:"merged"`
+	Unchanged    int           `json:"unchanged"`
+	NotFound     int           `json:"notFound"`
+	Rejected     int           `json:"rejected"`
+	Failed       int           `json:"failed"`
+	Results      []MergeResult `json:"results"`
+}
+
+// CompanyMerge tells how two records were combined into the surviving one
+type CompanyMerge struct {
+	Survivor Companies       `json:"survivor"`
+	MergedID uuid.UUID       `json:"mergedId"`
+	Fields   []FieldDecision `json:"fields"`
+}
+
+// ArchiveReport gathers the reports of the files of an archive, each one
+// merged as an import job of its own
+type ArchiveReport struct {
+	SourceFile string              `json:"sourceFile"`
+	Files      []ArchiveFileReport `json:"files"`
+}
+
+// ArchiveFileReport is the report of a file of an archive, or the error that
+// kept it from being merged
+type ArchiveFileReport struct {
+	File   string       `json:"file"`
+	Error  string       `json:"error,omitempty"`
+	Report *MergeReport `json:"report,omitempty"`
+}
+
+// Status of a file picked up from a watched folder
+const (
+	WATCH_STATUS_PROCESSED = "processed"
+	WATCH_STATUS_FAILED    = "failed"
+	WATCH_STATUS_DUPLICATE = "duplicate"
+)
+
+// IngestedFile is a file merged from a watched folder, known by the SHA-256
+// checksum of its content
+type IngestedFile struct {
+	Checksum    string    `json:"checksum"`
+	FileName    string    `json:"fileName"`
+	ProcessedAt time.Time `json:"processedAt"`
+}
+
+// WatchReport is written next to a file of a watched folder once it is
+// handled. DuplicateOf is the earlier file with the same content.
+type WatchReport struct {
+	File        string        `json:"file"`
+	Checksum    string        `json:"checksum,omitempty"`
+	Status      string        `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	DuplicateOf *IngestedFile `json:"duplicateOf,omitempty"`
+	ProcessedAt time.Time     `json:"processedAt"`
+	Report      *MergeReport  `json:"report,omitempty"`
+}
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// Migration is a schema migration of deployment/migrations, named
+// <version>_<name>.sql
+type Migration struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Names used to track the provenance of each company field
+const (
+	FIELD_NAME    = "name"
+	FIELD_ZIP     = "zipCode"
+	FIELD_WEBSITE = "website"
+)
+
+// Source describes where a write to the catalog comes from
+type Source struct {
+	System    string
+	File      string
+	ImportJob string
+	Priority  int
+	Timestamp time.Time
+}
+
+// Provenance records which source last set a company field
+type Provenance struct {
+	CompanyID    uuid.UUID `json:"-"`
+	Field        string    `json:"field"`
+	SourceSystem string    `json:"sourceSystem"`
+	SourceFile   string    `json:"sourceFile,omitempty"`
+	ImportJob    string    `json:"importJob,omitempty"`
+	Priority     int       `json:"priority"`
+	RecordedAt   time.Time `json:"recordedAt"`
+}
+
+// CompanyDetails is a company along with the provenance of its fields
+type CompanyDetails struct {
+	Companies
+	Provenance map[string]Provenance `json:"provenance"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "github.com/google/uuid"
+
+// ValidationResult tells whether one record of a file would be accepted,
+// with the problems that would reject it and the ones that would not. When
+// the records are matched, CompanyID is the stored company the record would
+// be merged into.
+type ValidationResult struct {
+	Line      int        `json:"line"`
+	Name      string     `json:"name"`
+	Zip       string     `json:"zipCode"`
+	Valid     bool       `json:"valid"`
+	Errors    []string   `json:"errors,omitempty"`
+	Warnings  []string   `json:"warnings,omitempty"`
+	CompanyID *uuid.UUID `json:"companyId,omitempty"`
+	MatchedBy string     `json:"matchedBy,omitempty"`
+}
+
+// ValidationReport summarizes the validation of a file, nothing of which is
+// stored. Matched and NotFound are only counted when the records are matched.
+type ValidationReport struct {
+	SourceFile string             `json:"sourceFile,omitempty"`
+	Total      int                `json:"total"`
+	Valid      int                `json:"valid"`
+	Invalid    int                `json:"invalid"`
+	Matched    int                `json:"matched,omitempty"`
+	NotFound   int                `json:"notFound,omitempty"`
+	Results    []ValidationResult `json:"results"`
+}
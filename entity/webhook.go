@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events a webhook subscription can ask for. merged is sent for a company
+// merged into another one, the survivor getting an updated event.
+const (
+	EVENT_CREATED = "created"
+	EVENT_UPDATED = "updated"
+	EVENT_DELETED = "deleted"
+	EVENT_MERGED  = "merged"
+)
+
+// Statuses of a webhook delivery. A delivery that keeps failing ends dead
+// until it is retried by hand.
+const (
+	DELIVERY_PENDING   = "pending"
+	DELIVERY_DELIVERED = "delivered"
+	DELIVERY_DEAD      = "dead"
+)
+
+// WebhookSubscription sends the events it lists to URL. Secret signs the
+// payloads and is only returned when the subscription is created.
+type WebhookSubscription struct {
+	ID        uuid.UUID `json:"_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CompanyEvent is an event of the outbox, recorded along with the write to
+// the catalog it comes from
+type CompanyEvent struct {
+	ID            int64
+	Event         string
+	CompanyID     uuid.UUID
+	MergedInto    *uuid.UUID
+	ChangedFields []string
+	OccurredAt    time.Time
+	// Company is the company as it was when a created or updated event
+	// occurred
+	Company *Companies
+}
+
+// WebhookPayload is the body sent to the subscriptions. Company holds the
+// company as it was when the event occurred, and is left out for deleted
+// and merged companies.
+type WebhookPayload struct {
+	EventID       int64      `json:"eventId"`
+	Event         string     `json:"event"`
+	OccurredAt    time.Time  `json:"occurredAt"`
+	CompanyID     uuid.UUID  `json:"companyId"`
+	MergedInto    *uuid.UUID `json:"mergedInto,omitempty"`
+	ChangedFields []string   `json:"changedFields,omitempty"`
+	Company       *Companies `json:"company,omitempty"`
+}
+
+// WebhookDelivery is the sending of an event to a subscription. URL and
+// Secret are the ones of the subscription, set on the deliveries due.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"_id"`
+	SubscriptionID uuid.UUID       `json:"subscriptionId"`
+	EventID        int64           `json:"eventId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	URL            string          `json:"-"`
+	Secret         string          `json:"-"`
+}
@@ -0,0 +1,42 @@
+package entity
+
+// ZipReference locates a US zip code
+type ZipReference struct {
+	Zip       string  `json:"zipCode"`
+	City      string  `json:"city"`
+	State     string  `json:"state"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// NearbyQuery selects the page of companies within RadiusMiles of a point.
+// The bounding box encloses the circle and narrows the zip codes whose
+// distance is computed. Its longitudes lie between -180 and 180, MinLongitude
+// being greater than MaxLongitude when the box crosses the antimeridian.
+type NearbyQuery struct {
+	Latitude     float64
+	Longitude    float64
+	RadiusMiles  float64
+	MinLatitude  float64
+	MaxLatitude  float64
+	MinLongitude float64
+	MaxLongitude float64
+	Limit        int
+	Offset       int
+}
+
+// NearbyCompany is a company with its distance to the searched zip code
+type NearbyCompany struct {
+	Companies
+	DistanceMiles float64 `json:"distanceMiles"`
+}
+
+// NearbyPage is one page of the companies around a zip code, closest first
+type NearbyPage struct {
+	Zip         string          `json:"zipCode"`
+	RadiusMiles float64         `json:"radiusMiles"`
+	Total       int             `json:"total"`
+	Limit       int             `json:"limit"`
+	Offset      int             `json:"offset"`
+	Companies   []NearbyCompany `json:"companies"`
+}
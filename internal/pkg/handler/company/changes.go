@@ -0,0 +1,28 @@
+package company
+
+import (
+	"errors"
+	"net/http"
+
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+//GetCompanyChanges GET /v1/companies/changes?since={value}&limit={value}
+func (c *CompanyHandler) GetCompanyChanges(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "limit must be an integer")
+		return
+	}
+
+	page, err := c.service.ListChanges(r.Context(), r.URL.Query().Get("since"), limit)
+	switch {
+	case errors.Is(err, companyService.ERR_NOT_VALID_CURSOR), errors.Is(err, companyService.ERR_NOT_VALID_PAGE):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, page)
+}
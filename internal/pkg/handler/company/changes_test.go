@@ -0,0 +1,89 @@
+package company
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+)
+
+func TestGetCompanyChanges(t *testing.T) {
+	t.Run("page of changes", func(t *testing.T) {
+		change := entity.CompanyChange{Sequence: 43, Operation: entity.CHANGE_DELETE, CompanyID: uuid.New()}
+		mockService := &MockCompanyService{
+			ListChangesMock: func(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error) {
+				if cursor != "42" || limit != 10 {
+					t.Errorf("got %v, %v want 42, 10", cursor, limit)
+				}
+				return &entity.ChangePage{Changes: []entity.CompanyChange{change}, NextCursor: "43", HasMore: true}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/changes?since=42&limit=10", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetCompanyChanges(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		var got entity.ChangePage
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.NextCursor != "43" || !got.HasMore || len(got.Changes) != 1 || got.Changes[0].CompanyID != change.CompanyID {
+			t.Errorf("got %+v", got)
+		}
+	})
+
+	t.Run("not valid limit", func(t *testing.T) {
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/changes?limit=all", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler.GetCompanyChanges(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("service errors", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			want int
+		}{
+			{companyService.ERR_NOT_VALID_CURSOR, http.StatusBadRequest},
+			{companyService.ERR_NOT_VALID_PAGE, http.StatusBadRequest},
+			{companyService.ERR_WHILE_GETTING_COMPANIES, http.StatusInternalServerError},
+		}
+
+		for _, test := range tests {
+			mockService := &MockCompanyService{
+				ListChangesMock: func(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error) {
+					return nil, test.err
+				},
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/companies/changes?since=abc", nil)
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+
+			companyHandler.GetCompanyChanges(response, request)
+
+			if response.Code != test.want {
+				t.Errorf("%v: got: %d, want: %d", test.err, response.Code, test.want)
+			}
+		}
+	})
+}
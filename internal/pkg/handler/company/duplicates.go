@@ -0,0 +1,98 @@
+package company
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+)
+
+type confirmDuplicateRequest struct {
+	SurvivorID uuid.UUID `json:"survivorId"`
+}
+
+//GetDuplicates GET /v1/companies/duplicates?status={value}
+func (c *CompanyHandler) GetDuplicates(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entity.DUPLICATE_STATUS_PENDING, entity.DUPLICATE_STATUS_CONFIRMED, entity.DUPLICATE_STATUS_REJECTED:
+	default:
+		RespondError(w, http.StatusBadRequest, "invalid status")
+		return
+	}
+
+	candidates, err := c.service.ListDuplicateCandidates(r.Context(), status)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, candidates)
+}
+
+//DetectDuplicates POST /v1/companies/duplicates/detect
+func (c *CompanyHandler) DetectDuplicates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := c.service.DetectDuplicates(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, candidates)
+}
+
+//ConfirmDuplicate POST /v1/companies/duplicates/{id}/confirm
+func (c *CompanyHandler) ConfirmDuplicate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid candidate ID")
+		return
+	}
+
+	var request confirmDuplicateRequest
+	if err := json.NewDecoder(io.LimitReader(r.Body, 128*1024*8)).Decode(&request); err != nil && err != io.EOF {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	survivor, err := c.service.ConfirmDuplicate(r.Context(), id, request.SurvivorID)
+	if errors.Is(err, companyService.ERR_DUPLICATE_NOT_FOUND) || errors.Is(err, companyService.ERR_COMPANY_NOT_EXISTS) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if errors.Is(err, companyService.ERR_NOT_VALID_SURVIVOR) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if errors.Is(err, companyService.ERR_VERSION_CONFLICT) {
+		RespondError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, survivor)
+}
+
+//RejectDuplicate POST /v1/companies/duplicates/{id}/reject
+func (c *CompanyHandler) RejectDuplicate(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid candidate ID")
+		return
+	}
+
+	err = c.service.RejectDuplicate(r.Context(), id)
+	if errors.Is(err, companyService.ERR_DUPLICATE_NOT_FOUND) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
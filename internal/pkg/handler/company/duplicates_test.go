@@ -0,0 +1,122 @@
+package company
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestGetDuplicates(t *testing.T) {
+	t.Run("pending candidates", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			ListDuplicatesMock: func(ctx context.Context, status string) ([]entity.DuplicateCandidate, error) {
+				if status != entity.DUPLICATE_STATUS_PENDING {
+					t.Errorf("got %v want %v", status, entity.DUPLICATE_STATUS_PENDING)
+				}
+				return []entity.DuplicateCandidate{}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/duplicates?status=pending", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetDuplicates(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/duplicates?status=maybe", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		companyHandler.GetDuplicates(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestConfirmDuplicate(t *testing.T) {
+	candidateID := uuid.New()
+	survivorID := uuid.New()
+
+	tests := []struct {
+		name string
+		body string
+		err  error
+		want int
+	}{
+		{"with survivor", `{"survivorId": "` + survivorID.String() + `"}`, nil, http.StatusOK},
+		{"without body", ``, nil, http.StatusOK},
+		{"already resolved", ``, companyService.ERR_DUPLICATE_NOT_FOUND, http.StatusNotFound},
+		{"survivor outside the pair", ``, companyService.ERR_NOT_VALID_SURVIVOR, http.StatusBadRequest},
+		{"invalid body", `{`, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockCompanyService{
+				ConfirmDuplicateMock: func(ctx context.Context, id uuid.UUID, survivor uuid.UUID) (*entity.Companies, error) {
+					if test.body != "" && survivor != survivorID {
+						t.Errorf("got %v want %v", survivor, survivorID)
+					}
+					if test.err != nil {
+						return nil, test.err
+					}
+					return &entity.Companies{ID: survivor}, nil
+				},
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/companies/duplicates/"+candidateID.String()+"/confirm", strings.NewReader(test.body))
+			request = mux.SetURLVars(request, map[string]string{"id": candidateID.String()})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+
+			companyHandler.ConfirmDuplicate(response, request)
+
+			if response.Code != test.want {
+				t.Errorf("got: %d, want: %d", response.Code, test.want)
+			}
+		})
+	}
+}
+
+func TestRejectDuplicate(t *testing.T) {
+	id := uuid.New()
+	mockService := &MockCompanyService{
+		RejectDuplicateMock: func(ctx context.Context, candidateID uuid.UUID) error {
+			return nil
+		},
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/companies/duplicates/"+id.String()+"/reject", nil)
+	request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+	response := httptest.NewRecorder()
+
+	companyHandler := NewCompanyHandler()
+	companyHandler.Register(mockService)
+
+	companyHandler.RejectDuplicate(response, request)
+
+	if response.Code != http.StatusNoContent {
+		t.Errorf("got: %d, want: %d", response.Code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,94 @@
+package company
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+// exportContentTypes are the content types of the formats the export writes
+var exportContentTypes = map[string]string{
+	recordsRepository.FORMAT_CSV:     "text/csv; charset=utf-8",
+	recordsRepository.FORMAT_NDJSON:  "application/x-ndjson",
+	recordsRepository.FORMAT_PARQUET: "application/vnd.apache.parquet",
+}
+
+// startedWriter tells whether anything was sent, after which the status of
+// the response can no longer change
+type startedWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (w *startedWriter) Write(p []byte) (int, error) {
+	w.started = true
+	return w.ResponseWriter.Write(p)
+}
+
+//ExportCompanies GET /v1/companies/export?format={value}&name={value}&zip={value}&country={value}&zipUnknown={value}&includeDeleted={value}
+func (c *CompanyHandler) ExportCompanies(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = recordsRepository.FORMAT_CSV
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		RespondError(w, http.StatusBadRequest, "format must be csv, ndjson or parquet")
+		return
+	}
+
+	filter, err := parseCompanyFilter(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	attributes := []string{}
+	for _, definition := range c.service.AttributeSchema() {
+		attributes = append(attributes, definition.Name)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="companies.`+format+`"`)
+	response := &startedWriter{ResponseWriter: w}
+
+	var recordWriter recordsRepository.RecordWriter
+	err = c.service.ExportCompanies(r.Context(), filter, func(company *entity.Companies) error {
+		if recordWriter == nil {
+			recordWriter, err = recordsRepository.NewRecordWriter(format, response, attributes)
+			if err != nil {
+				return err
+			}
+		}
+		return recordWriter.Write(company)
+	})
+	if err == nil {
+		if recordWriter == nil {
+			recordWriter, err = recordsRepository.NewRecordWriter(format, response, attributes)
+		}
+		if err == nil {
+			err = recordWriter.Close()
+		}
+	}
+
+	switch {
+	case err == nil:
+		return
+	case errors.Is(err, companyService.ERR_UNKNOWN_COUNTRY) && !response.started:
+		w.Header().Del("Content-Disposition")
+		RespondError(w, http.StatusBadRequest, err.Error())
+	case !response.started:
+		w.Header().Del("Content-Disposition")
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusInternalServerError)
+	default:
+		// the client would take a truncated file for a whole one, so the
+		// connection is cut instead
+		log.Printf("Export interrupted: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,140 @@
+package company
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+func TestExportCompanies(t *testing.T) {
+	companies := []*entity.Companies{
+		{Name: "TOLA SALES GROUP", Zip: "78229", Country: entity.COUNTRY_US, Website: "http://repsources.com", Attributes: map[string]string{"phone": "210-555-0100"}},
+		{Name: "MAPLE SUPPLY", Zip: "K1A 0B1", Country: entity.COUNTRY_CA},
+	}
+	streaming := func(gotFilter *entity.CompanyFilter) *MockCompanyService {
+		return &MockCompanyService{
+			AttributeSchemaMock: func() []entity.AttributeDefinition {
+				return []entity.AttributeDefinition{{Name: "phone"}}
+			},
+			ExportCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+				*gotFilter = filter
+				for _, company := range companies {
+					if err := each(company); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		var gotFilter entity.CompanyFilter
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/export?country=US&zipUnknown=false", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(streaming(&gotFilter))
+		companyHandler.ExportCompanies(response, request)
+
+		want := "name;addresszip;website;country;phone\nTOLA SALES GROUP;78229;http://repsources.com;US;210-555-0100\nMAPLE SUPPLY;K1A 0B1;;CA;\n"
+		if response.Code != http.StatusOK || response.Body.String() != want {
+			t.Errorf("got: %d, %q want %q", response.Code, response.Body.String(), want)
+		}
+		if response.Header().Get("Content-Type") != "text/csv; charset=utf-8" || gotFilter.Country != "US" {
+			t.Errorf("got %v and filter %+v", response.Header(), gotFilter)
+		}
+	})
+
+	t.Run("parquet", func(t *testing.T) {
+		var gotFilter entity.CompanyFilter
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/export?format=parquet", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(streaming(&gotFilter))
+		companyHandler.ExportCompanies(response, request)
+
+		body := response.Body.Bytes()
+		if response.Code != http.StatusOK || !bytes.HasPrefix(body, []byte("PAR1")) || !bytes.HasSuffix(body, []byte("PAR1")) {
+			t.Errorf("got: %d, %v bytes", response.Code, len(body))
+		}
+	})
+
+	t.Run("empty ndjson", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			AttributeSchemaMock: func() []entity.AttributeDefinition { return nil },
+			ExportCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+				return nil
+			},
+		}
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/export?format=ndjson", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.ExportCompanies(response, request)
+
+		if response.Code != http.StatusOK || response.Body.Len() != 0 {
+			t.Errorf("got: %d, %q", response.Code, response.Body.String())
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		tests := []struct {
+			url        string
+			err        error
+			wantStatus int
+		}{
+			{"/v1/companies/export?format=xlsx", nil, http.StatusBadRequest},
+			{"/v1/companies/export?includeDeleted=maybe", nil, http.StatusBadRequest},
+			{"/v1/companies/export?country=Atlantis", companyService.ERR_UNKNOWN_COUNTRY, http.StatusBadRequest},
+			{"/v1/companies/export", errors.New("connection refused"), http.StatusInternalServerError},
+		}
+		for _, test := range tests {
+			mockService := &MockCompanyService{
+				AttributeSchemaMock: func() []entity.AttributeDefinition { return nil },
+				ExportCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+					return test.err
+				},
+			}
+			request := httptest.NewRequest(http.MethodGet, test.url, nil)
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.ExportCompanies(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d for %v", response.Code, test.wantStatus, test.url)
+			}
+		}
+	})
+
+	t.Run("interrupted once started", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			AttributeSchemaMock: func() []entity.AttributeDefinition { return nil },
+			ExportCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+				each(companies[0])
+				return errors.New("connection reset")
+			},
+		}
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/export?format=parquet", nil)
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		defer func() {
+			if recovered := recover(); recovered != http.ErrAbortHandler {
+				t.Errorf("got %v want the handler aborted", recovered)
+			}
+		}()
+		companyHandler.ExportCompanies(httptest.NewRecorder(), request)
+	})
+}
@@ -1,19 +1,23 @@
 package company
 
 import (
-	"bufio"
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
-	csvRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/csv"
+	ingest "github.com/eduardojabes/data-integration-challenge/internal/pkg/ingest/company"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 type CompanyService interface {
@@ -23,6 +27,36 @@ type CompanyService interface {
 	FindByName(name string) (*entity.Companies, error)
 	UpdateCompany(ctx context.Context, company *entity.Companies) error
 	DeleteCompany(ctx context.Context, entity entity.Companies) error
+	ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error)
+	GetCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error)
+	RestoreCompany(ctx context.Context, id uuid.UUID) error
+	ReplaceCompany(ctx context.Context, company *entity.Companies) error
+	MergeCompanies(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport
+	ValidateCompanies(ctx context.Context, companies []*entity.Companies, source *entity.Source) (*entity.ValidationReport, error)
+	GetProvenance(ctx context.Context, companyIDs []uuid.UUID) (map[uuid.UUID]map[string]entity.Provenance, error)
+	AttributeSchema() []entity.AttributeDefinition
+	RegisterAttribute(ctx context.Context, definition entity.AttributeDefinition) error
+	GetLocations(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error)
+	DetectDuplicates(ctx context.Context) ([]entity.DuplicateCandidate, error)
+	ListDuplicateCandidates(ctx context.Context, status string) ([]entity.DuplicateCandidate, error)
+	ConfirmDuplicate(ctx context.Context, candidateID uuid.UUID, survivorID uuid.UUID) (*entity.Companies, error)
+	RejectDuplicate(ctx context.Context, candidateID uuid.UUID) error
+	MergeInto(ctx context.Context, id uuid.UUID, targetID uuid.UUID) (*entity.CompanyMerge, error)
+	FindBySource(ctx context.Context, sourceSystem string, externalID string) (*entity.Companies, error)
+	FindNearby(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error)
+	ListImportProfiles(ctx context.Context) ([]entity.ImportProfile, error)
+	GetImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error)
+	SaveImportProfile(ctx context.Context, profile *entity.ImportProfile) error
+	DeleteImportProfile(ctx context.Context, name string) error
+	ExportCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error
+	ListChanges(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error)
+	ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	CreateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error
+	UpdateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error
+	DeleteWebhook(ctx context.Context, id uuid.UUID) error
+	ListWebhookDeliveries(ctx context.Context, id uuid.UUID, status string) ([]entity.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, id uuid.UUID) error
 }
 
 type CompanyHandler struct {
@@ -58,16 +92,438 @@ func RespondError(w http.ResponseWriter, code int, message string) {
 	RespondJSON(w, code, map[string]string{"error": message})
 }
 
+// parseIncludeDeleted reads the optional includeDeleted query parameter
+func parseIncludeDeleted(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("includeDeleted")
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseCompanyFilter reads the name, zip, country, zipUnknown and
+// includeDeleted query parameters listing companies
+func parseCompanyFilter(r *http.Request) (entity.CompanyFilter, error) {
+	includeDeleted, err := parseIncludeDeleted(r)
+	if err != nil {
+		return entity.CompanyFilter{}, errors.New("includeDeleted must be a boolean")
+	}
+
+	zipUnknown := false
+	if value := r.URL.Query().Get("zipUnknown"); value != "" {
+		zipUnknown, err = strconv.ParseBool(value)
+		if err != nil {
+			return entity.CompanyFilter{}, errors.New("zipUnknown must be a boolean")
+		}
+	}
+
+	return entity.CompanyFilter{
+		Name:           r.URL.Query().Get("name"),
+		Zip:            r.URL.Query().Get("zip"),
+		Country:        r.URL.Query().Get("country"),
+		ZipUnknown:     zipUnknown,
+		IncludeDeleted: includeDeleted,
+	}, nil
+}
+
+// parseCompanyID reads the company ID from the route variables
+func parseCompanyID(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(mux.Vars(r)["id"])
+}
+
+//GetCompanies GET /v1/companies?name={value}&zip={value}&country={value}&zipUnknown={value}&includeDeleted={value} application/json
 func (c *CompanyHandler) GetCompanies(w http.ResponseWriter, r *http.Request) {
-	companies, err := c.service.GetCompanies()
+	filter, err := parseCompanyFilter(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	companies, err := c.service.ListCompanies(r.Context(), filter)
+	if errors.Is(err, companyService.ERR_UNKNOWN_COUNTRY) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
+	if includesProvenance(r) {
+		details, err := c.companyDetails(r.Context(), companies)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		RespondJSON(w, http.StatusOK, details)
+		return
+	}
 	RespondJSON(w, http.StatusOK, companies)
 	return
 }
 
+// includesProvenance tells whether the request asked for ?include=provenance
+func includesProvenance(r *http.Request) bool {
+	for _, include := range strings.Split(r.URL.Query().Get("include"), ",") {
+		if strings.TrimSpace(include) == "provenance" {
+			return true
+		}
+	}
+	return false
+}
+
+// companyDetails attaches the provenance of each field to the companies
+func (c *CompanyHandler) companyDetails(ctx context.Context, companies []entity.Companies) ([]entity.CompanyDetails, error) {
+	ids := []uuid.UUID{}
+	for _, company := range companies {
+		ids = append(ids, company.ID)
+	}
+
+	provenance, err := c.service.GetProvenance(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	details := []entity.CompanyDetails{}
+	for _, company := range companies {
+		fields := provenance[company.ID]
+		if fields == nil {
+			fields = map[string]entity.Provenance{}
+		}
+		details = append(details, entity.CompanyDetails{Companies: company, Provenance: fields})
+	}
+	return details, nil
+}
+
+// respondCompany answers with a single company, attaching its provenance
+// when the request asks for it
+func (c *CompanyHandler) respondCompany(w http.ResponseWriter, r *http.Request, company *entity.Companies) {
+	if company == nil || !includesProvenance(r) {
+		RespondJSON(w, http.StatusOK, company)
+		return
+	}
+
+	details, err := c.companyDetails(r.Context(), []entity.Companies{*company})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, details[0])
+}
+
+//GetCompanyByID GET /v1/companies/{id}?includeDeleted={value} application/json
+func (c *CompanyHandler) GetCompanyByID(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	includeDeleted, err := parseIncludeDeleted(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "includeDeleted must be a boolean")
+		return
+	}
+
+	company, err := c.service.GetCompanyByID(r.Context(), id, includeDeleted)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if company == nil {
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	}
+	setETag(w, company.Version)
+	c.respondCompany(w, r, company)
+}
+
+// setETag exposes the company version as a strong entity tag
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", fmt.Sprintf(`"%d"`, version))
+}
+
+// parseIfMatch reads the version expected by the If-Match header. A missing
+// header or "*" returns zero, meaning any stored version is accepted.
+func parseIfMatch(r *http.Request) (int, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	value = strings.TrimPrefix(value, "W/")
+	version, err := strconv.Atoi(strings.Trim(value, `"`))
+	if err != nil || version <= 0 {
+		return 0, errors.New("If-Match must hold an ETag returned by this API")
+	}
+	return version, nil
+}
+
+// companyBody holds the fields of a company a PUT or PATCH body may write.
+// The others, such as the city, state and parent, are set by the server.
+// Fields missing from the body are nil.
+type companyBody struct {
+	Name       *string           `json:"name"`
+	Zip        *string           `json:"zipCode"`
+	Country    *string           `json:"country"`
+	Website    *string           `json:"website"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// decodeCompanyBody reads the fields of a PUT or PATCH body
+func decodeCompanyBody(r *http.Request) (companyBody, error) {
+	var body companyBody
+	err := json.NewDecoder(io.LimitReader(r.Body, 128*1024*8)).Decode(&body)
+	return body, err
+}
+
+// apply writes the fields sent in the body to company, the missing ones
+// keep their value
+func (body companyBody) apply(company *entity.Companies) {
+	if body.Name != nil {
+		company.Name = *body.Name
+	}
+	if body.Zip != nil {
+		company.Zip = *body.Zip
+	}
+	if body.Country != nil {
+		company.Country = *body.Country
+	}
+	if body.Website != nil {
+		company.Website = *body.Website
+	}
+	if body.Attributes != nil {
+		if company.Attributes == nil {
+			company.Attributes = map[string]string{}
+		}
+		for name, value := range body.Attributes {
+			company.Attributes[name] = value
+		}
+	}
+}
+
+// saveCompany stores a PUT or PATCH body and responds with the stored company
+func (c *CompanyHandler) saveCompany(w http.ResponseWriter, r *http.Request, company *entity.Companies) {
+	err := c.service.ReplaceCompany(r.Context(), company)
+	switch {
+	case errors.Is(err, companyService.ERR_COMPANY_NOT_EXISTS):
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	case errors.Is(err, companyService.ERR_VERSION_CONFLICT):
+		status := http.StatusConflict
+		if r.Header.Get("If-Match") != "" {
+			status = http.StatusPreconditionFailed
+		}
+		RespondError(w, status, err.Error())
+		return
+	case errors.Is(err, companyService.ERR_NOT_VALID_COMPANY),
+		errors.Is(err, companyService.ERR_ZIP_UNKNOWN),
+		errors.Is(err, companyService.ERR_UNKNOWN_ATTRIBUTE),
+		errors.Is(err, companyService.ERR_NOT_VALID_ATTRIBUTE):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	stored, err := c.service.GetCompanyByID(r.Context(), company.ID, false)
+	if err != nil || stored == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	setETag(w, stored.Version)
+	RespondJSON(w, http.StatusOK, stored)
+}
+
+//UpdateCompany PUT /v1/companies/{id} application/json
+func (c *CompanyHandler) UpdateCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	body, err := decodeCompanyBody(r)
+	if err != nil {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	company := entity.Companies{ID: id, Version: version}
+	body.apply(&company)
+
+	c.saveCompany(w, r, &company)
+}
+
+//PatchCompany PATCH /v1/companies/{id} application/json
+func (c *CompanyHandler) PatchCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	version, err := parseIfMatch(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	company, err := c.service.GetCompanyByID(r.Context(), id, false)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if company == nil {
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	}
+
+	body, err := decodeCompanyBody(r)
+	if err != nil {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	// fields missing from the body keep their stored values
+	body.apply(company)
+
+	company.ID = id
+	if version != 0 {
+		company.Version = version
+	}
+
+	c.saveCompany(w, r, company)
+}
+
+//DeleteCompany DELETE /v1/companies/{id}
+func (c *CompanyHandler) DeleteCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	company, err := c.service.GetCompanyByID(r.Context(), id, false)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if company == nil {
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	}
+
+	if err := c.service.DeleteCompany(r.Context(), *company); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//RestoreCompany POST /v1/companies/{id}/restore
+func (c *CompanyHandler) RestoreCompany(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	err = c.service.RestoreCompany(r.Context(), id)
+	if errors.Is(err, companyService.ERR_COMPANY_NOT_DELETED) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	company, err := c.service.GetCompanyByID(r.Context(), id, false)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, company)
+}
+
+//MergeInto POST /v1/companies/{id}/merge-into/{targetId}
+func (c *CompanyHandler) MergeInto(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	targetID, err := uuid.Parse(mux.Vars(r)["targetId"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid target company ID")
+		return
+	}
+
+	merge, err := c.service.MergeInto(r.Context(), id, targetID)
+	switch {
+	case errors.Is(err, companyService.ERR_COMPANY_NOT_EXISTS):
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	case errors.Is(err, companyService.ERR_SAME_COMPANY):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, companyService.ERR_VERSION_CONFLICT):
+		RespondError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	setETag(w, merge.Survivor.Version)
+	RespondJSON(w, http.StatusOK, merge)
+}
+
+//GetCompanyBySource GET /v1/companies/by-source/{source}/{externalId}
+func (c *CompanyHandler) GetCompanyBySource(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	company, err := c.service.FindBySource(r.Context(), vars["source"], vars["externalId"])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	if company == nil {
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	}
+	setETag(w, company.Version)
+	c.respondCompany(w, r, company)
+}
+
+//GetLocations GET /v1/companies/{id}/locations
+func (c *CompanyHandler) GetLocations(w http.ResponseWriter, r *http.Request) {
+	id, err := parseCompanyID(r)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid company ID")
+		return
+	}
+
+	parent, err := c.service.GetLocations(r.Context(), id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if parent == nil {
+		RespondError(w, http.StatusNotFound, "company not found")
+		return
+	}
+	RespondJSON(w, http.StatusOK, parent)
+}
+
 //GetCompanyByNameAndZip GET /v1/companies?name={value} application/json
 func (c *CompanyHandler) GetCompanyByName(w http.ResponseWriter, r *http.Request) {
 	name := r.URL.Query().Get("name")
@@ -79,7 +535,7 @@ func (c *CompanyHandler) GetCompanyByName(w http.ResponseWriter, r *http.Request
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	RespondJSON(w, http.StatusOK, companies)
+	c.respondCompany(w, r, companies)
 
 	return
 }
@@ -97,7 +553,7 @@ func (c *CompanyHandler) GetCompanyByNameAndZip(w http.ResponseWriter, r *http.R
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	RespondJSON(w, http.StatusOK, companies)
+	c.respondCompany(w, r, companies)
 
 	return
 }
@@ -138,41 +594,109 @@ func (c *CompanyHandler) CreateCompany(w http.ResponseWriter, r *http.Request) {
 	return
 }
 
-//MergeCompanies POST /v1/companies multipart/form-data
+//MergeCompanies POST /v1/companies/merge-all-companies?format={value} multipart/form-data, application/json or application/x-ndjson
 func (c *CompanyHandler) MergeCompanies(w http.ResponseWriter, r *http.Request) {
-	ERR_COMPANY_NOT_EXISTS := errors.New("Erro: there is no company with this name")
 	ctx := context.Background()
 
-	file, _, err := r.FormFile("csv")
+	file, fileName, format, err := uploadedFile(w, r)
+	if errors.Is(err, ERR_UPLOAD_TOO_LARGE) {
+		RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if errors.Is(err, recordsRepository.ERR_UNKNOWN_FORMAT) || errors.Is(err, recordsRepository.ERR_NOT_VALID_ARCHIVE) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	defer file.Close()
 
-	csvreader := csv.NewReader(bufio.NewReader(file))
-	csvreader.Comma = ';'
-	data, err := csvreader.ReadAll()
-
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
+	profile, err := c.importProfile(r)
+	if errors.Is(err, companyService.ERR_IMPORT_PROFILE_NOT_FOUND) {
+		RespondError(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	if len(data) == 0 {
-		w.WriteHeader(http.StatusBadRequest)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	companyData := csvRepository.CreateCompanyEntityByCSV(ctx, data)
-	for _, company := range companyData {
-		err = c.service.UpdateCompany(ctx, company)
+	if _, err := mergeSource(r, fileName, profile); err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-		if err != nil && errors.Is(err, ERR_COMPANY_NOT_EXISTS) {
-			w.WriteHeader(http.StatusBadRequest)
+	if format == recordsRepository.FORMAT_ZIP {
+		report, err := c.mergeArchive(ctx, r, file, fileName, profile)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		RespondJSON(w, http.StatusOK, report)
+		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	report, err := c.mergeFile(ctx, r, file, fileName, format, profile)
+	if errors.Is(err, ingest.ERR_EMPTY_FILE) {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, report)
 	return
 }
+
+// mergeSource describes the uploaded file as a client file read with the
+// profile, from the optional asOf form value. The source system and its
+// priority are decided by the server, an upload cannot claim them, and asOf
+// may only date the data back: a later time is the upload time, so a file
+// cannot win the newest-wins fields ahead of the data sent after it
+func mergeSource(r *http.Request, fileName string, profile *entity.ImportProfile) (entity.Source, error) {
+	source := companyService.ProfileSource(companyService.SOURCE_CLIENT, fileName, profile)
+	if r.FormValue("source") != "" || r.FormValue("priority") != "" {
+		return source, errors.New("source and priority are set by the import profile, not by the upload")
+	}
+
+	if value := r.FormValue("asOf"); value != "" {
+		asOf, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return source, errors.New("asOf must be a RFC 3339 timestamp")
+		}
+		if asOf.Before(source.Timestamp) {
+			source.Timestamp = asOf.UTC()
+		}
+	}
+	return source, nil
+}
+
+//GetAttributeSchema GET /v1/company-attributes application/json
+func (c *CompanyHandler) GetAttributeSchema(w http.ResponseWriter, r *http.Request) {
+	RespondJSON(w, http.StatusOK, c.service.AttributeSchema())
+}
+
+//RegisterAttribute PUT /v1/company-attributes/{name} application/json
+func (c *CompanyHandler) RegisterAttribute(w http.ResponseWriter, r *http.Request) {
+	var definition entity.AttributeDefinition
+	if err := json.NewDecoder(io.LimitReader(r.Body, 128*1024*8)).Decode(&definition); err != nil {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	definition.Name = mux.Vars(r)["name"]
+
+	err := c.service.RegisterAttribute(r.Context(), definition)
+	if errors.Is(err, companyService.ERR_NOT_VALID_ATTRIBUTE_SCHEMA) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, definition)
+}
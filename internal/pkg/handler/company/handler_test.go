@@ -11,21 +11,203 @@ import (
 	"mime/multipart"
 	"os"
 	"reflect"
+	"strings"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 type MockCompanyService struct {
-	GetCompaniesMock     func() ([]entity.Companies, error)
-	AddCompanyMock       func(ctx context.Context, company *entity.Companies) error
-	FindByNameAndZipMock func(name string, zip string) (*entity.Companies, error)
-	FindByNameMock       func(name string) (*entity.Companies, error)
-	UpdateCompanyMock    func(ctx context.Context, company *entity.Companies) error
-	DeleteCompanyMock    func(ctx context.Context, entity entity.Companies) error
+	GetCompaniesMock          func() ([]entity.Companies, error)
+	AddCompanyMock            func(ctx context.Context, company *entity.Companies) error
+	FindByNameAndZipMock      func(name string, zip string) (*entity.Companies, error)
+	FindByNameMock            func(name string) (*entity.Companies, error)
+	UpdateCompanyMock         func(ctx context.Context, company *entity.Companies) error
+	DeleteCompanyMock         func(ctx context.Context, entity entity.Companies) error
+	ListCompaniesMock         func(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error)
+	GetCompanyByIDMock        func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error)
+	RestoreCompanyMock        func(ctx context.Context, id uuid.UUID) error
+	ReplaceCompanyMock        func(ctx context.Context, company *entity.Companies) error
+	MergeCompaniesMock        func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport
+	ValidateCompaniesMock     func(ctx context.Context, companies []*entity.Companies, source *entity.Source) (*entity.ValidationReport, error)
+	GetProvenanceMock         func(ctx context.Context, companyIDs []uuid.UUID) (map[uuid.UUID]map[string]entity.Provenance, error)
+	AttributeSchemaMock       func() []entity.AttributeDefinition
+	RegisterAttributeMock     func(ctx context.Context, definition entity.AttributeDefinition) error
+	GetLocationsMock          func(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error)
+	DetectDuplicatesMock      func(ctx context.Context) ([]entity.DuplicateCandidate, error)
+	ListDuplicatesMock        func(ctx context.Context, status string) ([]entity.DuplicateCandidate, error)
+	ConfirmDuplicateMock      func(ctx context.Context, candidateID uuid.UUID, survivorID uuid.UUID) (*entity.Companies, error)
+	RejectDuplicateMock       func(ctx context.Context, candidateID uuid.UUID) error
+	MergeIntoMock             func(ctx context.Context, id uuid.UUID, targetID uuid.UUID) (*entity.CompanyMerge, error)
+	FindBySourceMock          func(ctx context.Context, sourceSystem string, externalID string) (*entity.Companies, error)
+	FindNearbyMock            func(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error)
+	ListImportProfilesMock    func(ctx context.Context) ([]entity.ImportProfile, error)
+	GetImportProfileMock      func(ctx context.Context, name string) (*entity.ImportProfile, error)
+	SaveImportProfileMock     func(ctx context.Context, profile *entity.ImportProfile) error
+	DeleteImportProfileMock   func(ctx context.Context, name string) error
+	ExportCompaniesMock       func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error
+	ListChangesMock           func(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error)
+	ListWebhooksMock          func(ctx context.Context) ([]entity.WebhookSubscription, error)
+	GetWebhookMock            func(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	CreateWebhookMock         func(ctx context.Context, subscription *entity.WebhookSubscription) error
+	UpdateWebhookMock         func(ctx context.Context, subscription *entity.WebhookSubscription) error
+	DeleteWebhookMock         func(ctx context.Context, id uuid.UUID) error
+	ListWebhookDeliveriesMock func(ctx context.Context, id uuid.UUID, status string) ([]entity.WebhookDelivery, error)
+	RetryWebhookDeliveryMock  func(ctx context.Context, id uuid.UUID) error
+}
+
+func (mcs *MockCompanyService) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	if mcs.ListWebhooksMock != nil {
+		return mcs.ListWebhooksMock(ctx)
+	}
+	return nil, errors.New("ListWebhooksMock")
+}
+
+func (mcs *MockCompanyService) GetWebhook(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	if mcs.GetWebhookMock != nil {
+		return mcs.GetWebhookMock(ctx, id)
+	}
+	return nil, errors.New("GetWebhookMock")
+}
+
+func (mcs *MockCompanyService) CreateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	if mcs.CreateWebhookMock != nil {
+		return mcs.CreateWebhookMock(ctx, subscription)
+	}
+	return errors.New("CreateWebhookMock")
+}
+
+func (mcs *MockCompanyService) UpdateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	if mcs.UpdateWebhookMock != nil {
+		return mcs.UpdateWebhookMock(ctx, subscription)
+	}
+	return errors.New("UpdateWebhookMock")
+}
+
+func (mcs *MockCompanyService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	if mcs.DeleteWebhookMock != nil {
+		return mcs.DeleteWebhookMock(ctx, id)
+	}
+	return errors.New("DeleteWebhookMock")
+}
+
+func (mcs *MockCompanyService) ListWebhookDeliveries(ctx context.Context, id uuid.UUID, status string) ([]entity.WebhookDelivery, error) {
+	if mcs.ListWebhookDeliveriesMock != nil {
+		return mcs.ListWebhookDeliveriesMock(ctx, id, status)
+	}
+	return nil, errors.New("ListWebhookDeliveriesMock")
+}
+
+func (mcs *MockCompanyService) RetryWebhookDelivery(ctx context.Context, id uuid.UUID) error {
+	if mcs.RetryWebhookDeliveryMock != nil {
+		return mcs.RetryWebhookDeliveryMock(ctx, id)
+	}
+	return errors.New("RetryWebhookDeliveryMock")
+}
+
+func (mcs *MockCompanyService) ListChanges(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error) {
+	if mcs.ListChangesMock != nil {
+		return mcs.ListChangesMock(ctx, cursor, limit)
+	}
+	return nil, errors.New("ListChangesMock")
+}
+
+func (mcs *MockCompanyService) ExportCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+	if mcs.ExportCompaniesMock != nil {
+		return mcs.ExportCompaniesMock(ctx, filter, each)
+	}
+	return errors.New("ExportCompaniesMock")
+}
+
+func (mcs *MockCompanyService) ListImportProfiles(ctx context.Context) ([]entity.ImportProfile, error) {
+	if mcs.ListImportProfilesMock != nil {
+		return mcs.ListImportProfilesMock(ctx)
+	}
+	return nil, errors.New("ListImportProfilesMock")
+}
+
+func (mcs *MockCompanyService) GetImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error) {
+	if mcs.GetImportProfileMock != nil {
+		return mcs.GetImportProfileMock(ctx, name)
+	}
+	return nil, errors.New("GetImportProfileMock")
+}
+
+func (mcs *MockCompanyService) SaveImportProfile(ctx context.Context, profile *entity.ImportProfile) error {
+	if mcs.SaveImportProfileMock != nil {
+		return mcs.SaveImportProfileMock(ctx, profile)
+	}
+	return errors.New("SaveImportProfileMock")
+}
+
+func (mcs *MockCompanyService) DeleteImportProfile(ctx context.Context, name string) error {
+	if mcs.DeleteImportProfileMock != nil {
+		return mcs.DeleteImportProfileMock(ctx, name)
+	}
+	return errors.New("DeleteImportProfileMock")
+}
+
+func (mcs *MockCompanyService) FindNearby(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error) {
+	if mcs.FindNearbyMock != nil {
+		return mcs.FindNearbyMock(ctx, zip, radiusMiles, limit, offset)
+	}
+	return nil, errors.New("FindNearbyMock")
+}
+
+func (mcs *MockCompanyService) FindBySource(ctx context.Context, sourceSystem string, externalID string) (*entity.Companies, error) {
+	if mcs.FindBySourceMock != nil {
+		return mcs.FindBySourceMock(ctx, sourceSystem, externalID)
+	}
+	return nil, errors.New("FindBySourceMock")
+}
+
+func (mcs *MockCompanyService) MergeInto(ctx context.Context, id uuid.UUID, targetID uuid.UUID) (*entity.CompanyMerge, error) {
+	if mcs.MergeIntoMock != nil {
+		return mcs.MergeIntoMock(ctx, id, targetID)
+	}
+	return nil, errors.New("MergeIntoMock")
+}
+
+func (mcs *MockCompanyService) DetectDuplicates(ctx context.Context) ([]entity.DuplicateCandidate, error) {
+	if mcs.DetectDuplicatesMock != nil {
+		return mcs.DetectDuplicatesMock(ctx)
+	}
+	return nil, errors.New("DetectDuplicatesMock")
+}
+
+func (mcs *MockCompanyService) ListDuplicateCandidates(ctx context.Context, status string) ([]entity.DuplicateCandidate, error) {
+	if mcs.ListDuplicatesMock != nil {
+		return mcs.ListDuplicatesMock(ctx, status)
+	}
+	return nil, errors.New("ListDuplicatesMock")
+}
+
+func (mcs *MockCompanyService) ConfirmDuplicate(ctx context.Context, candidateID uuid.UUID, survivorID uuid.UUID) (*entity.Companies, error) {
+	if mcs.ConfirmDuplicateMock != nil {
+		return mcs.ConfirmDuplicateMock(ctx, candidateID, survivorID)
+	}
+	return nil, errors.New("ConfirmDuplicateMock")
+}
+
+func (mcs *MockCompanyService) RejectDuplicate(ctx context.Context, candidateID uuid.UUID) error {
+	if mcs.RejectDuplicateMock != nil {
+		return mcs.RejectDuplicateMock(ctx, candidateID)
+	}
+	return errors.New("RejectDuplicateMock")
+}
+
+func (mcs *MockCompanyService) GetLocations(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+	if mcs.GetLocationsMock != nil {
+		return mcs.GetLocationsMock(ctx, id)
+	}
+	return nil, errors.New("GetLocationsMock")
 }
 
 func (mcs *MockCompanyService) GetCompanies() ([]entity.Companies, error) {
@@ -68,6 +250,69 @@ func (mcs *MockCompanyService) UpdateCompany(ctx context.Context, company *entit
 	return errors.New("UpdateCompanyMock")
 }
 
+func (mcs *MockCompanyService) ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error) {
+	if mcs.ListCompaniesMock != nil {
+		return mcs.ListCompaniesMock(ctx, filter)
+	}
+	return nil, errors.New("ListCompaniesMock")
+}
+
+func (mcs *MockCompanyService) GetCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+	if mcs.GetCompanyByIDMock != nil {
+		return mcs.GetCompanyByIDMock(ctx, id, includeDeleted)
+	}
+	return nil, errors.New("GetCompanyByIDMock")
+}
+
+func (mcs *MockCompanyService) RestoreCompany(ctx context.Context, id uuid.UUID) error {
+	if mcs.RestoreCompanyMock != nil {
+		return mcs.RestoreCompanyMock(ctx, id)
+	}
+	return errors.New("RestoreCompanyMock")
+}
+
+func (mcs *MockCompanyService) ReplaceCompany(ctx context.Context, company *entity.Companies) error {
+	if mcs.ReplaceCompanyMock != nil {
+		return mcs.ReplaceCompanyMock(ctx, company)
+	}
+	return errors.New("ReplaceCompanyMock")
+}
+
+func (mcs *MockCompanyService) MergeCompanies(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+	if mcs.MergeCompaniesMock != nil {
+		return mcs.MergeCompaniesMock(ctx, companies, source)
+	}
+	return &entity.MergeReport{}
+}
+
+func (mcs *MockCompanyService) ValidateCompanies(ctx context.Context, companies []*entity.Companies, source *entity.Source) (*entity.ValidationReport, error) {
+	if mcs.ValidateCompaniesMock != nil {
+		return mcs.ValidateCompaniesMock(ctx, companies, source)
+	}
+	return nil, errors.New("ValidateCompaniesMock")
+}
+
+func (mcs *MockCompanyService) GetProvenance(ctx context.Context, companyIDs []uuid.UUID) (map[uuid.UUID]map[string]entity.Provenance, error) {
+	if mcs.GetProvenanceMock != nil {
+		return mcs.GetProvenanceMock(ctx, companyIDs)
+	}
+	return nil, errors.New("GetProvenanceMock")
+}
+
+func (mcs *MockCompanyService) AttributeSchema() []entity.AttributeDefinition {
+	if mcs.AttributeSchemaMock != nil {
+		return mcs.AttributeSchemaMock()
+	}
+	return nil
+}
+
+func (mcs *MockCompanyService) RegisterAttribute(ctx context.Context, definition entity.AttributeDefinition) error {
+	if mcs.RegisterAttributeMock != nil {
+		return mcs.RegisterAttributeMock(ctx, definition)
+	}
+	return errors.New("RegisterAttributeMock")
+}
+
 type Service struct {
 	service CompanyService
 }
@@ -110,8 +355,8 @@ func CreateHttpRequestAndResponse(fileName string) (*http.Request, *httptest.Res
 func TestMergeCompanies(t *testing.T) {
 	t.Run("error in database", func(t *testing.T) {
 		companyService := &MockCompanyService{
-			UpdateCompanyMock: func(ctx context.Context, company *entity.Companies) error {
-				return errors.New("error")
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				return &entity.MergeReport{Total: len(companies), Failed: len(companies)}
 			},
 		}
 		data := "name;addresszip;website \n tola sales group;78229;http://repsources.com"
@@ -132,8 +377,8 @@ func TestMergeCompanies(t *testing.T) {
 	t.Run("Error in Formfile", func(t *testing.T) {
 
 		companyService := &MockCompanyService{
-			UpdateCompanyMock: func(ctx context.Context, company *entity.Companies) error {
-				return errors.New("error")
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				return &entity.MergeReport{Total: len(companies), Failed: len(companies)}
 			},
 		}
 
@@ -159,8 +404,8 @@ func TestMergeCompanies(t *testing.T) {
 
 	t.Run("Error in Lenght Data", func(t *testing.T) {
 		companyService := &MockCompanyService{
-			UpdateCompanyMock: func(ctx context.Context, company *entity.Companies) error {
-				return nil
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				return &entity.MergeReport{Total: len(companies), Merged: len(companies)}
 			},
 		}
 
@@ -181,8 +426,8 @@ func TestMergeCompanies(t *testing.T) {
 	})
 	t.Run("Correrct Update Data", func(t *testing.T) {
 		companyService := &MockCompanyService{
-			UpdateCompanyMock: func(ctx context.Context, company *entity.Companies) error {
-				return nil
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				return &entity.MergeReport{Total: len(companies), Merged: len(companies)}
 			},
 		}
 
@@ -199,6 +444,114 @@ func TestMergeCompanies(t *testing.T) {
 		}
 		os.Remove(fileName)
 	})
+
+	t.Run("source of the merge", func(t *testing.T) {
+		var gotSource entity.Source
+		mockService := &MockCompanyService{
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				gotSource = source
+				return &entity.MergeReport{}
+			},
+		}
+
+		data := "name;addresszip;website \n tola sales group;78229;http://repsources.com"
+		fileName := CreatTestFile(data)
+		request, response := CreateHttpRequestAndResponse(fileName)
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.MergeCompanies(response, request)
+
+		if gotSource.System != companyService.SOURCE_CLIENT || gotSource.Priority != companyService.SourcePriority(companyService.SOURCE_CLIENT) || !strings.HasPrefix(gotSource.File, "test_file_") || gotSource.ImportJob == "" {
+			t.Errorf("got source %v", gotSource)
+		}
+		os.Remove(fileName)
+	})
+}
+
+func TestMergeCompaniesReport(t *testing.T) {
+	t.Run("responds with the report", func(t *testing.T) {
+		companyService := &MockCompanyService{
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				return &entity.MergeReport{ImportJob: source.ImportJob, Total: len(companies), Merged: len(companies)}
+			},
+		}
+
+		data := "name;addresszip;website\ntola sales group;78229;http://repsources.com"
+		fileName := CreatTestFile(data)
+		request, response := CreateHttpRequestAndResponse(fileName)
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.MergeCompanies(response, request)
+
+		var report entity.MergeReport
+		if err := json.Unmarshal(response.Body.Bytes(), &report); err != nil {
+			t.Errorf(`got "%v", but expected none"`, err)
+		}
+		if report.Total != 1 || report.Merged != 1 || report.ImportJob == "" {
+			t.Errorf("got report %v", report)
+		}
+		os.Remove(fileName)
+	})
+
+	t.Run("priority sent by the client", func(t *testing.T) {
+		body := &bytes.Buffer{}
+		mpWriter := multipart.NewWriter(body)
+		ioWriter, _ := mpWriter.CreateFormFile("csv", "client.csv")
+		ioWriter.Write([]byte("name;addresszip;website\ntola sales group;78229;http://repsources.com"))
+		mpWriter.WriteField("priority", "100")
+		mpWriter.Close()
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", bytes.NewReader(body.Bytes()))
+		request.Header.Add("Content-Type", mpWriter.FormDataContentType())
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		companyHandler.MergeCompanies(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("asOf sent by the client", func(t *testing.T) {
+		past := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+		for asOf, latest := range map[string]bool{past.Format(time.RFC3339): false, "2999-01-01T00:00:00Z": true} {
+			body := &bytes.Buffer{}
+			mpWriter := multipart.NewWriter(body)
+			ioWriter, _ := mpWriter.CreateFormFile("csv", "client.csv")
+			ioWriter.Write([]byte("name;addresszip;website\ntola sales group;78229;http://repsources.com"))
+			mpWriter.WriteField("asOf", asOf)
+			mpWriter.Close()
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", bytes.NewReader(body.Bytes()))
+			request.Header.Add("Content-Type", mpWriter.FormDataContentType())
+			response := httptest.NewRecorder()
+
+			var got entity.Source
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(&MockCompanyService{
+				MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+					got = source
+					return &entity.MergeReport{Total: len(companies), Merged: len(companies)}
+				},
+			})
+
+			before := time.Now()
+			companyHandler.MergeCompanies(response, request)
+
+			if latest && (got.Timestamp.Before(before.Add(-time.Second)) || got.Timestamp.After(time.Now())) {
+				t.Errorf("got %v for %v want the upload time", got.Timestamp, asOf)
+			}
+			if !latest && !got.Timestamp.Equal(past) {
+				t.Errorf("got %v for %v want %v", got.Timestamp, asOf, past)
+			}
+		}
+	})
 }
 
 func TestCreateCompany(t *testing.T) {
@@ -306,7 +659,7 @@ func TestGetCompanyByNameAndZip(t *testing.T) {
 			t.Errorf(`got "%v", but expected none"`, err)
 		}
 		if !reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want %s"`, readCompany, company)
+			t.Errorf(`got "%v", want %v"`, readCompany, company)
 		}
 	})
 
@@ -340,7 +693,7 @@ func TestGetCompanyByNameAndZip(t *testing.T) {
 			t.Errorf("expected error got %v, but expected %v", response.Code, http.StatusInternalServerError)
 		}
 		if reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want empty company`, readCompany)
+			t.Errorf(`got "%v", want empty company`, readCompany)
 		}
 	})
 	t.Run("not exist company", func(t *testing.T) {
@@ -373,7 +726,7 @@ func TestGetCompanyByNameAndZip(t *testing.T) {
 			t.Errorf("not expected error got %v", response.Code)
 		}
 		if reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want empty company`, readCompany)
+			t.Errorf(`got "%v", want empty company`, readCompany)
 		}
 	})
 }
@@ -408,7 +761,7 @@ func TestGetCompanyByName(t *testing.T) {
 			t.Errorf(`got "%v", but expected none"`, err)
 		}
 		if !reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want %s"`, readCompany, company)
+			t.Errorf(`got "%v", want %v"`, readCompany, company)
 		}
 	})
 
@@ -441,7 +794,7 @@ func TestGetCompanyByName(t *testing.T) {
 		}
 		json.Unmarshal(body, &readCompany)
 		if reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want empty company`, readCompany)
+			t.Errorf(`got "%v", want empty company`, readCompany)
 		}
 	})
 	t.Run("not exist company", func(t *testing.T) {
@@ -474,7 +827,606 @@ func TestGetCompanyByName(t *testing.T) {
 			t.Errorf("not expected error got %v", response.Code)
 		}
 		if reflect.DeepEqual(company, &readCompany) {
-			t.Errorf(`got "%s", want empty company`, readCompany)
+			t.Errorf(`got "%v", want empty company`, readCompany)
+		}
+	})
+}
+
+func TestGetCompanies(t *testing.T) {
+	t.Run("list with includeDeleted", func(t *testing.T) {
+		var gotFilter entity.CompanyFilter
+		companyService := &MockCompanyService{
+			ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error) {
+				gotFilter = filter
+				return []entity.Companies{}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies?includeDeleted=true", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.GetCompanies(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		if !gotFilter.IncludeDeleted {
+			t.Errorf("expected includeDeleted to be passed to the service")
+		}
+	})
+
+	t.Run("list with zipUnknown", func(t *testing.T) {
+		var gotFilter entity.CompanyFilter
+		mockService := &MockCompanyService{
+			ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error) {
+				gotFilter = filter
+				return []entity.Companies{}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies?zipUnknown=true", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetCompanies(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		if !gotFilter.ZipUnknown {
+			t.Errorf("expected zipUnknown to be passed to the service")
+		}
+	})
+
+	t.Run("invalid includeDeleted", func(t *testing.T) {
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies?includeDeleted=maybe", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler.GetCompanies(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestDeleteCompany(t *testing.T) {
+	t.Run("soft deleting company", func(t *testing.T) {
+		id := uuid.New()
+		deleted := false
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id}, nil
+			},
+			DeleteCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				deleted = company.ID == id
+				return nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodDelete, "/v1/companies/"+id.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.DeleteCompany(response, request)
+
+		if response.Code != http.StatusNoContent {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusNoContent)
+		}
+		if !deleted {
+			t.Errorf("expected company %v to be deleted", id)
+		}
+	})
+
+	t.Run("not exist company", func(t *testing.T) {
+		id := uuid.New()
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return nil, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodDelete, "/v1/companies/"+id.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.DeleteCompany(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestRestoreCompany(t *testing.T) {
+	t.Run("restoring company", func(t *testing.T) {
+		id := uuid.New()
+		companyService := &MockCompanyService{
+			RestoreCompanyMock: func(ctx context.Context, id uuid.UUID) error {
+				return nil
+			},
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id, Name: "COMPANY"}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/companies/"+id.String()+"/restore", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.RestoreCompany(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("company is not deleted", func(t *testing.T) {
+		id := uuid.New()
+		mockService := &MockCompanyService{
+			RestoreCompanyMock: func(ctx context.Context, id uuid.UUID) error {
+				return companyService.ERR_COMPANY_NOT_DELETED
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPost, "/v1/companies/"+id.String()+"/restore", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.RestoreCompany(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusNotFound)
+		}
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/v1/companies/abc/restore", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": "abc"})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		companyHandler.RestoreCompany(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestUpdateCompany(t *testing.T) {
+	t.Run("replacing with If-Match", func(t *testing.T) {
+		id := uuid.New()
+		var replaced entity.Companies
+		companyService := &MockCompanyService{
+			ReplaceCompanyMock: func(ctx context.Context, company *entity.Companies) error {
+				replaced = *company
+				return nil
+			},
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id, Name: "COMPANY", Version: 3}, nil
+			},
+		}
+
+		body := `{"name":"company","zipCode":"12345","website":"http://company.com"}`
+		request := httptest.NewRequest(http.MethodPut, "/v1/companies/"+id.String(), bytes.NewBufferString(body))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		request.Header.Set("If-Match", `"2"`)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.UpdateCompany(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		if replaced.ID != id || replaced.Version != 2 {
+			t.Errorf("got %v, want ID %v and version 2", replaced, id)
+		}
+		if response.Header().Get("ETag") != `"3"` {
+			t.Errorf("got ETag %s, want \"3\"", response.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("stale If-Match", func(t *testing.T) {
+		id := uuid.New()
+		companyService := &MockCompanyService{
+			ReplaceCompanyMock: func(ctx context.Context, company *entity.Companies) error {
+				return companyService.ERR_VERSION_CONFLICT
+			},
+		}
+
+		body := `{"name":"company","zipCode":"12345"}`
+		request := httptest.NewRequest(http.MethodPut, "/v1/companies/"+id.String(), bytes.NewBufferString(body))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		request.Header.Set("If-Match", `"1"`)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.UpdateCompany(response, request)
+
+		if response.Code != http.StatusPreconditionFailed {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusPreconditionFailed)
+		}
+	})
+
+	t.Run("invalid If-Match", func(t *testing.T) {
+		id := uuid.New()
+		request := httptest.NewRequest(http.MethodPut, "/v1/companies/"+id.String(), bytes.NewBufferString(`{}`))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		request.Header.Set("If-Match", `"abc"`)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+
+		companyHandler.UpdateCompany(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestPatchCompany(t *testing.T) {
+	t.Run("patching keeps missing fields", func(t *testing.T) {
+		id := uuid.New()
+		stored := entity.Companies{ID: id, Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 4}
+		var replaced entity.Companies
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				company := stored
+				return &company, nil
+			},
+			ReplaceCompanyMock: func(ctx context.Context, company *entity.Companies) error {
+				replaced = *company
+				return nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPatch, "/v1/companies/"+id.String(), bytes.NewBufferString(`{"website":"http://new.com"}`))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.PatchCompany(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		if replaced.Name != "COMPANY" || replaced.Website != "http://new.com" || replaced.Version != 4 {
+			t.Errorf("got %v", replaced)
+		}
+	})
+
+	t.Run("server-owned fields are kept", func(t *testing.T) {
+		id := uuid.New()
+		parentID := uuid.New()
+		stored := entity.Companies{ID: id, ParentID: parentID, Name: "COMPANY", Zip: "78229", City: "SAN ANTONIO", State: "TX", Version: 4}
+		var replaced entity.Companies
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				company := stored
+				return &company, nil
+			},
+			ReplaceCompanyMock: func(ctx context.Context, company *entity.Companies) error {
+				replaced = *company
+				return nil
+			},
+		}
+
+		body := `{"name":"company llc","city":"AUSTIN","state":"CA","zipUnknown":true,"parentId":"` + uuid.NewString() + `","version":9}`
+		request := httptest.NewRequest(http.MethodPatch, "/v1/companies/"+id.String(), bytes.NewBufferString(body))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.PatchCompany(response, request)
+
+		want := stored
+		want.Name = "company llc"
+		if response.Code != http.StatusOK || !reflect.DeepEqual(want, replaced) {
+			t.Errorf("got %d, %v want %v", response.Code, replaced, want)
+		}
+	})
+
+	t.Run("conflict without If-Match", func(t *testing.T) {
+		id := uuid.New()
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id, Version: 1}, nil
+			},
+			ReplaceCompanyMock: func(ctx context.Context, company *entity.Companies) error {
+				return companyService.ERR_VERSION_CONFLICT
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPatch, "/v1/companies/"+id.String(), bytes.NewBufferString(`{"website":"http://new.com"}`))
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.PatchCompany(response, request)
+
+		if response.Code != http.StatusConflict {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusConflict)
+		}
+	})
+}
+
+func TestGetCompanyWithProvenance(t *testing.T) {
+	t.Run("include provenance", func(t *testing.T) {
+		id := uuid.New()
+		provenance := entity.Provenance{Field: entity.FIELD_WEBSITE, SourceSystem: "client-csv", SourceFile: "q2_clientData.csv"}
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id, Name: "COMPANY"}, nil
+			},
+			GetProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) (map[uuid.UUID]map[string]entity.Provenance, error) {
+				return map[uuid.UUID]map[string]entity.Provenance{id: {entity.FIELD_WEBSITE: provenance}}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/"+id.String()+"?include=provenance", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.GetCompanyByID(response, request)
+
+		var details entity.CompanyDetails
+		if err := json.Unmarshal(response.Body.Bytes(), &details); err != nil {
+			t.Errorf(`got "%v", but expected none"`, err)
+		}
+		if details.ID != id || details.Provenance[entity.FIELD_WEBSITE].SourceFile != provenance.SourceFile {
+			t.Errorf("got %v", details)
 		}
 	})
+
+	t.Run("without include", func(t *testing.T) {
+		id := uuid.New()
+		companyService := &MockCompanyService{
+			GetCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return &entity.Companies{ID: id, Name: "COMPANY"}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/"+id.String(), nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.GetCompanyByID(response, request)
+
+		if bytes.Contains(response.Body.Bytes(), []byte("provenance")) {
+			t.Errorf("not expected provenance in %s", response.Body.String())
+		}
+	})
+}
+
+func TestRegisterAttribute(t *testing.T) {
+	t.Run("registering attribute", func(t *testing.T) {
+		var registered entity.AttributeDefinition
+		companyService := &MockCompanyService{
+			RegisterAttributeMock: func(ctx context.Context, definition entity.AttributeDefinition) error {
+				registered = definition
+				return nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPut, "/v1/company-attributes/employees", bytes.NewBufferString(`{"type":"integer"}`))
+		request = mux.SetURLVars(request, map[string]string{"name": "employees"})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(companyService)
+
+		companyHandler.RegisterAttribute(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+		if registered.Name != "employees" || registered.Type != entity.ATTRIBUTE_TYPE_INTEGER {
+			t.Errorf("got %v", registered)
+		}
+	})
+
+	t.Run("invalid definition", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			RegisterAttributeMock: func(ctx context.Context, definition entity.AttributeDefinition) error {
+				return companyService.ERR_NOT_VALID_ATTRIBUTE_SCHEMA
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodPut, "/v1/company-attributes/Employees", bytes.NewBufferString(`{"type":"integer"}`))
+		request = mux.SetURLVars(request, map[string]string{"name": "Employees"})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.RegisterAttribute(response, request)
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+func TestGetLocations(t *testing.T) {
+	t.Run("with locations", func(t *testing.T) {
+		id := uuid.New()
+		parent := &entity.ParentCompany{
+			ID:        uuid.New(),
+			Name:      "COMPANY",
+			Locations: []entity.Companies{{ID: id, Name: "COMPANY", Zip: "12345"}},
+		}
+		mockService := &MockCompanyService{
+			GetLocationsMock: func(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+				return parent, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/"+id.String()+"/locations", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetLocations(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+
+		var got entity.ParentCompany
+		json.NewDecoder(response.Body).Decode(&got)
+		if got.ID != parent.ID || len(got.Locations) != 1 || got.Locations[0].ID != id {
+			t.Errorf("got %v want %v", got, parent)
+		}
+	})
+
+	t.Run("unknown company", func(t *testing.T) {
+		id := uuid.New()
+		mockService := &MockCompanyService{
+			GetLocationsMock: func(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+				return nil, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/"+id.String()+"/locations", nil)
+		request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetLocations(response, request)
+
+		if response.Code != http.StatusNotFound {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusNotFound)
+		}
+	})
+}
+
+func TestMergeInto(t *testing.T) {
+	id := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name   string
+		target string
+		err    error
+		want   int
+	}{
+		{"merging", targetID.String(), nil, http.StatusOK},
+		{"invalid target", "abc", nil, http.StatusBadRequest},
+		{"unknown company", targetID.String(), companyService.ERR_COMPANY_NOT_EXISTS, http.StatusNotFound},
+		{"into itself", targetID.String(), companyService.ERR_SAME_COMPANY, http.StatusBadRequest},
+		{"concurrent change", targetID.String(), companyService.ERR_VERSION_CONFLICT, http.StatusConflict},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockCompanyService{
+				MergeIntoMock: func(ctx context.Context, id uuid.UUID, target uuid.UUID) (*entity.CompanyMerge, error) {
+					if test.err != nil {
+						return nil, test.err
+					}
+					return &entity.CompanyMerge{Survivor: entity.Companies{ID: target, Version: 3}, MergedID: id}, nil
+				},
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/v1/companies/"+id.String()+"/merge-into/"+test.target, nil)
+			request = mux.SetURLVars(request, map[string]string{"id": id.String(), "targetId": test.target})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+
+			companyHandler.MergeInto(response, request)
+
+			if response.Code != test.want {
+				t.Errorf("got: %d, want: %d", response.Code, test.want)
+			}
+			if test.want == http.StatusOK && response.Header().Get("ETag") != `"3"` {
+				t.Errorf("got ETag %q want %q", response.Header().Get("ETag"), `"3"`)
+			}
+		})
+	}
+}
+
+func TestGetCompanyBySource(t *testing.T) {
+	id := uuid.New()
+	mockService := &MockCompanyService{
+		FindBySourceMock: func(ctx context.Context, sourceSystem string, externalID string) (*entity.Companies, error) {
+			if sourceSystem == "client-csv" && externalID == "C-1001" {
+				return &entity.Companies{ID: id, Name: "COMPANY"}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	tests := []struct {
+		name       string
+		externalID string
+		want       int
+	}{
+		{"known key", "C-1001", http.StatusOK},
+		{"unknown key", "C-9999", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v1/companies/by-source/client-csv/"+test.externalID, nil)
+			request = mux.SetURLVars(request, map[string]string{"source": "client-csv", "externalId": test.externalID})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+
+			companyHandler.GetCompanyBySource(response, request)
+
+			if response.Code != test.want {
+				t.Errorf("got: %d, want: %d", response.Code, test.want)
+			}
+		})
+	}
 }
@@ -0,0 +1,90 @@
+package company
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/gorilla/mux"
+)
+
+//GetImportProfiles GET /v1/import-profiles application/json
+func (c *CompanyHandler) GetImportProfiles(w http.ResponseWriter, r *http.Request) {
+	profiles, err := c.service.ListImportProfiles(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, profiles)
+}
+
+//GetImportProfile GET /v1/import-profiles/{name} application/json
+func (c *CompanyHandler) GetImportProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := c.service.GetImportProfile(r.Context(), mux.Vars(r)["name"])
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if profile == nil {
+		RespondError(w, http.StatusNotFound, "import profile not found")
+		return
+	}
+	RespondJSON(w, http.StatusOK, profile)
+}
+
+//PutImportProfile PUT /v1/import-profiles/{name} application/json
+func (c *CompanyHandler) PutImportProfile(w http.ResponseWriter, r *http.Request) {
+	var profile entity.ImportProfile
+	if err := json.NewDecoder(io.LimitReader(r.Body, 128*1024*8)).Decode(&profile); err != nil {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	profile.Name = mux.Vars(r)["name"]
+
+	err := c.service.SaveImportProfile(r.Context(), &profile)
+	if errors.Is(err, companyService.ERR_NOT_VALID_IMPORT_PROFILE) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, profile)
+}
+
+//DeleteImportProfile DELETE /v1/import-profiles/{name}
+func (c *CompanyHandler) DeleteImportProfile(w http.ResponseWriter, r *http.Request) {
+	err := c.service.DeleteImportProfile(r.Context(), mux.Vars(r)["name"])
+	if errors.Is(err, companyService.ERR_IMPORT_PROFILE_NOT_FOUND) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importProfile reads the optional profile form value of an upload, nil when
+// the file uses the default layout
+func (c *CompanyHandler) importProfile(r *http.Request) (*entity.ImportProfile, error) {
+	name := r.FormValue("profile")
+	if name == "" {
+		return nil, nil
+	}
+
+	profile, err := c.service.GetImportProfile(r.Context(), name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, companyService.ERR_IMPORT_PROFILE_NOT_FOUND
+	}
+	return profile, nil
+}
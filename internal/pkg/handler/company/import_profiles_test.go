@@ -0,0 +1,191 @@
+package company
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/gorilla/mux"
+)
+
+func TestPutImportProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"saved", `{"delimiter": ",", "columns": {"Company": "name"}}`, nil, http.StatusOK},
+		{"not valid", `{"columns": {"Company": "phone number"}}`, companyService.ERR_NOT_VALID_IMPORT_PROFILE, http.StatusBadRequest},
+		{"not json", `columns`, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotProfile *entity.ImportProfile
+			mockService := &MockCompanyService{
+				SaveImportProfileMock: func(ctx context.Context, profile *entity.ImportProfile) error {
+					gotProfile = profile
+					return test.err
+				},
+			}
+			request := httptest.NewRequest(http.MethodPut, "/v1/import-profiles/crm", bytes.NewBufferString(test.body))
+			request = mux.SetURLVars(request, map[string]string{"name": "crm"})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.PutImportProfile(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+			if test.wantStatus == http.StatusOK && (gotProfile.Name != "crm" || gotProfile.Columns["Company"] != entity.IMPORT_FIELD_NAME) {
+				t.Errorf("got profile %v", gotProfile)
+			}
+		})
+	}
+}
+
+func TestGetImportProfile(t *testing.T) {
+	mockService := &MockCompanyService{
+		GetImportProfileMock: func(ctx context.Context, name string) (*entity.ImportProfile, error) {
+			if name == "crm" {
+				return &entity.ImportProfile{Name: "crm"}, nil
+			}
+			return nil, nil
+		},
+	}
+	companyHandler := NewCompanyHandler()
+	companyHandler.Register(mockService)
+
+	for name, wantStatus := range map[string]int{"crm": http.StatusOK, "erp": http.StatusNotFound} {
+		request := httptest.NewRequest(http.MethodGet, "/v1/import-profiles/"+name, nil)
+		request = mux.SetURLVars(request, map[string]string{"name": name})
+		response := httptest.NewRecorder()
+
+		companyHandler.GetImportProfile(response, request)
+
+		if response.Code != wantStatus {
+			t.Errorf("got: %d, want: %d", response.Code, wantStatus)
+		}
+	}
+}
+
+func TestDeleteImportProfile(t *testing.T) {
+	mockService := &MockCompanyService{
+		DeleteImportProfileMock: func(ctx context.Context, name string) error {
+			if name == "crm" {
+				return nil
+			}
+			return companyService.ERR_IMPORT_PROFILE_NOT_FOUND
+		},
+	}
+	companyHandler := NewCompanyHandler()
+	companyHandler.Register(mockService)
+
+	for name, wantStatus := range map[string]int{"crm": http.StatusNoContent, "erp": http.StatusNotFound} {
+		request := httptest.NewRequest(http.MethodDelete, "/v1/import-profiles/"+name, nil)
+		request = mux.SetURLVars(request, map[string]string{"name": name})
+		response := httptest.NewRecorder()
+
+		companyHandler.DeleteImportProfile(response, request)
+
+		if response.Code != wantStatus {
+			t.Errorf("got: %d, want: %d", response.Code, wantStatus)
+		}
+	}
+}
+
+func newProfileUpload(profile string, data string) *http.Request {
+	body := &bytes.Buffer{}
+	mpWriter := multipart.NewWriter(body)
+	ioWriter, _ := mpWriter.CreateFormFile("csv", "partner.csv")
+	ioWriter.Write([]byte(data))
+	mpWriter.WriteField("profile", profile)
+	mpWriter.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", bytes.NewReader(body.Bytes()))
+	request.Header.Add("Content-Type", mpWriter.FormDataContentType())
+	return request
+}
+
+func TestMergeCompaniesWithProfile(t *testing.T) {
+	profile := &entity.ImportProfile{
+		Name:      "partner",
+		Delimiter: "|",
+		Columns:   map[string]string{"Company": entity.IMPORT_FIELD_NAME, "Postal": entity.IMPORT_FIELD_ZIP},
+		Priority:  70,
+	}
+
+	t.Run("mapping the file", func(t *testing.T) {
+		var gotCompanies []*entity.Companies
+		var gotSource entity.Source
+		mockService := &MockCompanyService{
+			GetImportProfileMock: func(ctx context.Context, name string) (*entity.ImportProfile, error) {
+				return profile, nil
+			},
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				gotCompanies, gotSource = companies, source
+				return &entity.MergeReport{Total: len(companies)}
+			},
+		}
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(response, newProfileUpload("partner", "Postal|Company\n78229|tola sales group"))
+
+		var report entity.MergeReport
+		json.Unmarshal(response.Body.Bytes(), &report)
+		if response.Code != http.StatusOK || report.Total != 1 {
+			t.Errorf("got: %d, %v", response.Code, report)
+		}
+		if len(gotCompanies) != 1 || gotCompanies[0].Name != "TOLA SALES GROUP" || gotCompanies[0].Zip != "78229" {
+			t.Errorf("got companies %v", gotCompanies)
+		}
+		if gotSource.Priority != profile.Priority {
+			t.Errorf("got priority %v want %v", gotSource.Priority, profile.Priority)
+		}
+	})
+
+	t.Run("unknown profile", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			GetImportProfileMock: func(ctx context.Context, name string) (*entity.ImportProfile, error) {
+				return nil, nil
+			},
+		}
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(response, newProfileUpload("erp", "Postal|Company\n78229|tola sales group"))
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("file not matching the profile", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			GetImportProfileMock: func(ctx context.Context, name string) (*entity.ImportProfile, error) {
+				return profile, nil
+			},
+		}
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(response, newProfileUpload("partner", "name;zip\ntola sales group;78229"))
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	ingest "github.com/eduardojabes/data-integration-challenge/internal/pkg/ingest/company"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+)
+
+// MAX_UPLOAD_SIZE bounds the body of an upload, multipart or not
+const MAX_UPLOAD_SIZE = 32 << 20
+
+var ERR_UPLOAD_TOO_LARGE = errors.New("Error: upload exceeds the size limit")
+
+// uploadBody fails the reads past MAX_UPLOAD_SIZE, before multipart forms are
+// spooled to disk or archives are read in memory
+type uploadBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *uploadBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ERR_UPLOAD_TOO_LARGE
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n, ERR_UPLOAD_TOO_LARGE
+	}
+	return n, err
+}
+
+// uploadedFile returns the file of an import with its name and format. A
+// multipart request carries it under the "csv" key, any other request in its
+// body; the optional format value wins over the content type.
+func uploadedFile(w http.ResponseWriter, r *http.Request) (io.ReadCloser, string, string, error) {
+	var file io.ReadCloser
+	var fileName, contentType string
+
+	body := &uploadBody{ReadCloser: r.Body, remaining: MAX_UPLOAD_SIZE}
+	r.Body = body
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		multipartFile, header, err := r.FormFile("csv")
+		if body.remaining < 0 {
+			return nil, "", "", ERR_UPLOAD_TOO_LARGE
+		}
+		if err != nil {
+			return nil, "", "", err
+		}
+		file, fileName, contentType = multipartFile, header.Filename, header.Header.Get("Content-Type")
+	} else {
+		file, contentType = body, r.Header.Get("Content-Type")
+	}
+
+	content, fileName, err := recordsRepository.Decompress(file, fileName, nil)
+	if err != nil {
+		file.Close()
+		return nil, "", "", err
+	}
+
+	format := r.FormValue("format")
+	if format == "" {
+		format = recordsRepository.DetectFormat(contentType, fileName)
+	}
+
+	if _, err := recordsRepository.NewRecordReader(format, recordsRepository.ReaderOptions{}); err != nil && format != recordsRepository.FORMAT_ZIP {
+		file.Close()
+		return nil, "", "", err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{content, file}, fileName, format, nil
+}
+
+// mergeFile reads the records of a file and merges them as one import job
+func (c *CompanyHandler) mergeFile(ctx context.Context, r *http.Request, file io.Reader, fileName string, format string, profile *entity.ImportProfile) (*entity.MergeReport, error) {
+	source, err := mergeSource(r, fileName, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	companyData, err := ingest.ReadCompanies(ctx, file, format, ingest.Options{Sheet: r.FormValue("sheet"), Profile: profile})
+	if err != nil {
+		return nil, err
+	}
+	return c.service.MergeCompanies(ctx, companyData, source), nil
+}
+
+// mergeArchive merges each file of a zip archive as an import job of its
+// own; a file that cannot be read is reported without stopping the others
+func (c *CompanyHandler) mergeArchive(ctx context.Context, r *http.Request, file io.Reader, fileName string, profile *entity.ImportProfile) (*entity.ArchiveReport, error) {
+	files, err := recordsRepository.ReadArchive(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.ArchiveReport{SourceFile: fileName, Files: []entity.ArchiveFileReport{}}
+	for _, archived := range files {
+		fileReport := entity.ArchiveFileReport{File: archived.Name}
+
+		format := recordsRepository.DetectFormat("", strings.TrimSuffix(archived.Name, ".gz"))
+		if format == recordsRepository.FORMAT_ZIP {
+			fileReport.Error = "archives inside archives are not read"
+			report.Files = append(report.Files, fileReport)
+			continue
+		}
+
+		content, err := archived.Open()
+		if err == nil {
+			fileReport.Report, err = c.mergeFile(ctx, r, content, path.Join(fileName, archived.Name), format, profile)
+			content.Close()
+		}
+		if err != nil {
+			fileReport.Error = err.Error()
+		}
+		report.Files = append(report.Files, fileReport)
+	}
+	return report, nil
+}
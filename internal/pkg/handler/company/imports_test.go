@@ -0,0 +1,273 @@
+package company
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+func TestMergeCompaniesRecords(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		wantStatus  int
+		wantTotal   int
+	}{
+		{"json array", "/v1/companies/merge-all-companies", "application/json",
+			`[{"name": "tola sales group", "zipCode": "78229", "website": "http://repsources.com"}, {"name": "maple supply", "zipCode": "K1A 0B1", "country": "CA"}]`,
+			http.StatusOK, 2},
+		{"ndjson by content type", "/v1/companies/merge-all-companies", "application/x-ndjson",
+			"{\"name\": \"tola sales group\", \"zipCode\": \"78229\"}\n{\"name\": \"maple supply\", \"zipCode\": \"K1A 0B1\"}\n",
+			http.StatusOK, 2},
+		{"ndjson by format", "/v1/companies/merge-all-companies?format=ndjson", "text/plain",
+			"{\"name\": \"tola sales group\", \"zipCode\": \"78229\"}\n",
+			http.StatusOK, 1},
+		{"csv body", "/v1/companies/merge-all-companies", "text/csv",
+			"name;addresszip;website\ntola sales group;78229;http://repsources.com",
+			http.StatusOK, 1},
+		{"unknown format", "/v1/companies/merge-all-companies?format=xml", "application/xml", "<companies/>", http.StatusBadRequest, 0},
+		{"not valid json", "/v1/companies/merge-all-companies", "application/json", `{"name": "tola sales group"}`, http.StatusBadRequest, 0},
+		{"empty json", "/v1/companies/merge-all-companies", "application/json", `[]`, http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotCompanies []*entity.Companies
+			mockService := &MockCompanyService{
+				MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+					gotCompanies = companies
+					return &entity.MergeReport{Total: len(companies)}
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, test.url, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", test.contentType)
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.MergeCompanies(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+			if len(gotCompanies) != test.wantTotal {
+				t.Errorf("got %v companies want %v", len(gotCompanies), test.wantTotal)
+			}
+			if test.wantTotal > 0 && (gotCompanies[0].Name != "TOLA SALES GROUP" || gotCompanies[0].Zip != "78229") {
+				t.Errorf("got company %v", gotCompanies[0])
+			}
+		})
+	}
+
+	t.Run("country of a json record", func(t *testing.T) {
+		var gotCompanies []*entity.Companies
+		mockService := &MockCompanyService{
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				gotCompanies = companies
+				return &entity.MergeReport{}
+			},
+		}
+		body := `[{"name": "maple supply", "zipCode": "K1A 0B1", "country": "CA", "externalId": "C-1", "attributes": {"phone": "613-555-0100"}}]`
+		request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(httptest.NewRecorder(), request)
+
+		if len(gotCompanies) != 1 || gotCompanies[0].Country != "CA" || gotCompanies[0].ExternalID != "C-1" || gotCompanies[0].Attributes["phone"] != "613-555-0100" {
+			t.Errorf("got companies %v", gotCompanies)
+		}
+	})
+}
+
+// newSpreadsheet zips a workbook with a single sheet named Clients
+func newSpreadsheet(sheetData string) []byte {
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Clients" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships><Relationship Id="rId1" Target="worksheets/sheet1.xml"/></Relationships>`,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData>` + sheetData + `</sheetData></worksheet>`,
+	}
+	for name, content := range parts {
+		part, _ := archive.Create(name)
+		part.Write([]byte(content))
+	}
+	archive.Close()
+	return buffer.Bytes()
+}
+
+func TestMergeCompaniesSpreadsheet(t *testing.T) {
+	header := `<row r="1"><c r="A1" t="inlineStr"><is><t>name</t></is></c><c r="B1" t="inlineStr"><is><t>addresszip</t></is></c><c r="C1" t="inlineStr"><is><t>website</t></is></c></row>`
+	tests := []struct {
+		name       string
+		sheet      string
+		row        string
+		wantStatus int
+	}{
+		{"values", "Clients", `<row r="2"><c r="A2" t="inlineStr"><is><t>tola sales group</t></is></c><c r="B2"><v>78229</v></c></row>`, http.StatusOK},
+		{"formula", "", `<row r="2"><c r="A2" t="inlineStr"><is><t>tola sales group</t></is></c><c r="B2"><f>B3</f><v>78229</v></c></row>`, http.StatusBadRequest},
+		{"unknown sheet", "Prospects", `<row r="2"><c r="A2" t="inlineStr"><is><t>tola sales group</t></is></c></row>`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotCompanies []*entity.Companies
+			mockService := &MockCompanyService{
+				MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+					gotCompanies = companies
+					return &entity.MergeReport{Total: len(companies)}
+				},
+			}
+
+			body := &bytes.Buffer{}
+			mpWriter := multipart.NewWriter(body)
+			ioWriter, _ := mpWriter.CreateFormFile("csv", "clients.xlsx")
+			ioWriter.Write(newSpreadsheet(header + test.row))
+			mpWriter.WriteField("sheet", test.sheet)
+			mpWriter.Close()
+			request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", bytes.NewReader(body.Bytes()))
+			request.Header.Add("Content-Type", mpWriter.FormDataContentType())
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.MergeCompanies(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d, %s", response.Code, test.wantStatus, response.Body.String())
+			}
+			if test.wantStatus == http.StatusOK && (len(gotCompanies) != 1 || gotCompanies[0].Name != "TOLA SALES GROUP" || gotCompanies[0].Zip != "78229") {
+				t.Errorf("got companies %v", gotCompanies)
+			}
+		})
+	}
+}
+
+func newUpload(fileName string, content []byte) *http.Request {
+	body := &bytes.Buffer{}
+	mpWriter := multipart.NewWriter(body)
+	ioWriter, _ := mpWriter.CreateFormFile("csv", fileName)
+	ioWriter.Write(content)
+	mpWriter.Close()
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/companies/merge-all-companies", bytes.NewReader(body.Bytes()))
+	request.Header.Add("Content-Type", mpWriter.FormDataContentType())
+	return request
+}
+
+func gzipped(content string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	writer.Write([]byte(content))
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func TestMergeCompaniesCompressed(t *testing.T) {
+	t.Run("gzip file", func(t *testing.T) {
+		var gotSource entity.Source
+		mockService := &MockCompanyService{
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				gotSource = source
+				return &entity.MergeReport{Total: len(companies)}
+			},
+		}
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(response, newUpload("clients.csv.gz", gzipped("name;addresszip;website\ntola sales group;78229;http://repsources.com")))
+
+		var report entity.MergeReport
+		json.Unmarshal(response.Body.Bytes(), &report)
+		if response.Code != http.StatusOK || report.Total != 1 || gotSource.File != "clients.csv" {
+			t.Errorf("got: %d, %v from %v", response.Code, report, gotSource.File)
+		}
+	})
+
+	t.Run("zip archive", func(t *testing.T) {
+		jobs := map[string]string{}
+		mockService := &MockCompanyService{
+			MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+				jobs[source.File] = source.ImportJob
+				return &entity.MergeReport{ImportJob: source.ImportJob, SourceFile: source.File, Total: len(companies)}
+			},
+		}
+
+		buffer := &bytes.Buffer{}
+		archive := zip.NewWriter(buffer)
+		for name, content := range map[string][]byte{
+			"january.csv":      []byte("name;addresszip;website\ntola sales group;78229;http://repsources.com"),
+			"february.json.gz": gzipped(`[{"name": "maple supply", "zipCode": "94002"}, {"name": "acme", "zipCode": "12345"}]`),
+			"march.json":       []byte(`{"name": "acme"}`),
+			"nested.zip":       []byte("PK"),
+		} {
+			part, _ := archive.Create(name)
+			part.Write(content)
+		}
+		archive.Close()
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+		companyHandler.MergeCompanies(response, newUpload("bundle.zip", buffer.Bytes()))
+
+		var report entity.ArchiveReport
+		json.Unmarshal(response.Body.Bytes(), &report)
+		if response.Code != http.StatusOK || report.SourceFile != "bundle.zip" || len(report.Files) != 4 {
+			t.Fatalf("got: %d, %v", response.Code, report)
+		}
+
+		totals := map[string]int{}
+		for _, file := range report.Files {
+			if file.Report != nil {
+				totals[file.File] = file.Report.Total
+			} else if file.Error == "" {
+				t.Errorf("got neither a report nor an error for %v", file.File)
+			}
+		}
+		if totals["january.csv"] != 1 || totals["february.json.gz"] != 2 || len(totals) != 2 {
+			t.Errorf("got totals %v", totals)
+		}
+		if len(jobs) != 2 || jobs["bundle.zip/january.csv"] == jobs["bundle.zip/february.json.gz"] {
+			t.Errorf("got import jobs %v, want one per file", jobs)
+		}
+	})
+
+	t.Run("upload too large", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+		companyHandler.MergeCompanies(response, newUpload("bundle.zip", make([]byte, MAX_UPLOAD_SIZE)))
+
+		if response.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusRequestEntityTooLarge)
+		}
+	})
+
+	t.Run("not valid archive", func(t *testing.T) {
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(&MockCompanyService{})
+		companyHandler.MergeCompanies(response, newUpload("bundle.zip", []byte("name;addresszip")))
+
+		if response.Code != http.StatusBadRequest {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusBadRequest)
+		}
+	})
+}
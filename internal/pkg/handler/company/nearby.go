@@ -0,0 +1,58 @@
+package company
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+// parseIntParam reads an optional integer query parameter, 0 when absent
+func parseIntParam(r *http.Request, name string) (int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+//GetNearbyCompanies GET /v1/companies/nearby?zip={value}&radiusMiles={value}&limit={value}&offset={value}
+func (c *CompanyHandler) GetNearbyCompanies(w http.ResponseWriter, r *http.Request) {
+	zip := r.URL.Query().Get("zip")
+	if zip == "" {
+		RespondError(w, http.StatusBadRequest, "zip is required")
+		return
+	}
+
+	radiusMiles, err := strconv.ParseFloat(r.URL.Query().Get("radiusMiles"), 64)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "radiusMiles must be a number")
+		return
+	}
+
+	limit, err := parseIntParam(r, "limit")
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "limit must be an integer")
+		return
+	}
+	offset, err := parseIntParam(r, "offset")
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "offset must be an integer")
+		return
+	}
+
+	page, err := c.service.FindNearby(r.Context(), zip, radiusMiles, limit, offset)
+	switch {
+	case errors.Is(err, companyService.ERR_ZIP_UNKNOWN):
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, companyService.ERR_NOT_VALID_RADIUS), errors.Is(err, companyService.ERR_NOT_VALID_PAGE):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, page)
+}
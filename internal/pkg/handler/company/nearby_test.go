@@ -0,0 +1,83 @@
+package company
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+func TestGetNearbyCompanies(t *testing.T) {
+	t.Run("page of companies", func(t *testing.T) {
+		mockService := &MockCompanyService{
+			FindNearbyMock: func(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error) {
+				if zip != "78229" || radiusMiles != 12.5 || limit != 20 || offset != 40 {
+					t.Errorf("got %v, %v, %v, %v", zip, radiusMiles, limit, offset)
+				}
+				return &entity.NearbyPage{Zip: zip, RadiusMiles: radiusMiles, Limit: limit, Offset: offset, Companies: []entity.NearbyCompany{}}, nil
+			},
+		}
+
+		request := httptest.NewRequest(http.MethodGet, "/v1/companies/nearby?zip=78229&radiusMiles=12.5&limit=20&offset=40", nil)
+		response := httptest.NewRecorder()
+
+		companyHandler := NewCompanyHandler()
+		companyHandler.Register(mockService)
+
+		companyHandler.GetNearbyCompanies(response, request)
+
+		if response.Code != http.StatusOK {
+			t.Errorf("got: %d, want: %d", response.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("not valid parameters", func(t *testing.T) {
+		for _, query := range []string{"radiusMiles=10", "zip=78229", "zip=78229&radiusMiles=ten", "zip=78229&radiusMiles=10&limit=all"} {
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(&MockCompanyService{})
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/companies/nearby?"+query, nil)
+			response := httptest.NewRecorder()
+
+			companyHandler.GetNearbyCompanies(response, request)
+
+			if response.Code != http.StatusBadRequest {
+				t.Errorf("%v: got: %d, want: %d", query, response.Code, http.StatusBadRequest)
+			}
+		}
+	})
+
+	t.Run("service errors", func(t *testing.T) {
+		tests := []struct {
+			err  error
+			want int
+		}{
+			{companyService.ERR_ZIP_UNKNOWN, http.StatusNotFound},
+			{companyService.ERR_NOT_VALID_RADIUS, http.StatusBadRequest},
+			{companyService.ERR_NOT_VALID_PAGE, http.StatusBadRequest},
+		}
+
+		for _, test := range tests {
+			mockService := &MockCompanyService{
+				FindNearbyMock: func(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error) {
+					return nil, test.err
+				},
+			}
+
+			request := httptest.NewRequest(http.MethodGet, "/v1/companies/nearby?zip=78229&radiusMiles=10", nil)
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+
+			companyHandler.GetNearbyCompanies(response, request)
+
+			if response.Code != test.want {
+				t.Errorf("%v: got: %d, want: %d", test.err, response.Code, test.want)
+			}
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package company
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	ingest "github.com/eduardojabes/data-integration-challenge/internal/pkg/ingest/company"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+//ValidateCompanies POST /v1/companies/validate?format={value}&match={value} multipart/form-data, application/json or application/x-ndjson
+func (c *CompanyHandler) ValidateCompanies(w http.ResponseWriter, r *http.Request) {
+	file, fileName, format, err := uploadedFile(w, r)
+	if errors.Is(err, ERR_UPLOAD_TOO_LARGE) {
+		RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	if errors.Is(err, recordsRepository.ERR_UNKNOWN_FORMAT) || errors.Is(err, recordsRepository.ERR_NOT_VALID_ARCHIVE) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	if format == recordsRepository.FORMAT_ZIP {
+		RespondError(w, http.StatusBadRequest, "archives are not validated, send their files one by one")
+		return
+	}
+
+	profile, err := c.importProfile(r)
+	if errors.Is(err, companyService.ERR_IMPORT_PROFILE_NOT_FOUND) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	// the records are only matched to the stored companies on demand, as the
+	// source given like for a merge
+	var source *entity.Source
+	if value := r.FormValue("match"); value != "" {
+		match, err := strconv.ParseBool(value)
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, "match must be a boolean")
+			return
+		}
+		if match {
+			matchSource, err := mergeSource(r, fileName, profile)
+			if err != nil {
+				RespondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			source = &matchSource
+		}
+	}
+
+	companies, err := ingest.ReadCompanies(r.Context(), file, format, ingest.Options{Sheet: r.FormValue("sheet"), Profile: profile})
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := c.service.ValidateCompanies(r.Context(), companies, source)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	report.SourceFile = fileName
+	RespondJSON(w, http.StatusOK, report)
+}
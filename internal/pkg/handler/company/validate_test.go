@@ -0,0 +1,72 @@
+package company
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+func TestValidateCompanies(t *testing.T) {
+	const body = "name;addresszip;website\ntola sales group;78229;http://repsources.com\n"
+
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		err        error
+		wantStatus int
+		wantSource string
+	}{
+		{"without matching", "/v1/companies/validate", body, nil, http.StatusOK, ""},
+		{"matching as a client file", "/v1/companies/validate?match=true", body, nil, http.StatusOK, companyService.SOURCE_CLIENT},
+		{"source sent by the client", "/v1/companies/validate?match=true&source=catalog", body, nil, http.StatusBadRequest, ""},
+		{"not a boolean", "/v1/companies/validate?match=maybe", body, nil, http.StatusBadRequest, ""},
+		{"empty file", "/v1/companies/validate", "", nil, http.StatusBadRequest, ""},
+		{"error in database", "/v1/companies/validate?match=true", body, errors.New("connection refused"), http.StatusInternalServerError, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotSource *entity.Source
+			mockService := &MockCompanyService{
+				ValidateCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source *entity.Source) (*entity.ValidationReport, error) {
+					gotSource = source
+					return &entity.ValidationReport{Total: len(companies), Valid: len(companies)}, test.err
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, test.url, strings.NewReader(test.body))
+			request.Header.Set("Content-Type", "text/csv")
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.ValidateCompanies(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Fatalf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+			if test.wantStatus != http.StatusOK {
+				return
+			}
+
+			var report entity.ValidationReport
+			json.NewDecoder(response.Body).Decode(&report)
+			if report.Total != 1 {
+				t.Errorf("got %+v want one record", report)
+			}
+			if test.wantSource == "" && gotSource != nil {
+				t.Errorf("got %+v want the records not matched", gotSource)
+			}
+			if test.wantSource != "" && (gotSource == nil || gotSource.System != test.wantSource) {
+				t.Errorf("got %+v want matched as %v", gotSource, test.wantSource)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package company
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// readWebhook decodes the subscription sent in the body of a request
+func readWebhook(w http.ResponseWriter, r *http.Request) (*entity.WebhookSubscription, bool) {
+	var subscription entity.WebhookSubscription
+	if err := json.NewDecoder(io.LimitReader(r.Body, 128*1024)).Decode(&subscription); err != nil {
+		RespondError(w, http.StatusUnprocessableEntity, err.Error())
+		return nil, false
+	}
+	return &subscription, true
+}
+
+//GetWebhooks GET /v1/webhooks application/json
+func (c *CompanyHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := c.service.ListWebhooks(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, subscriptions)
+}
+
+//GetWebhook GET /v1/webhooks/{id} application/json
+func (c *CompanyHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	subscription, err := c.service.GetWebhook(r.Context(), id)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if subscription == nil {
+		RespondError(w, http.StatusNotFound, "webhook not found")
+		return
+	}
+	RespondJSON(w, http.StatusOK, subscription)
+}
+
+//PostWebhook POST /v1/webhooks application/json
+func (c *CompanyHandler) PostWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, ok := readWebhook(w, r)
+	if !ok {
+		return
+	}
+
+	err := c.service.CreateWebhook(r.Context(), subscription)
+	if errors.Is(err, companyService.ERR_NOT_VALID_WEBHOOK) {
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusCreated, subscription)
+}
+
+//PutWebhook PUT /v1/webhooks/{id} application/json
+func (c *CompanyHandler) PutWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	subscription, ok := readWebhook(w, r)
+	if !ok {
+		return
+	}
+	subscription.ID = id
+
+	err = c.service.UpdateWebhook(r.Context(), subscription)
+	switch {
+	case errors.Is(err, companyService.ERR_NOT_VALID_WEBHOOK):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, companyService.ERR_WEBHOOK_NOT_FOUND):
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, subscription)
+}
+
+//DeleteWebhook DELETE /v1/webhooks/{id}
+func (c *CompanyHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	err = c.service.DeleteWebhook(r.Context(), id)
+	if errors.Is(err, companyService.ERR_WEBHOOK_NOT_FOUND) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+//GetWebhookDeliveries GET /v1/webhooks/{id}/deliveries?status={value} application/json
+func (c *CompanyHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid webhook ID")
+		return
+	}
+
+	deliveries, err := c.service.ListWebhookDeliveries(r.Context(), id, r.URL.Query().Get("status"))
+	switch {
+	case errors.Is(err, companyService.ERR_NOT_VALID_DELIVERY_STATUS):
+		RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, companyService.ERR_WEBHOOK_NOT_FOUND):
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	RespondJSON(w, http.StatusOK, deliveries)
+}
+
+//RetryWebhookDelivery POST /v1/webhooks/deliveries/{id}/retry
+func (c *CompanyHandler) RetryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, "invalid delivery ID")
+		return
+	}
+
+	err = c.service.RetryWebhookDelivery(r.Context(), id)
+	if errors.Is(err, companyService.ERR_WEBHOOK_DELIVERY_NOT_DEAD) {
+		RespondError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
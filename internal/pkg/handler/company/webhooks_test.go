@@ -0,0 +1,170 @@
+package company
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestPostWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"created", `{"url": "https://crm.example.com/hooks", "events": ["merged"]}`, nil, http.StatusCreated},
+		{"not valid", `{"url": "https://crm.example.com/hooks", "events": ["renamed"]}`, companyService.ERR_NOT_VALID_WEBHOOK, http.StatusBadRequest},
+		{"not json", `url`, nil, http.StatusUnprocessableEntity},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockCompanyService{
+				CreateWebhookMock: func(ctx context.Context, subscription *entity.WebhookSubscription) error {
+					subscription.Secret = "secret"
+					return test.err
+				},
+			}
+			request := httptest.NewRequest(http.MethodPost, "/v1/webhooks", bytes.NewBufferString(test.body))
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.PostWebhook(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+			if test.wantStatus == http.StatusCreated {
+				var got entity.WebhookSubscription
+				json.NewDecoder(response.Body).Decode(&got)
+				if got.Secret != "secret" || got.Events[0] != entity.EVENT_MERGED {
+					t.Errorf("got %+v want the secret returned", got)
+				}
+			}
+		})
+	}
+}
+
+func TestPutWebhook(t *testing.T) {
+	id := uuid.New()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"updated", nil, http.StatusOK},
+		{"not found", companyService.ERR_WEBHOOK_NOT_FOUND, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockCompanyService{
+				UpdateWebhookMock: func(ctx context.Context, subscription *entity.WebhookSubscription) error {
+					if subscription.ID != id {
+						t.Errorf("got %v want %v", subscription.ID, id)
+					}
+					return test.err
+				},
+			}
+			request := httptest.NewRequest(http.MethodPut, "/v1/webhooks/"+id.String(), bytes.NewBufferString(`{"url": "https://crm.example.com/hooks", "events": ["updated"]}`))
+			request = mux.SetURLVars(request, map[string]string{"id": id.String()})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.PutWebhook(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestDeleteWebhook(t *testing.T) {
+	mockService := &MockCompanyService{
+		DeleteWebhookMock: func(ctx context.Context, id uuid.UUID) error {
+			return companyService.ERR_WEBHOOK_NOT_FOUND
+		},
+	}
+	id := uuid.New().String()
+	request := httptest.NewRequest(http.MethodDelete, "/v1/webhooks/"+id, nil)
+	request = mux.SetURLVars(request, map[string]string{"id": id})
+	response := httptest.NewRecorder()
+
+	companyHandler := NewCompanyHandler()
+	companyHandler.Register(mockService)
+	companyHandler.DeleteWebhook(response, request)
+
+	if response.Code != http.StatusNotFound {
+		t.Errorf("got: %d, want: %d", response.Code, http.StatusNotFound)
+	}
+}
+
+func TestGetWebhookDeliveries(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"dead letters", nil, http.StatusOK},
+		{"unknown status", companyService.ERR_NOT_VALID_DELIVERY_STATUS, http.StatusBadRequest},
+		{"not found", companyService.ERR_WEBHOOK_NOT_FOUND, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockService := &MockCompanyService{
+				ListWebhookDeliveriesMock: func(ctx context.Context, id uuid.UUID, status string) ([]entity.WebhookDelivery, error) {
+					if status != entity.DELIVERY_DEAD {
+						t.Errorf("got %v want %v", status, entity.DELIVERY_DEAD)
+					}
+					return []entity.WebhookDelivery{}, test.err
+				},
+			}
+			id := uuid.New().String()
+			request := httptest.NewRequest(http.MethodGet, "/v1/webhooks/"+id+"/deliveries?status=dead", nil)
+			request = mux.SetURLVars(request, map[string]string{"id": id})
+			response := httptest.NewRecorder()
+
+			companyHandler := NewCompanyHandler()
+			companyHandler.Register(mockService)
+			companyHandler.GetWebhookDeliveries(response, request)
+
+			if response.Code != test.wantStatus {
+				t.Errorf("got: %d, want: %d", response.Code, test.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	mockService := &MockCompanyService{
+		RetryWebhookDeliveryMock: func(ctx context.Context, id uuid.UUID) error {
+			return nil
+		},
+	}
+	id := uuid.New().String()
+	request := httptest.NewRequest(http.MethodPost, "/v1/webhooks/deliveries/"+id+"/retry", nil)
+	request = mux.SetURLVars(request, map[string]string{"id": id})
+	response := httptest.NewRecorder()
+
+	companyHandler := NewCompanyHandler()
+	companyHandler.Register(mockService)
+	companyHandler.RetryWebhookDelivery(response, request)
+
+	if response.Code != http.StatusAccepted {
+		t.Errorf("got: %d, want: %d", response.Code, http.StatusAccepted)
+	}
+}
@@ -0,0 +1,85 @@
+package company
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	csvRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/csv"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+var ERR_EMPTY_FILE = errors.New("Error: the file has no records")
+
+// Options tell how the records of a file are read. Format is detected from
+// the name of the file when empty, Sheet picks the sheet of a workbook and
+// Profile, when set, gives the delimiter and the columns.
+type Options struct {
+	Format  string
+	Sheet   string
+	Profile *entity.ImportProfile
+}
+
+// ReadRecords reads the rows of a file, its columns separated by the
+// delimiter of the profile when it is a CSV file
+func ReadRecords(file io.Reader, format string, options Options) ([][]string, error) {
+	readerOptions := recordsRepository.ReaderOptions{Comma: ';', Sheet: options.Sheet}
+	if options.Profile != nil {
+		readerOptions.Comma, _ = utf8.DecodeRuneInString(options.Profile.Delimiter)
+	}
+
+	reader, err := recordsRepository.NewRecordReader(format, readerOptions)
+	if err != nil {
+		return nil, err
+	}
+	return reader.ReadRecords(bufio.NewReader(file))
+}
+
+// CompaniesFromRecords maps the rows following the profile, or the default
+// CSV layout without one
+func CompaniesFromRecords(ctx context.Context, data [][]string, profile *entity.ImportProfile) ([]*entity.Companies, error) {
+	if profile != nil {
+		return companyService.MapImportRows(profile, data)
+	}
+	return csvRepository.CreateCompanyEntityByCSV(ctx, data), nil
+}
+
+// ReadCompanies reads the companies of a file in the given format,
+// ERR_EMPTY_FILE when it has no records
+func ReadCompanies(ctx context.Context, file io.Reader, format string, options Options) ([]*entity.Companies, error) {
+	data, err := ReadRecords(file, format, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, ERR_EMPTY_FILE
+	}
+	return CompaniesFromRecords(ctx, data, options.Profile)
+}
+
+// ReadFile reads the companies of a file on disk, gzipped or not. Archives
+// are not read, their files have to be read one by one.
+func ReadFile(ctx context.Context, path string, options Options) ([]*entity.Companies, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	content, fileName, err := recordsRepository.Decompress(file, filepath.Base(path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	format := options.Format
+	if format == "" {
+		format = recordsRepository.DetectFormat("", fileName)
+	}
+	return ReadCompanies(ctx, content, format, options)
+}
@@ -0,0 +1,66 @@
+package company
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+)
+
+func TestReadCompanies(t *testing.T) {
+	t.Run("default layout", func(t *testing.T) {
+		companies, err := ReadCompanies(context.Background(), strings.NewReader("name;addresszip;website\ntola sales group;78229;http://repsources.com\n"), recordsRepository.FORMAT_CSV, Options{})
+
+		if err != nil || len(companies) != 1 || companies[0].Name != "TOLA SALES GROUP" {
+			t.Errorf("got %v, %v", companies, err)
+		}
+	})
+
+	t.Run("with a profile", func(t *testing.T) {
+		profile := &entity.ImportProfile{Name: "partner", Delimiter: "|", Columns: map[string]string{"Company": entity.IMPORT_FIELD_NAME, "Postal": entity.IMPORT_FIELD_ZIP}}
+
+		companies, err := ReadCompanies(context.Background(), strings.NewReader("Company|Postal\ntola sales group|78229\n"), recordsRepository.FORMAT_CSV, Options{Profile: profile})
+
+		if err != nil || len(companies) != 1 || companies[0].Zip != "78229" {
+			t.Errorf("got %v, %v", companies, err)
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		if _, err := ReadCompanies(context.Background(), strings.NewReader(""), recordsRepository.FORMAT_CSV, Options{}); !errors.Is(err, ERR_EMPTY_FILE) {
+			t.Errorf("got %v error want %v", err, ERR_EMPTY_FILE)
+		}
+	})
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	writer.Write([]byte(`{"name": "tola sales group", "zipCode": "78229"}` + "\n"))
+	writer.Close()
+	os.WriteFile(filepath.Join(dir, "partner.ndjson.gz"), compressed.Bytes(), 0o644)
+	os.WriteFile(filepath.Join(dir, "partner.zip"), []byte("PK"), 0o644)
+
+	t.Run("gzipped file in the format of its name", func(t *testing.T) {
+		companies, err := ReadFile(context.Background(), filepath.Join(dir, "partner.ndjson.gz"), Options{})
+
+		if err != nil || len(companies) != 1 || companies[0].Zip != "78229" {
+			t.Errorf("got %v, %v", companies, err)
+		}
+	})
+
+	t.Run("archive", func(t *testing.T) {
+		if _, err := ReadFile(context.Background(), filepath.Join(dir, "partner.zip"), Options{}); !errors.Is(err, recordsRepository.ERR_UNKNOWN_FORMAT) {
+			t.Errorf("got %v error want %v", err, recordsRepository.ERR_UNKNOWN_FORMAT)
+		}
+	})
+}
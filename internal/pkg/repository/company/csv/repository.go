@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
 )
 
 type CompanyCSVRepository struct{}
@@ -16,6 +17,26 @@ func NewCompanyCSVRepository() *CompanyCSVRepository {
 	return &CompanyCSVRepository{}
 }
 
+var headerSeparators = strings.NewReplacer(" ", "", "_", "", "-", "")
+
+// isExternalIDHeader tells whether a column holds the ID the source system
+// gives to the company, e.g. "External ID" or "external_id"
+func isExternalIDHeader(header string) bool {
+	header = headerSeparators.Replace(strings.ToLower(header))
+	return header == "externalid"
+}
+
+// isCountryHeader tells whether a column holds the country of the postal
+// code, e.g. "Country" or "address_country"
+func isCountryHeader(header string) bool {
+	header = headerSeparators.Replace(strings.ToLower(header))
+	return header == "country" || header == "addresscountry"
+}
+
+// CreateCompanyEntityByCSV reads name, zip and website from the first three
+// columns. A further column named "External ID" holds the ID of the company in
+// the source system and one named "Country" the country of its postal code;
+// any other becomes an attribute named after its header.
 func CreateCompanyEntityByCSV(ctx context.Context, fileData [][]string) []*entity.Companies {
 	var companyData []*entity.Companies
 
@@ -31,6 +52,22 @@ func CreateCompanyEntityByCSV(ctx context.Context, fileData [][]string) []*entit
 					lineRead.Zip = field
 				case 2:
 					lineRead.Website = field
+				default:
+					if j >= len(fileData[0]) || strings.TrimSpace(field) == "" {
+						continue
+					}
+					if isExternalIDHeader(fileData[0][j]) {
+						lineRead.ExternalID = strings.TrimSpace(field)
+						continue
+					}
+					if isCountryHeader(fileData[0][j]) {
+						lineRead.Country = strings.TrimSpace(field)
+						continue
+					}
+					if lineRead.Attributes == nil {
+						lineRead.Attributes = map[string]string{}
+					}
+					lineRead.Attributes[strings.TrimSpace(fileData[0][j])] = strings.TrimSpace(field)
 				}
 			}
 
@@ -51,16 +88,25 @@ func (ccCSV *CompanyCSVRepository) Read_File(f io.Reader) ([][]string, error) {
 	return data, nil
 }
 
+// GetCompany reads the companies of the CSV file at key, which may be gzipped
 func (ccCSV *CompanyCSVRepository) GetCompany(ctx context.Context, key string) ([]*entity.Companies, error) {
 	file, err := os.Open(key)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := ccCSV.Read_File(file)
-
 	defer file.Close()
 
+	content, _, err := recordsRepository.Decompress(file, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ccCSV.Read_File(content)
+	if err != nil {
+		return nil, err
+	}
+
 	companyData := CreateCompanyEntityByCSV(ctx, data)
 
 	return companyData, nil
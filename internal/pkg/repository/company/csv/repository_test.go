@@ -2,6 +2,7 @@ package company
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
 	"io/ioutil"
 	"os"
@@ -77,4 +78,73 @@ func TestGetCompany(t *testing.T) {
 		os.Remove(fileName)
 	})
 
+	t.Run("open a gzipped archive", func(t *testing.T) {
+		file, _ := ioutil.TempFile("./", "test_file_*.csv.gz")
+		writer := gzip.NewWriter(file)
+		writer.Write([]byte("name;addresszip;website\ntola sales group;78229;http://repsources.com"))
+		writer.Close()
+		file.Close()
+
+		got, err := repository.GetCompany(context.Background(), file.Name())
+
+		if err != nil || len(got) != 1 || got[0].Name != "TOLA SALES GROUP" {
+			t.Errorf("got %v, %v", got, err)
+		}
+		os.Remove(file.Name())
+	})
+}
+
+func TestCreateCompanyEntityByCSV(t *testing.T) {
+	t.Run("extra columns as attributes", func(t *testing.T) {
+		data := [][]string{
+			{"name", "addresszip", "website", "Phone", "Industry Code"},
+			{"tola sales group", "78229", "http://repsources.com", "210-555-0100", ""},
+		}
+
+		got := CreateCompanyEntityByCSV(context.Background(), data)
+
+		want := map[string]string{"Phone": "210-555-0100"}
+		if len(got) != 1 || !reflect.DeepEqual(got[0].Attributes, want) {
+			t.Errorf("got %v, but it should be %v", got[0].Attributes, want)
+		}
+	})
+
+	t.Run("external ID column", func(t *testing.T) {
+		data := [][]string{
+			{"name", "addresszip", "website", "External ID"},
+			{"tola sales group", "78229", "http://repsources.com", " C-1001 "},
+		}
+
+		got := CreateCompanyEntityByCSV(context.Background(), data)
+
+		if got[0].ExternalID != "C-1001" || got[0].Attributes != nil {
+			t.Errorf("got %v, %v, but it should be C-1001 and no attributes", got[0].ExternalID, got[0].Attributes)
+		}
+	})
+
+	t.Run("country column", func(t *testing.T) {
+		data := [][]string{
+			{"name", "addresszip", "website", "Address Country"},
+			{"maple supply", "K1A 0B1", "https://maple.ca", " CA "},
+		}
+
+		got := CreateCompanyEntityByCSV(context.Background(), data)
+
+		if got[0].Country != "CA" || got[0].Attributes != nil {
+			t.Errorf("got %v, %v, but it should be CA and no attributes", got[0].Country, got[0].Attributes)
+		}
+	})
+
+	t.Run("core columns only", func(t *testing.T) {
+		data := [][]string{
+			{"name", "addresszip", "website"},
+			{"tola sales group", "78229", "http://repsources.com"},
+		}
+
+		got := CreateCompanyEntityByCSV(context.Background(), data)
+
+		if got[0].Attributes != nil {
+			t.Errorf("got %v, but it should be nil", got[0].Attributes)
+		}
+	})
 }
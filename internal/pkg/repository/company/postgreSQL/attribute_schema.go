@@ -0,0 +1,48 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type AttributeDefinitionModel struct {
+	Name        string `db:"as_name"`
+	Type        string `db:"as_type"`
+	Pattern     string `db:"as_pattern"`
+	Description string `db:"as_description"`
+	MergePolicy string `db:"as_merge_policy"`
+}
+
+func (r *PostgreCompanyRepository) ListAttributeDefinitions(ctx context.Context) ([]*entity.AttributeDefinition, error) {
+	var definitionModel []*AttributeDefinitionModel
+	definitions := []*entity.AttributeDefinition{}
+
+	err := pgxscan.Select(ctx, r.conn, &definitionModel, `SELECT * FROM company_attribute_schema ORDER BY as_name`)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range definitionModel {
+		definitions = append(definitions, &entity.AttributeDefinition{
+			Name:        model.Name,
+			Type:        model.Type,
+			Pattern:     model.Pattern,
+			Description: model.Description,
+			MergePolicy: model.MergePolicy,
+		})
+	}
+	return definitions, nil
+}
+
+func (r *PostgreCompanyRepository) SaveAttributeDefinition(ctx context.Context, definition entity.AttributeDefinition) error {
+	_, err := r.conn.Exec(ctx, `INSERT INTO company_attribute_schema(as_name, as_type, as_pattern, as_description, as_merge_policy) values($1, $2, $3, $4, $5)
+		ON CONFLICT (as_name) DO UPDATE SET as_type = EXCLUDED.as_type, as_pattern = EXCLUDED.as_pattern, as_description = EXCLUDED.as_description, as_merge_policy = EXCLUDED.as_merge_policy`,
+		definition.Name, definition.Type, definition.Pattern, definition.Description, definition.MergePolicy)
+	if err != nil {
+		return err
+	}
+	return nil
+}
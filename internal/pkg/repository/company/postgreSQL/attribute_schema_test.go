@@ -0,0 +1,64 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestListAttributeDefinitions(t *testing.T) {
+	t.Run("with_definitions", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		want := &entity.AttributeDefinition{Name: "phone", Type: entity.ATTRIBUTE_TYPE_STRING, Pattern: "^[0-9]+$"}
+
+		mock.ExpectQuery("SELECT (.+) FROM company_attribute_schema").
+			WillReturnRows(mock.NewRows([]string{"as_name", "as_type", "as_pattern", "as_description", "as_merge_policy"}).
+				AddRow(want.Name, want.Type, want.Pattern, want.Description, want.MergePolicy))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListAttributeDefinitions(context.Background())
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(want, got[0]) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		mock.ExpectQuery("SELECT (.+) FROM company_attribute_schema").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListAttributeDefinitions(context.Background())
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestSaveAttributeDefinition(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+
+	definition := entity.AttributeDefinition{Name: "industry_code", Type: entity.ATTRIBUTE_TYPE_STRING, Pattern: "^[0-9]{2,6}$", MergePolicy: "fill-if-empty"}
+
+	mock.ExpectExec("INSERT INTO company_attribute_schema").
+		WithArgs(definition.Name, definition.Type, definition.Pattern, definition.Description, definition.MergePolicy).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	repository := NewPostgreCompanyRepository(mock)
+	err := repository.SaveAttributeDefinition(context.Background(), definition)
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+}
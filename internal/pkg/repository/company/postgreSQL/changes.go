@@ -0,0 +1,107 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
+)
+
+// CHANGE_SEQUENCER_LOCK is the advisory lock taken while sequencing the
+// change feed
+const CHANGE_SEQUENCER_LOCK = 4501
+
+type CompanyChangeModel struct {
+	ChangeID        int64     `db:"ch_id"`
+	ChangeSequence  int64     `db:"ch_sequence"`
+	ChangeCompanyID uuid.UUID `db:"ch_company_id"`
+	ChangeOperation string    `db:"ch_operation"`
+	ChangeChangedAt time.Time `db:"ch_changed_at"`
+}
+
+// readChanges returns up to limit changes after the since sequence. Changes
+// committed since the last read get their sequence first, one reader at a
+// time, so a change is never numbered below one already read.
+func (r *PostgreCompanyRepository) readChanges(ctx context.Context, since int64, limit int) ([]*CompanyChangeModel, error) {
+	var changeModel []*CompanyChangeModel
+
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, CHANGE_SEQUENCER_LOCK)
+	if err != nil {
+		return nil, fmt.Errorf("error while sequencing changes: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE company_changes c SET ch_sequence = pending.sequence
+		FROM (SELECT ch_id, nextval('company_changes_sequence') AS sequence FROM (SELECT ch_id FROM company_changes WHERE ch_sequence IS NULL ORDER BY ch_id) ordered) pending
+		WHERE c.ch_id = pending.ch_id`)
+	if err != nil {
+		return nil, fmt.Errorf("error while sequencing changes: %w", err)
+	}
+
+	err = pgxscan.Select(ctx, tx, &changeModel, `SELECT * FROM company_changes WHERE ch_sequence > $1 ORDER BY ch_sequence LIMIT $2`, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return changeModel, nil
+}
+
+// ListCompanyChanges returns up to limit changes after the since sequence,
+// with the current state of their companies
+func (r *PostgreCompanyRepository) ListCompanyChanges(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error) {
+	changes := []*entity.CompanyChange{}
+
+	changeModel, err := r.readChanges(ctx, since, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uuid.UUID{}
+	for _, model := range changeModel {
+		if model.ChangeOperation != entity.CHANGE_DELETE {
+			ids = append(ids, model.ChangeCompanyID)
+		}
+	}
+
+	companies := map[uuid.UUID]*entity.Companies{}
+	if len(ids) > 0 {
+		var companyModel []*CompanyModel
+		err = pgxscan.Select(ctx, r.conn, &companyModel, `SELECT * FROM companies_catalog_table WHERE cc_company_id = ANY($1)`, ids)
+		if err != nil {
+			return nil, fmt.Errorf("error while executing query: %w", err)
+		}
+		for _, model := range companyModel {
+			companies[model.CompanyID] = model.toEntity()
+		}
+	}
+
+	for _, model := range changeModel {
+		change := &entity.CompanyChange{
+			Sequence:  model.ChangeSequence,
+			Operation: model.ChangeOperation,
+			CompanyID: model.ChangeCompanyID,
+			ChangedAt: model.ChangeChangedAt,
+		}
+		if change.Operation != entity.CHANGE_DELETE {
+			change.Company = companies[change.CompanyID]
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
@@ -0,0 +1,91 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestListCompanyChanges(t *testing.T) {
+	updated := uuid.New()
+	deleted := uuid.New()
+	changedAt := time.Now()
+
+	t.Run("with changes", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1\)`).
+			WithArgs(CHANGE_SEQUENCER_LOCK).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec(`UPDATE company_changes c SET ch_sequence = pending.sequence`).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectQuery(`SELECT (.+) FROM company_changes WHERE ch_sequence > \$1 ORDER BY ch_sequence LIMIT \$2`).
+			WithArgs(int64(10), 50).
+			WillReturnRows(mock.NewRows([]string{"ch_id", "ch_sequence", "ch_company_id", "ch_operation", "ch_changed_at"}).
+				AddRow(int64(3), int64(11), updated, entity.CHANGE_UPDATE, changedAt).
+				AddRow(int64(4), int64(12), deleted, entity.CHANGE_DELETE, changedAt))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`SELECT (.+) FROM companies_catalog_table WHERE cc_company_id = ANY\(\$1\)`).
+			WithArgs([]uuid.UUID{updated}).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip"}).AddRow(updated, "COMPANY", "12345"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListCompanyChanges(context.Background(), 10, 50)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(got) != 2 || got[0].Sequence != 11 || got[0].Company == nil || got[0].Company.Name != "COMPANY" {
+			t.Errorf("got %+v", got)
+		}
+		if got[1].Operation != entity.CHANGE_DELETE || got[1].Company != nil || got[1].CompanyID != deleted {
+			t.Errorf("got %+v", got[1])
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("without changes", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec(`UPDATE company_changes`).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectQuery(`SELECT (.+) FROM company_changes`).
+			WillReturnRows(mock.NewRows([]string{"ch_id", "ch_sequence", "ch_company_id", "ch_operation", "ch_changed_at"}))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListCompanyChanges(context.Background(), 0, 50)
+
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %v, %v want no changes", got, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec(`UPDATE company_changes`).WillReturnError(errors.New("relation does not exist"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListCompanyChanges(context.Background(), 0, 50)
+
+		if err == nil {
+			t.Errorf("got nil error, it should be an error")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
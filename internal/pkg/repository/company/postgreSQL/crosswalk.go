@@ -0,0 +1,56 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
+)
+
+type CrosswalkModel struct {
+	SourceSystem string    `db:"cx_source_system"`
+	ExternalKey  string    `db:"cx_external_key"`
+	CompanyID    uuid.UUID `db:"cx_company_id"`
+	CreatedAt    time.Time `db:"cx_created_at"`
+	UpdatedAt    time.Time `db:"cx_updated_at"`
+}
+
+// SaveCrosswalk maps the external key of a source system to a company,
+// replacing the company a key pointed to before
+func (r *PostgreCompanyRepository) SaveCrosswalk(ctx context.Context, crosswalk entity.Crosswalk) error {
+	return saveCrosswalk(ctx, r.conn, crosswalk)
+}
+
+func saveCrosswalk(ctx context.Context, conn executor, crosswalk entity.Crosswalk) error {
+	_, err := conn.Exec(ctx, `INSERT INTO company_crosswalk(cx_source_system, cx_external_key, cx_company_id) values($1, $2, $3)
+		ON CONFLICT (cx_source_system, cx_external_key) DO UPDATE SET cx_company_id = EXCLUDED.cx_company_id, cx_updated_at = now() WHERE company_crosswalk.cx_company_id <> EXCLUDED.cx_company_id`,
+		crosswalk.SourceSystem, crosswalk.ExternalKey, crosswalk.CompanyID)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *PostgreCompanyRepository) ReadCrosswalk(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+	var crosswalkModel []*CrosswalkModel
+	err := pgxscan.Select(ctx, r.conn, &crosswalkModel, `SELECT * FROM company_crosswalk WHERE cx_source_system = $1 AND cx_external_key = $2`, sourceSystem, externalKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(crosswalkModel) == 0 {
+		return nil, nil
+	}
+
+	model := crosswalkModel[0]
+	return &entity.Crosswalk{
+		SourceSystem: model.SourceSystem,
+		ExternalKey:  model.ExternalKey,
+		CompanyID:    model.CompanyID,
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestSaveCrosswalk(t *testing.T) {
+	t.Run("Saving crosswalk", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		crosswalk := entity.Crosswalk{SourceSystem: "client-csv", ExternalKey: "C-1001", CompanyID: uuid.New()}
+
+		mock.ExpectExec("INSERT INTO company_crosswalk").
+			WithArgs(crosswalk.SourceSystem, crosswalk.ExternalKey, crosswalk.CompanyID).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveCrosswalk(context.Background(), crosswalk)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectExec("INSERT INTO company_crosswalk").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveCrosswalk(context.Background(), entity.Crosswalk{})
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestReadCrosswalk(t *testing.T) {
+	t.Run("no_rows", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM company_crosswalk WHERE (.+)").
+			WillReturnRows(mock.NewRows([]string{"cx_source_system", "cx_external_key", "cx_company_id"}))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadCrosswalk(context.Background(), "client-csv", "C-1001")
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if got != nil {
+			t.Errorf("got %v want nil", got)
+		}
+	})
+
+	t.Run("with_crosswalk", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		want := &entity.Crosswalk{SourceSystem: "client-csv", ExternalKey: "C-1001", CompanyID: uuid.New()}
+		mock.ExpectQuery("SELECT (.+) FROM company_crosswalk WHERE (.+)").
+			WithArgs(want.SourceSystem, want.ExternalKey).
+			WillReturnRows(mock.NewRows([]string{"cx_source_system", "cx_external_key", "cx_company_id"}).
+				AddRow(want.SourceSystem, want.ExternalKey, want.CompanyID))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadCrosswalk(context.Background(), want.SourceSystem, want.ExternalKey)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
+)
+
+type DuplicateCandidateModel struct {
+	CandidateID uuid.UUID  `db:"dc_candidate_id"`
+	CompanyID   uuid.UUID  `db:"dc_company_id"`
+	DuplicateID uuid.UUID  `db:"dc_duplicate_id"`
+	Score       float64    `db:"dc_score"`
+	Status      string     `db:"dc_status"`
+	DetectedAt  time.Time  `db:"dc_detected_at"`
+	ResolvedAt  *time.Time `db:"dc_resolved_at"`
+}
+
+func (m *DuplicateCandidateModel) toEntity() *entity.DuplicateCandidate {
+	return &entity.DuplicateCandidate{
+		ID:          m.CandidateID,
+		CompanyID:   m.CompanyID,
+		DuplicateID: m.DuplicateID,
+		Score:       m.Score,
+		Status:      m.Status,
+		DetectedAt:  m.DetectedAt,
+		ResolvedAt:  m.ResolvedAt,
+	}
+}
+
+// SaveDuplicateCandidates stores newly detected pairs, returning the pending
+// candidates as stored. Pairs already known keep their ID and pending ones get
+// the new score; resolved ones are left as they are and out of the result
+func (r *PostgreCompanyRepository) SaveDuplicateCandidates(ctx context.Context, candidates []entity.DuplicateCandidate) ([]entity.DuplicateCandidate, error) {
+	saved := []entity.DuplicateCandidate{}
+	for _, candidate := range candidates {
+		var candidateModel []*DuplicateCandidateModel
+		err := pgxscan.Select(ctx, r.conn, &candidateModel, `INSERT INTO duplicate_candidates(dc_candidate_id, dc_company_id, dc_duplicate_id, dc_score, dc_status) values($1, $2, $3, $4, $5)
+			ON CONFLICT (dc_company_id, dc_duplicate_id) DO UPDATE SET dc_score = EXCLUDED.dc_score, dc_detected_at = now() WHERE duplicate_candidates.dc_status = $5 RETURNING *`,
+			candidate.ID, candidate.CompanyID, candidate.DuplicateID, candidate.Score, entity.DUPLICATE_STATUS_PENDING)
+		if err != nil {
+			return nil, err
+		}
+		for _, model := range candidateModel {
+			saved = append(saved, *model.toEntity())
+		}
+	}
+	return saved, nil
+}
+
+// ListDuplicateCandidates returns the candidates with the given status, or all
+// of them when status is empty, the most likely duplicates first. Pending
+// candidates one of whose companies was deleted since are left out
+func (r *PostgreCompanyRepository) ListDuplicateCandidates(ctx context.Context, status string) ([]*entity.DuplicateCandidate, error) {
+	var candidateModel []*DuplicateCandidateModel
+	candidates := []*entity.DuplicateCandidate{}
+
+	query := `SELECT d.* FROM duplicate_candidates d WHERE (d.dc_status <> $1 OR NOT EXISTS (SELECT 1 FROM companies_catalog_table c WHERE c.cc_company_id IN (d.dc_company_id, d.dc_duplicate_id) AND c.cc_deleted_at IS NOT NULL))`
+	args := []interface{}{entity.DUPLICATE_STATUS_PENDING}
+	if status != "" {
+		query += ` AND d.dc_status = $2`
+		args = append(args, status)
+	}
+	query += ` ORDER BY d.dc_score DESC, d.dc_detected_at`
+
+	err := pgxscan.Select(ctx, r.conn, &candidateModel, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range candidateModel {
+		candidates = append(candidates, model.toEntity())
+	}
+	return candidates, nil
+}
+
+func (r *PostgreCompanyRepository) ReadDuplicateCandidate(ctx context.Context, id uuid.UUID) (*entity.DuplicateCandidate, error) {
+	var candidateModel []*DuplicateCandidateModel
+	err := pgxscan.Select(ctx, r.conn, &candidateModel, `SELECT * FROM duplicate_candidates WHERE dc_candidate_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(candidateModel) == 0 {
+		return nil, nil
+	}
+	return candidateModel[0].toEntity(), nil
+}
+
+// ResolveDuplicateCandidate sets the final status of a pending candidate,
+// reporting whether it was still pending
+func (r *PostgreCompanyRepository) ResolveDuplicateCandidate(ctx context.Context, id uuid.UUID, status string) (bool, error) {
+	tag, err := r.conn.Exec(ctx, `UPDATE duplicate_candidates SET dc_status = $2, dc_resolved_at = now() WHERE dc_candidate_id = $1 AND dc_status = $3`, id, status, entity.DUPLICATE_STATUS_PENDING)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
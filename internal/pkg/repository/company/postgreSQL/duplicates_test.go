@@ -0,0 +1,140 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestSaveDuplicateCandidates(t *testing.T) {
+	columns := []string{"dc_candidate_id", "dc_company_id", "dc_duplicate_id", "dc_score", "dc_status"}
+
+	t.Run("Saving candidates", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		candidate := entity.DuplicateCandidate{
+			ID:          uuid.New(),
+			CompanyID:   uuid.New(),
+			DuplicateID: uuid.New(),
+			Score:       0.93,
+		}
+		resolved := entity.DuplicateCandidate{ID: uuid.New(), CompanyID: uuid.New(), DuplicateID: uuid.New(), Score: 0.9}
+		storedID := uuid.New()
+
+		mock.ExpectQuery(`INSERT INTO duplicate_candidates(.+) WHERE duplicate_candidates.dc_status = \$5 RETURNING \*`).
+			WithArgs(candidate.ID, candidate.CompanyID, candidate.DuplicateID, candidate.Score, entity.DUPLICATE_STATUS_PENDING).
+			WillReturnRows(mock.NewRows(columns).AddRow(storedID, candidate.CompanyID, candidate.DuplicateID, candidate.Score, entity.DUPLICATE_STATUS_PENDING))
+		mock.ExpectQuery("INSERT INTO duplicate_candidates").
+			WithArgs(resolved.ID, resolved.CompanyID, resolved.DuplicateID, resolved.Score, entity.DUPLICATE_STATUS_PENDING).
+			WillReturnRows(mock.NewRows(columns))
+
+		repository := NewPostgreCompanyRepository(mock)
+		saved, err := repository.SaveDuplicateCandidates(context.Background(), []entity.DuplicateCandidate{candidate, resolved})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(saved) != 1 || saved[0].ID != storedID {
+			t.Errorf("got %v want the pending candidate with its stored ID", saved)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		mock.ExpectQuery("INSERT INTO duplicate_candidates").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.SaveDuplicateCandidates(context.Background(), []entity.DuplicateCandidate{{ID: uuid.New()}})
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestListDuplicateCandidates(t *testing.T) {
+	want := &entity.DuplicateCandidate{
+		ID:          uuid.New(),
+		CompanyID:   uuid.New(),
+		DuplicateID: uuid.New(),
+		Score:       0.93,
+		Status:      entity.DUPLICATE_STATUS_PENDING,
+	}
+
+	t.Run("by status", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM duplicate_candidates d WHERE \(d.dc_status <> \$1 OR NOT EXISTS (.+) c.cc_deleted_at IS NOT NULL\)\) AND d.dc_status = \$2`).
+			WithArgs(entity.DUPLICATE_STATUS_PENDING, entity.DUPLICATE_STATUS_PENDING).
+			WillReturnRows(mock.NewRows([]string{"dc_candidate_id", "dc_company_id", "dc_duplicate_id", "dc_score", "dc_status"}).
+				AddRow(want.ID, want.CompanyID, want.DuplicateID, want.Score, want.Status))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListDuplicateCandidates(context.Background(), entity.DUPLICATE_STATUS_PENDING)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(want, got[0]) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM duplicate_candidates").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListDuplicateCandidates(context.Background(), "")
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestResolveDuplicateCandidate(t *testing.T) {
+	t.Run("pending candidate", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		id := uuid.New()
+		mock.ExpectExec("UPDATE duplicate_candidates SET dc_status").
+			WithArgs(id, entity.DUPLICATE_STATUS_REJECTED, entity.DUPLICATE_STATUS_PENDING).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		resolved, err := repository.ResolveDuplicateCandidate(context.Background(), id, entity.DUPLICATE_STATUS_REJECTED)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !resolved {
+			t.Errorf("got %v want true", resolved)
+		}
+	})
+
+	t.Run("already resolved", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectExec("UPDATE duplicate_candidates SET dc_status").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		repository := NewPostgreCompanyRepository(mock)
+		resolved, err := repository.ResolveDuplicateCandidate(context.Background(), uuid.New(), entity.DUPLICATE_STATUS_CONFIRMED)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if resolved {
+			t.Errorf("got %v want false", resolved)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+// EXPORT_BATCH_SIZE is the number of rows fetched from the cursor at once
+const EXPORT_BATCH_SIZE = 500
+
+// StreamCompanies hands the companies matching the filter to each, one at a
+// time, reading them through a cursor so the catalog is never held in memory.
+// The cursor lives on a connection of its own, taken from the pool for the
+// length of the export, so a slow client holds up no other statement
+func (r *PostgreCompanyRepository) StreamCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+	session, release, err := r.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("error while opening the cursor: %w", err)
+	}
+	defer release()
+
+	tx, err := session.conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error while opening the cursor: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query, args := companyFilterQuery(filter)
+	_, err = tx.Exec(ctx, `DECLARE export_companies NO SCROLL CURSOR FOR `+query, args...)
+	if err != nil {
+		return fmt.Errorf("error while opening the cursor: %w", err)
+	}
+
+	for {
+		var companyModel []*CompanyModel
+		err = pgxscan.Select(ctx, tx, &companyModel, fmt.Sprintf(`FETCH %d FROM export_companies`, EXPORT_BATCH_SIZE))
+		if err != nil {
+			return fmt.Errorf("error while executing query: %w", err)
+		}
+
+		for _, model := range companyModel {
+			if err := each(model.toEntity()); err != nil {
+				return err
+			}
+		}
+
+		if len(companyModel) < EXPORT_BATCH_SIZE {
+			return nil
+		}
+	}
+}
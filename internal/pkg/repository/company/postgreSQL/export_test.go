@@ -0,0 +1,104 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestStreamCompanies(t *testing.T) {
+	t.Run("fetching in batches", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`DECLARE export_companies NO SCROLL CURSOR FOR SELECT (.+) FROM companies_catalog_table WHERE cc_country = \$1 AND cc_deleted_at IS NULL ORDER BY`).
+			WithArgs(entity.COUNTRY_US).
+			WillReturnResult(pgxmock.NewResult("DECLARE CURSOR", 0))
+		full := mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip"})
+		for index := 0; index < EXPORT_BATCH_SIZE; index++ {
+			full.AddRow(uuid.New(), "COMPANY", "12345")
+		}
+		mock.ExpectQuery(`FETCH 500 FROM export_companies`).WillReturnRows(full)
+		mock.ExpectQuery(`FETCH 500 FROM export_companies`).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip"}).AddRow(uuid.New(), "OTHER", "54321"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		count := 0
+		err := repository.StreamCompanies(context.Background(), entity.CompanyFilter{Country: entity.COUNTRY_US}, func(company *entity.Companies) error {
+			count++
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if count != EXPORT_BATCH_SIZE+1 {
+			t.Errorf("got %v companies want %v", count, EXPORT_BATCH_SIZE+1)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("stopping on error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`DECLARE export_companies`).
+			WillReturnResult(pgxmock.NewResult("DECLARE CURSOR", 0))
+		mock.ExpectQuery(`FETCH 500 FROM export_companies`).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip"}).AddRow(uuid.New(), "COMPANY", "12345").AddRow(uuid.New(), "OTHER", "54321"))
+		mock.ExpectRollback()
+
+		wantErr := errors.New("client went away")
+		repository := NewPostgreCompanyRepository(mock)
+		count := 0
+		err := repository.StreamCompanies(context.Background(), entity.CompanyFilter{}, func(company *entity.Companies) error {
+			count++
+			return wantErr
+		})
+
+		if !errors.Is(err, wantErr) || count != 1 {
+			t.Errorf("got %v error after %v companies want %v after 1", err, count, wantErr)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec(`DECLARE export_companies`).WillReturnError(errors.New("relation does not exist"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.StreamCompanies(context.Background(), entity.CompanyFilter{}, func(company *entity.Companies) error {
+			return nil
+		})
+
+		if err == nil {
+			t.Errorf("got nil error, it should be an error")
+		}
+	})
+
+	t.Run("on a connection of the pool", func(t *testing.T) {
+		// the connections of the mock pool cannot be acquired
+		mock, _ := pgxmock.NewPool()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.StreamCompanies(context.Background(), entity.CompanyFilter{}, func(company *entity.Companies) error {
+			return nil
+		})
+
+		if err == nil {
+			t.Errorf("got nil error want the error acquiring the connection")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type ImportProfileModel struct {
+	Name          string              `db:"ip_name"`
+	Delimiter     string              `db:"ip_delimiter"`
+	HeaderAliases map[string][]string `db:"ip_header_aliases"`
+	Columns       map[string]string   `db:"ip_columns"`
+	Transforms    map[string][]string `db:"ip_transforms"`
+	Priority      int                 `db:"ip_priority"`
+	CreatedAt     time.Time           `db:"ip_created_at"`
+	UpdatedAt     time.Time           `db:"ip_updated_at"`
+}
+
+func (m *ImportProfileModel) toEntity() *entity.ImportProfile {
+	return &entity.ImportProfile{
+		Name:          m.Name,
+		Delimiter:     m.Delimiter,
+		HeaderAliases: m.HeaderAliases,
+		Columns:       m.Columns,
+		Transforms:    m.Transforms,
+		Priority:      m.Priority,
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+// listsColumn keeps the JSONB lists of a profile from being written as NULL
+func listsColumn(lists map[string][]string) map[string][]string {
+	if lists == nil {
+		return map[string][]string{}
+	}
+	return lists
+}
+
+func (r *PostgreCompanyRepository) ListImportProfiles(ctx context.Context) ([]*entity.ImportProfile, error) {
+	var profileModel []*ImportProfileModel
+	profiles := []*entity.ImportProfile{}
+
+	err := pgxscan.Select(ctx, r.conn, &profileModel, `SELECT * FROM import_profiles ORDER BY ip_name`)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range profileModel {
+		profiles = append(profiles, model.toEntity())
+	}
+	return profiles, nil
+}
+
+func (r *PostgreCompanyRepository) ReadImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error) {
+	var profileModel []*ImportProfileModel
+	err := pgxscan.Select(ctx, r.conn, &profileModel, `SELECT * FROM import_profiles WHERE ip_name = $1`, name)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(profileModel) == 0 {
+		return nil, nil
+	}
+	return profileModel[0].toEntity(), nil
+}
+
+// SaveImportProfile creates the profile or replaces the one with the same name
+func (r *PostgreCompanyRepository) SaveImportProfile(ctx context.Context, profile entity.ImportProfile) error {
+	_, err := r.conn.Exec(ctx, `INSERT INTO import_profiles(ip_name, ip_delimiter, ip_header_aliases, ip_columns, ip_transforms, ip_priority) values($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (ip_name) DO UPDATE SET ip_delimiter = EXCLUDED.ip_delimiter, ip_header_aliases = EXCLUDED.ip_header_aliases, ip_columns = EXCLUDED.ip_columns, ip_transforms = EXCLUDED.ip_transforms, ip_priority = EXCLUDED.ip_priority, ip_updated_at = now()`,
+		profile.Name, profile.Delimiter, listsColumn(profile.HeaderAliases), attributesColumn(profile.Columns), listsColumn(profile.Transforms), profile.Priority)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteImportProfile removes the profile, telling whether it existed
+func (r *PostgreCompanyRepository) DeleteImportProfile(ctx context.Context, name string) (bool, error) {
+	tag, err := r.conn.Exec(ctx, `DELETE FROM import_profiles WHERE ip_name = $1`, name)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,107 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/pashagolub/pgxmock"
+)
+
+var profileColumns = []string{"ip_name", "ip_delimiter", "ip_header_aliases", "ip_columns", "ip_transforms", "ip_priority"}
+
+func TestListImportProfiles(t *testing.T) {
+	t.Run("with profiles", func(t *testing.T) {
+		want := &entity.ImportProfile{
+			Name:          "partner",
+			Delimiter:     ",",
+			HeaderAliases: map[string][]string{"Company": {"Company Name"}},
+			Columns:       map[string]string{"Company": entity.IMPORT_FIELD_NAME},
+			Transforms:    map[string][]string{entity.IMPORT_FIELD_NAME: {"trim"}},
+			Priority:      50,
+		}
+
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM import_profiles ORDER BY ip_name").
+			WillReturnRows(mock.NewRows(profileColumns).
+				AddRow(want.Name, want.Delimiter, want.HeaderAliases, want.Columns, want.Transforms, want.Priority))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListImportProfiles(context.Background())
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(want, got[0]) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM import_profiles").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListImportProfiles(context.Background())
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestReadImportProfile(t *testing.T) {
+	t.Run("no_rows", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM import_profiles WHERE ip_name = \\$1").
+			WithArgs("partner").
+			WillReturnRows(mock.NewRows(profileColumns))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadImportProfile(context.Background(), "partner")
+
+		if err != nil || got != nil {
+			t.Errorf("got %v, %v want nil, nil", got, err)
+		}
+	})
+}
+
+func TestSaveImportProfile(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+
+	profile := entity.ImportProfile{Name: "partner", Delimiter: ",", Columns: map[string]string{"Company": entity.IMPORT_FIELD_NAME}}
+
+	mock.ExpectExec("INSERT INTO import_profiles").
+		WithArgs(profile.Name, profile.Delimiter, map[string][]string{}, profile.Columns, map[string][]string{}, 0).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	repository := NewPostgreCompanyRepository(mock)
+	err := repository.SaveImportProfile(context.Background(), profile)
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeleteImportProfile(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec("DELETE FROM import_profiles WHERE ip_name = \\$1").
+		WithArgs("partner").
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	repository := NewPostgreCompanyRepository(mock)
+	deleted, err := repository.DeleteImportProfile(context.Background(), "partner")
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if deleted {
+		t.Errorf("got %v want false", deleted)
+	}
+}
@@ -0,0 +1,39 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type IngestedFileModel struct {
+	Checksum    string    `db:"if_checksum"`
+	FileName    string    `db:"if_file_name"`
+	ProcessedAt time.Time `db:"if_processed_at"`
+}
+
+func (r *PostgreCompanyRepository) ReadIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error) {
+	var fileModel []*IngestedFileModel
+	err := pgxscan.Select(ctx, r.conn, &fileModel, `SELECT * FROM ingested_files WHERE if_checksum = $1`, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(fileModel) == 0 {
+		return nil, nil
+	}
+	return &entity.IngestedFile{Checksum: fileModel[0].Checksum, FileName: fileModel[0].FileName, ProcessedAt: fileModel[0].ProcessedAt}, nil
+}
+
+// SaveIngestedFile records a merged file, keeping the first one recorded
+// with the same checksum
+func (r *PostgreCompanyRepository) SaveIngestedFile(ctx context.Context, file entity.IngestedFile) error {
+	_, err := r.conn.Exec(ctx, `INSERT INTO ingested_files(if_checksum, if_file_name, if_processed_at) values($1, $2, $3) ON CONFLICT (if_checksum) DO NOTHING`, file.Checksum, file.FileName, file.ProcessedAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package company
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestReadIngestedFile(t *testing.T) {
+	processedAt := time.Now()
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectQuery(`SELECT (.+) FROM ingested_files WHERE if_checksum = \$1`).
+		WithArgs("abc").
+		WillReturnRows(mock.NewRows([]string{"if_checksum", "if_file_name", "if_processed_at"}).AddRow("abc", "partner.csv", processedAt))
+
+	repository := NewPostgreCompanyRepository(mock)
+	got, err := repository.ReadIngestedFile(context.Background(), "abc")
+
+	want := &entity.IngestedFile{Checksum: "abc", FileName: "partner.csv", ProcessedAt: processedAt}
+	if err != nil || *got != *want {
+		t.Errorf("got %v, %v want %v", got, err, want)
+	}
+}
+
+func TestSaveIngestedFile(t *testing.T) {
+	file := entity.IngestedFile{Checksum: "abc", FileName: "partner.csv", ProcessedAt: time.Now()}
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec(`INSERT INTO ingested_files(.+) ON CONFLICT \(if_checksum\) DO NOTHING`).
+		WithArgs(file.Checksum, file.FileName, file.ProcessedAt).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	repository := NewPostgreCompanyRepository(mock)
+	err := repository.SaveIngestedFile(context.Background(), file)
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,124 @@
+package company
+
+import (
+	"context"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// MergeCompanyInto writes the combined survivor, records its provenance and
+// retires merged in a single transaction. merged is deleted and redirected to
+// the survivor, as are every company previously merged into it and the
+// crosswalk keys of merged. Both versions
+// must match the stored ones or entity.ERR_VERSION_CONFLICT is returned.
+func (r *PostgreCompanyRepository) MergeCompanyInto(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	err = mergeCompanyInto(ctx, tx, survivor, merged, provenance)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SaveMergedCompany writes what the merge of a record decided in a single
+// transaction: the company when one of its fields changed, the crosswalk key
+// of the record when there is one and the provenance of the fields taken. The
+// company version must match the stored one or entity.ERR_VERSION_CONFLICT is
+// returned.
+func (r *PostgreCompanyRepository) SaveMergedCompany(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if company != nil {
+		err = updateCompany(ctx, tx, *company)
+		if err != nil {
+			return err
+		}
+	}
+
+	if crosswalk != nil {
+		err = saveCrosswalk(ctx, tx, *crosswalk)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = saveProvenance(ctx, tx, provenance)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ConfirmDuplicateCandidate merges like MergeCompanyInto and marks the
+// duplicate candidate as confirmed in the same transaction, returning
+// entity.ERR_VERSION_CONFLICT when the candidate is no longer pending
+func (r *PostgreCompanyRepository) ConfirmDuplicateCandidate(ctx context.Context, candidateID uuid.UUID, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	tag, err := tx.Exec(ctx, `UPDATE duplicate_candidates SET dc_status = $2, dc_resolved_at = now() WHERE dc_candidate_id = $1 AND dc_status = $3`, candidateID, entity.DUPLICATE_STATUS_CONFIRMED, entity.DUPLICATE_STATUS_PENDING)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ERR_VERSION_CONFLICT
+	}
+
+	err = mergeCompanyInto(ctx, tx, survivor, merged, provenance)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func mergeCompanyInto(ctx context.Context, tx executor, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error {
+	tag, err := tx.Exec(ctx, `UPDATE companies_catalog_table SET cc_merged_into = $2, cc_deleted_at = now(), cc_version = cc_version + 1, cc_updated_at = now() WHERE cc_company_id = $1 AND cc_version = $3 AND cc_deleted_at IS NULL`, merged.ID, survivor.ID, merged.Version)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ERR_VERSION_CONFLICT
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE companies_catalog_table SET cc_merged_into = $2 WHERE cc_merged_into = $1`, merged.ID, survivor.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE company_crosswalk SET cx_company_id = $2, cx_updated_at = now() WHERE cx_company_id = $1`, merged.ID, survivor.ID)
+	if err != nil {
+		return err
+	}
+
+	err = updateCompany(ctx, tx, survivor)
+	if err != nil {
+		return err
+	}
+
+	return saveProvenance(ctx, tx, provenance)
+}
@@ -0,0 +1,214 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestMergeCompanyInto(t *testing.T) {
+	survivor := entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 2}
+	merged := entity.Companies{ID: uuid.New(), Name: "COMPANY INC", Zip: "12345", Version: 1}
+	provenance := []entity.Provenance{{CompanyID: survivor.ID, Field: entity.FIELD_WEBSITE}}
+
+	t.Run("merging", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2, cc_deleted_at").
+			WithArgs(merged.ID, survivor.ID, merged.Version).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2 WHERE cc_merged_into = \\$1").
+			WithArgs(merged.ID, survivor.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("UPDATE company_crosswalk SET cx_company_id = \\$2").
+			WithArgs(merged.ID, survivor.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_name").
+			WithArgs(survivor.ID, survivor.Name, survivor.Zip, survivor.Website, survivor.Version, map[string]string{}, pgxmock.AnyArg(), survivor.Domain, survivor.City, survivor.State, survivor.ZipUnknown, entity.COUNTRY_US).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("INSERT INTO company_provenance").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.MergeCompanyInto(context.Background(), survivor, merged, provenance)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("survivor changed meanwhile", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2, cc_deleted_at").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2 WHERE cc_merged_into = \\$1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("UPDATE company_crosswalk").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_name").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("SELECT 1 FROM companies_catalog_table").
+			WithArgs(survivor.ID).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.MergeCompanyInto(context.Background(), survivor, merged, provenance)
+
+		if !errors.Is(err, entity.ERR_VERSION_CONFLICT) {
+			t.Errorf("got %v error want %v", err, entity.ERR_VERSION_CONFLICT)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE companies_catalog_table").
+			WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.MergeCompanyInto(context.Background(), survivor, merged, provenance)
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestConfirmDuplicateCandidate(t *testing.T) {
+	survivor := entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Version: 2}
+	merged := entity.Companies{ID: uuid.New(), Name: "COMPANY INC", Zip: "12345", Version: 1}
+	candidateID := uuid.New()
+
+	t.Run("confirming with the merge", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE duplicate_candidates SET dc_status").
+			WithArgs(candidateID, entity.DUPLICATE_STATUS_CONFIRMED, entity.DUPLICATE_STATUS_PENDING).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2, cc_deleted_at").
+			WithArgs(merged.ID, survivor.ID, merged.Version).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_merged_into = \\$2 WHERE cc_merged_into = \\$1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("UPDATE company_crosswalk").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_name").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.ConfirmDuplicateCandidate(context.Background(), candidateID, survivor, merged, nil)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("candidate resolved meanwhile", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE duplicate_candidates SET dc_status").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.ConfirmDuplicateCandidate(context.Background(), candidateID, survivor, merged, nil)
+
+		if !errors.Is(err, entity.ERR_VERSION_CONFLICT) {
+			t.Errorf("got %v error want %v", err, entity.ERR_VERSION_CONFLICT)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestSaveMergedCompany(t *testing.T) {
+	company := entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 2}
+	crosswalk := entity.Crosswalk{SourceSystem: "client-csv", ExternalKey: "C-1001", CompanyID: company.ID}
+	provenance := []entity.Provenance{{CompanyID: company.ID, Field: entity.FIELD_WEBSITE}}
+
+	t.Run("writing the merge", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_name").
+			WithArgs(company.ID, company.Name, company.Zip, company.Website, company.Version, map[string]string{}, pgxmock.AnyArg(), company.Domain, company.City, company.State, company.ZipUnknown, entity.COUNTRY_US).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("INSERT INTO company_crosswalk").
+			WithArgs(crosswalk.SourceSystem, crosswalk.ExternalKey, crosswalk.CompanyID).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO company_provenance").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveMergedCompany(context.Background(), &company, &crosswalk, provenance)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("only the crosswalk", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO company_crosswalk").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveMergedCompany(context.Background(), nil, &crosswalk, nil)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("rolling back on error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_name").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+		mock.ExpectExec("INSERT INTO company_crosswalk").
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec("INSERT INTO company_provenance").
+			WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveMergedCompany(context.Background(), &company, &crosswalk, provenance)
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,246 @@
+package company
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/jackc/pgconn"
+)
+
+var ERR_NOT_VALID_MIGRATION = errors.New("Error: the migration is not valid")
+
+// MIGRATIONS_LOCK is the advisory lock held while migrating
+const MIGRATIONS_LOCK = 4502
+
+// undefinedTable is the SQLSTATE of a query on a missing table
+const undefinedTable = "42P01"
+
+// The migrations are tracked in the table of goose, so the goose CLI and
+// Migrate agree on what is applied
+const createMigrationsTable = `CREATE TABLE IF NOT EXISTS goose_db_version (
+	id SERIAL PRIMARY KEY,
+	version_id BIGINT NOT NULL,
+	is_applied BOOLEAN NOT NULL,
+	tstamp TIMESTAMP NULL DEFAULT now()
+)`
+
+type MigrationVersionModel struct {
+	VersionID int64      `db:"version_id"`
+	IsApplied bool       `db:"is_applied"`
+	Tstamp    *time.Time `db:"tstamp"`
+}
+
+// migrationFile is a migration read from its file, with the statements
+// applying it
+type migrationFile struct {
+	entity.Migration
+	up string
+}
+
+// readMigrations reads the goose migrations of a directory in version order.
+// The statements applying a migration are the ones between the
+// "-- +goose Up" and "-- +goose Down" annotations; they are sent at once, so
+// the StatementBegin and StatementEnd annotations are not needed.
+func readMigrations(fsys fs.FS) ([]migrationFile, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := []migrationFile{}
+	versions := map[int64]string{}
+	for _, name := range names {
+		base := strings.TrimSuffix(path.Base(name), ".sql")
+		index := strings.Index(base, "_")
+		if index <= 0 {
+			return nil, fmt.Errorf("%w: %s has no version", ERR_NOT_VALID_MIGRATION, name)
+		}
+		version, err := strconv.ParseInt(base[:index], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: %s has no version", ERR_NOT_VALID_MIGRATION, name)
+		}
+		if other, ok := versions[version]; ok {
+			return nil, fmt.Errorf("%w: %s and %s have the same version", ERR_NOT_VALID_MIGRATION, other, name)
+		}
+		versions[version] = name
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		up, err := upStatements(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ERR_NOT_VALID_MIGRATION, name, err)
+		}
+
+		migrations = append(migrations, migrationFile{
+			Migration: entity.Migration{Version: version, Name: base[index+1:]},
+			up:        up,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func upStatements(content string) (string, error) {
+	var up strings.Builder
+	inUp, found := false, false
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		annotation := strings.TrimSpace(line)
+		if strings.HasPrefix(annotation, "-- +goose ") {
+			switch strings.TrimSpace(strings.TrimPrefix(annotation, "-- +goose ")) {
+			case "Up":
+				inUp, found = true, true
+			case "Down":
+				inUp = false
+			case "NO TRANSACTION":
+				return "", errors.New("migrations out of a transaction are not supported")
+			}
+			continue
+		}
+		if inUp {
+			up.WriteString(line)
+			up.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	if !found {
+		return "", errors.New("no -- +goose Up annotation")
+	}
+	return up.String(), nil
+}
+
+// readVersions returns the last row of every version of the migrations table,
+// telling whether it is applied like goose does. A missing table has none.
+func (r *PostgreCompanyRepository) readVersions(ctx context.Context) (map[int64]*MigrationVersionModel, error) {
+	var versionModel []*MigrationVersionModel
+	err := pgxscan.Select(ctx, r.conn, &versionModel, `SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC`)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == undefinedTable {
+		return map[int64]*MigrationVersionModel{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	versions := map[int64]*MigrationVersionModel{}
+	for _, model := range versionModel {
+		if _, ok := versions[model.VersionID]; !ok {
+			versions[model.VersionID] = model
+		}
+	}
+	return versions, nil
+}
+
+// MigrationStatus lists the migrations of the directory, telling which ones
+// are applied, without writing anything
+func (r *PostgreCompanyRepository) MigrationStatus(ctx context.Context, fsys fs.FS) ([]entity.Migration, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	versions, err := r.readVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := []entity.Migration{}
+	for _, migration := range migrations {
+		if version, ok := versions[migration.Version]; ok && version.IsApplied {
+			migration.Applied = true
+			migration.AppliedAt = version.Tstamp
+		}
+		status = append(status, migration.Migration)
+	}
+	return status, nil
+}
+
+// Migrate applies the migrations of the directory that are not applied yet,
+// each one in a transaction of its own, returning the ones applied. The
+// advisory lock is held on the session of the connection until the end, so
+// replicas starting together wait for the first one and then find nothing
+// left to apply.
+func (r *PostgreCompanyRepository) Migrate(ctx context.Context, fsys fs.FS) ([]entity.Migration, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	// the lock belongs to the session, so every statement runs on the same
+	// connection of the pool
+	session, release, err := r.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if _, err := session.conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, MIGRATIONS_LOCK); err != nil {
+		return nil, fmt.Errorf("error while locking the migrations: %w", err)
+	}
+	defer session.conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, MIGRATIONS_LOCK)
+
+	if _, err := session.conn.Exec(ctx, createMigrationsTable); err != nil {
+		return nil, fmt.Errorf("error while creating the migrations table: %w", err)
+	}
+	if _, err := session.conn.Exec(ctx, `INSERT INTO goose_db_version(version_id, is_applied) SELECT 0, true WHERE NOT EXISTS (SELECT 1 FROM goose_db_version)`); err != nil {
+		return nil, fmt.Errorf("error while creating the migrations table: %w", err)
+	}
+
+	versions, err := session.readVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	done := []entity.Migration{}
+	for _, migration := range migrations {
+		if version, ok := versions[migration.Version]; ok && version.IsApplied {
+			continue
+		}
+		if err := session.applyMigration(ctx, migration); err != nil {
+			return done, fmt.Errorf("error while applying %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		migration.Applied = true
+		done = append(done, migration.Migration)
+	}
+	return done, nil
+}
+
+func (r *PostgreCompanyRepository) applyMigration(ctx context.Context, migration migrationFile) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	if _, err = tx.Exec(ctx, migration.up); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `INSERT INTO goose_db_version(version_id, is_applied) VALUES($1, true)`, migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
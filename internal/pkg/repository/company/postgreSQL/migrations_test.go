@@ -0,0 +1,169 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/deployment/migrations"
+	"github.com/jackc/pgconn"
+	"github.com/pashagolub/pgxmock"
+)
+
+const companiesMigration = `-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE companies (id UUID PRIMARY KEY);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE companies;
+-- +goose StatementEnd
+`
+
+func TestReadMigrations(t *testing.T) {
+	t.Run("embedded migrations", func(t *testing.T) {
+		embedded, err := readMigrations(migrations.FS)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(embedded) == 0 || embedded[0].Version != 20220413090627 || embedded[0].Name != "companies_catalog_table" {
+			t.Errorf("got %+v", embedded)
+		}
+		for _, migration := range embedded {
+			if migration.up == "" {
+				t.Errorf("got no statements for %d", migration.Version)
+			}
+		}
+	})
+
+	t.Run("in version order without the down statements", func(t *testing.T) {
+		migrations, err := readMigrations(fstest.MapFS{
+			"2_websites.sql":  {Data: []byte("-- +goose Up\nALTER TABLE companies ADD website TEXT;\n")},
+			"1_companies.sql": {Data: []byte(companiesMigration)},
+			"README.md":       {Data: []byte("not a migration")},
+		})
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(migrations) != 2 || migrations[0].Name != "companies" || migrations[1].Version != 2 {
+			t.Fatalf("got %+v", migrations)
+		}
+		if migrations[0].up != "CREATE TABLE companies (id UUID PRIMARY KEY);\n\n" {
+			t.Errorf("got %q", migrations[0].up)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		for _, fsys := range []fstest.MapFS{
+			{"companies.sql": {Data: []byte(companiesMigration)}},
+			{"1_companies.sql": {Data: []byte("CREATE TABLE companies (id UUID PRIMARY KEY);")}},
+			{"1_companies.sql": {Data: []byte(companiesMigration)}, "01_websites.sql": {Data: []byte(companiesMigration)}},
+		} {
+			if _, err := readMigrations(fsys); !errors.Is(err, ERR_NOT_VALID_MIGRATION) {
+				t.Errorf("got %v error want %v", err, ERR_NOT_VALID_MIGRATION)
+			}
+		}
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_companies.sql": {Data: []byte(companiesMigration)},
+		"2_websites.sql":  {Data: []byte("-- +goose Up\nALTER TABLE companies ADD website TEXT;\n")},
+	}
+
+	t.Run("applying the missing migrations under the lock", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectExec(`SELECT pg_advisory_lock\(\$1\)`).WithArgs(MIGRATIONS_LOCK).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS goose_db_version`).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+		mock.ExpectExec(`INSERT INTO goose_db_version(.+) SELECT 0, true`).WillReturnResult(pgxmock.NewResult("INSERT", 0))
+		mock.ExpectQuery(`SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC`).
+			WillReturnRows(mock.NewRows([]string{"version_id", "is_applied", "tstamp"}).AddRow(int64(1), true, nil).AddRow(int64(0), true, nil))
+		mock.ExpectBegin()
+		mock.ExpectExec(`ALTER TABLE companies ADD website TEXT`).WillReturnResult(pgxmock.NewResult("ALTER", 0))
+		mock.ExpectExec(`INSERT INTO goose_db_version(.+) VALUES\(\$1, true\)`).WithArgs(int64(2)).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+		mock.ExpectExec(`SELECT pg_advisory_unlock\(\$1\)`).WithArgs(MIGRATIONS_LOCK).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		applied, err := repository.Migrate(context.Background(), fsys)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(applied) != 1 || applied[0].Version != 2 || !applied[0].Applied {
+			t.Errorf("got %+v want the second migration applied", applied)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("failing migration", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectExec(`SELECT pg_advisory_lock`).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		mock.ExpectExec(`CREATE TABLE IF NOT EXISTS goose_db_version`).WillReturnResult(pgxmock.NewResult("CREATE", 0))
+		mock.ExpectExec(`INSERT INTO goose_db_version(.+) SELECT 0, true`).WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectQuery(`SELECT (.+) FROM goose_db_version`).
+			WillReturnRows(mock.NewRows([]string{"version_id", "is_applied", "tstamp"}).AddRow(int64(0), true, nil))
+		mock.ExpectBegin()
+		mock.ExpectExec(`CREATE TABLE companies`).WillReturnError(errors.New("permission denied"))
+		mock.ExpectRollback()
+		mock.ExpectExec(`SELECT pg_advisory_unlock`).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		applied, err := repository.Migrate(context.Background(), fsys)
+
+		if err == nil || len(applied) != 0 {
+			t.Errorf("got %v, %v want the error of the first migration", applied, err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestMigrationStatus(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1_companies.sql": {Data: []byte(companiesMigration)},
+		"2_websites.sql":  {Data: []byte("-- +goose Up\nALTER TABLE companies ADD website TEXT;\n")},
+	}
+
+	t.Run("applied and pending", func(t *testing.T) {
+		appliedAt := time.Now()
+
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id DESC`).
+			WillReturnRows(mock.NewRows([]string{"version_id", "is_applied", "tstamp"}).
+				AddRow(int64(2), false, &appliedAt).
+				AddRow(int64(2), true, &appliedAt).
+				AddRow(int64(1), true, &appliedAt))
+
+		repository := NewPostgreCompanyRepository(mock)
+		status, err := repository.MigrationStatus(context.Background(), fsys)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(status) != 2 || !status[0].Applied || status[0].AppliedAt == nil || status[1].Applied {
+			t.Errorf("got %+v want the second migration rolled back", status)
+		}
+	})
+
+	t.Run("missing table", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM goose_db_version`).WillReturnError(&pgconn.PgError{Code: undefinedTable})
+
+		repository := NewPostgreCompanyRepository(mock)
+		status, err := repository.MigrationStatus(context.Background(), fsys)
+
+		if err != nil || len(status) != 2 || status[0].Applied || status[1].Applied {
+			t.Errorf("got %+v, %v want every migration pending", status, err)
+		}
+	})
+}
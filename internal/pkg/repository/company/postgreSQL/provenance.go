@@ -0,0 +1,60 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
+)
+
+type ProvenanceModel struct {
+	CompanyID    uuid.UUID `db:"cp_company_id"`
+	Field        string    `db:"cp_field"`
+	SourceSystem string    `db:"cp_source_system"`
+	SourceFile   string    `db:"cp_source_file"`
+	ImportJob    string    `db:"cp_import_job"`
+	RecordedAt   time.Time `db:"cp_recorded_at"`
+	Priority     int       `db:"cp_source_priority"`
+}
+
+func (r *PostgreCompanyRepository) SaveProvenance(ctx context.Context, provenance []entity.Provenance) error {
+	return saveProvenance(ctx, r.conn, provenance)
+}
+
+func saveProvenance(ctx context.Context, conn executor, provenance []entity.Provenance) error {
+	for _, record := range provenance {
+		_, err := conn.Exec(ctx, `INSERT INTO company_provenance(cp_company_id, cp_field, cp_source_system, cp_source_file, cp_import_job, cp_recorded_at, cp_source_priority) values($1, $2, $3, $4, $5, $6, $7)
+			ON CONFLICT (cp_company_id, cp_field) DO UPDATE SET cp_source_system = EXCLUDED.cp_source_system, cp_source_file = EXCLUDED.cp_source_file, cp_import_job = EXCLUDED.cp_import_job, cp_recorded_at = EXCLUDED.cp_recorded_at, cp_source_priority = EXCLUDED.cp_source_priority`,
+			record.CompanyID, record.Field, record.SourceSystem, record.SourceFile, record.ImportJob, record.RecordedAt, record.Priority)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgreCompanyRepository) ReadProvenance(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+	var provenanceModel []*ProvenanceModel
+	provenance := []*entity.Provenance{}
+
+	err := pgxscan.Select(ctx, r.conn, &provenanceModel, `SELECT * FROM company_provenance WHERE cp_company_id = ANY($1)`, companyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range provenanceModel {
+		provenance = append(provenance, &entity.Provenance{
+			CompanyID:    model.CompanyID,
+			Field:        model.Field,
+			SourceSystem: model.SourceSystem,
+			SourceFile:   model.SourceFile,
+			ImportJob:    model.ImportJob,
+			Priority:     model.Priority,
+			RecordedAt:   model.RecordedAt,
+		})
+	}
+	return provenance, nil
+}
@@ -0,0 +1,98 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestSaveProvenance(t *testing.T) {
+	t.Run("Saving provenance", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		record := entity.Provenance{
+			CompanyID:    uuid.New(),
+			Field:        entity.FIELD_WEBSITE,
+			SourceSystem: "client-csv",
+			SourceFile:   "q2_clientData.csv",
+			ImportJob:    uuid.NewString(),
+			Priority:     10,
+			RecordedAt:   time.Now(),
+		}
+
+		mock.ExpectExec("INSERT INTO company_provenance").
+			WithArgs(record.CompanyID, record.Field, record.SourceSystem, record.SourceFile, record.ImportJob, record.RecordedAt, record.Priority).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveProvenance(context.Background(), []entity.Provenance{record})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		mock.ExpectExec("INSERT INTO company_provenance").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveProvenance(context.Background(), []entity.Provenance{{CompanyID: uuid.New()}})
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestReadProvenance(t *testing.T) {
+	t.Run("with_provenance", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		want := &entity.Provenance{
+			CompanyID:    uuid.New(),
+			Field:        entity.FIELD_WEBSITE,
+			SourceSystem: "client-csv",
+			SourceFile:   "q2_clientData.csv",
+			ImportJob:    uuid.NewString(),
+			Priority:     10,
+			RecordedAt:   time.Now(),
+		}
+
+		mock.ExpectQuery("SELECT (.+) FROM company_provenance WHERE (.+)").
+			WillReturnRows(mock.NewRows([]string{"cp_company_id", "cp_field", "cp_source_system", "cp_source_file", "cp_import_job", "cp_recorded_at", "cp_source_priority"}).
+				AddRow(want.CompanyID, want.Field, want.SourceSystem, want.SourceFile, want.ImportJob, want.RecordedAt, want.Priority))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadProvenance(context.Background(), []uuid.UUID{want.CompanyID})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(want, got[0]) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+
+		mock.ExpectQuery("SELECT (.+) FROM company_provenance WHERE (.+)").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ReadProvenance(context.Background(), []uuid.UUID{uuid.New()})
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
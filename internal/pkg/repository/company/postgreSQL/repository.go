@@ -3,35 +3,138 @@ package company
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
 	"github.com/georgysavva/scany/pgxscan"
 	"github.com/google/uuid"
 	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 type CompanyModel struct {
-	CompanyID      uuid.UUID `db:"cc_company_id"`
-	ComapanyName   string    `db:"cc_name"`
-	CompanyZIP     string    `db:"cc_zip"`
-	CompanyWebSite string    `db:"cc_website"`
+	CompanyID         uuid.UUID         `db:"cc_company_id"`
+	CompanyParentID   uuid.UUID         `db:"cc_parent_id"`
+	ComapanyName      string            `db:"cc_name"`
+	CompanyZIP        string            `db:"cc_zip"`
+	CompanyZIPBase    string            `db:"cc_zip_base"`
+	CompanyCountry    string            `db:"cc_country"`
+	CompanyCity       string            `db:"cc_city"`
+	CompanyState      string            `db:"cc_state"`
+	CompanyZipUnknown bool              `db:"cc_zip_unknown"`
+	CompanyWebSite    string            `db:"cc_website"`
+	CompanyDomain     string            `db:"cc_domain"`
+	CompanyDeletedAt  *time.Time        `db:"cc_deleted_at"`
+	CompanyMergedInto *uuid.UUID        `db:"cc_merged_into"`
+	CompanyCreatedAt  time.Time         `db:"cc_created_at"`
+	CompanyUpdatedAt  time.Time         `db:"cc_updated_at"`
+	CompanyVersion    int               `db:"cc_version"`
+	CompanyAttributes map[string]string `db:"cc_attributes"`
+}
+
+type ParentCompanyModel struct {
+	ParentID        uuid.UUID `db:"pc_parent_id"`
+	ParentName      string    `db:"pc_name"`
+	ParentCreatedAt time.Time `db:"pc_created_at"`
 }
 
 type PostgreCompanyRepository struct {
 	conn connector
 }
 
+type executor interface {
+	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+}
+
 type connector interface {
 	pgxscan.Querier
-	Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error)
+	executor
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// acquirer is a connection pool, each statement running on any of its
+// connections
+type acquirer interface {
+	Acquire(ctx context.Context) (*pgxpool.Conn, error)
 }
 
 func NewPostgreCompanyRepository(conn connector) *PostgreCompanyRepository {
 	return &PostgreCompanyRepository{conn}
 }
 
+// acquire returns a repository on a single connection of the pool, for work
+// keeping state on the session such as cursors and advisory locks, and the
+// function handing the connection back. Without a pool the repository is
+// returned as is
+func (r *PostgreCompanyRepository) acquire(ctx context.Context) (*PostgreCompanyRepository, func(), error) {
+	pool, ok := r.conn.(acquirer)
+	if !ok {
+		return r, func() {}, nil
+	}
+	conn, err := pool.Acquire(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while acquiring a connection: %w", err)
+	}
+	return &PostgreCompanyRepository{conn}, conn.Release, nil
+}
+
+func (m *CompanyModel) toEntity() *entity.Companies {
+	return &entity.Companies{
+		ID:         m.CompanyID,
+		ParentID:   m.CompanyParentID,
+		Name:       m.ComapanyName,
+		Zip:        m.CompanyZIP,
+		Country:    m.CompanyCountry,
+		City:       m.CompanyCity,
+		State:      m.CompanyState,
+		ZipUnknown: m.CompanyZipUnknown,
+		Website:    m.CompanyWebSite,
+		Domain:     m.CompanyDomain,
+		Attributes: m.CompanyAttributes,
+		DeletedAt:  m.CompanyDeletedAt,
+		MergedInto: m.CompanyMergedInto,
+		CreatedAt:  m.CompanyCreatedAt,
+		UpdatedAt:  m.CompanyUpdatedAt,
+		Version:    m.CompanyVersion,
+	}
+}
+
+func (m *ParentCompanyModel) toEntity() *entity.ParentCompany {
+	return &entity.ParentCompany{
+		ID:        m.ParentID,
+		Name:      m.ParentName,
+		Locations: []entity.Companies{},
+	}
+}
+
+// attributesColumn keeps cc_attributes from being written as NULL
+func attributesColumn(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return map[string]string{}
+	}
+	return attributes
+}
+
+// countryColumn stores companies without a country as US ones
+func countryColumn(country string) string {
+	if country == "" {
+		return entity.COUNTRY_US
+	}
+	return country
+}
+
+// parentCTE finds or creates the parent company named by the $2 parameter,
+// using parameter idParam as the ID of a new parent
+func parentCTE(idParam int) string {
+	return fmt.Sprintf(`WITH parent AS (INSERT INTO parent_companies_table(pc_parent_id, pc_name) VALUES ($%d, $2) ON CONFLICT (pc_name) DO UPDATE SET pc_name = EXCLUDED.pc_name RETURNING pc_parent_id) `, idParam)
+}
+
+// AddCompany inserts a location, attaching it to the parent company with the
+// same name
 func (r *PostgreCompanyRepository) AddCompany(ctx context.Context, company entity.Companies) error {
-	_, err := r.conn.Exec(ctx, `INSERT INTO companies_catalog_table(cc_company_id, cc_name, cc_zip, cc_website) values($1, $2, $3, $4)`, company.ID, company.Name, company.Zip, company.Website)
+	_, err := r.conn.Exec(ctx, parentCTE(6)+`INSERT INTO companies_catalog_table(cc_company_id, cc_name, cc_zip, cc_website, cc_attributes, cc_parent_id, cc_domain, cc_city, cc_state, cc_zip_unknown, cc_country) SELECT $1, $2, $3, $4, $5, pc_parent_id, $7, $8, $9, $10, $11 FROM parent`, company.ID, company.Name, company.Zip, company.Website, attributesColumn(company.Attributes), uuid.New(), company.Domain, company.City, company.State, company.ZipUnknown, countryColumn(company.Country))
 	if err != nil {
 		return err
 	}
@@ -40,7 +143,7 @@ func (r *PostgreCompanyRepository) AddCompany(ctx context.Context, company entit
 
 func (r *PostgreCompanyRepository) ReadCompanyByName(ctx context.Context, name string) (*entity.Companies, error) {
 	var company []*CompanyModel
-	err := pgxscan.Select(ctx, r.conn, &company, `SELECT * FROM companies_catalog_table WHERE cc_name = $1`, name)
+	err := pgxscan.Select(ctx, r.conn, &company, `SELECT * FROM companies_catalog_table WHERE cc_name = $1 AND cc_deleted_at IS NULL`, name)
 	if err != nil {
 		return nil, fmt.Errorf("error while executing query: %w", err)
 	}
@@ -49,12 +152,71 @@ func (r *PostgreCompanyRepository) ReadCompanyByName(ctx context.Context, name s
 		return nil, nil
 	}
 
-	return &entity.Companies{
-		ID:      company[0].CompanyID,
-		Name:    company[0].ComapanyName,
-		Zip:     company[0].CompanyZIP,
-		Website: company[0].CompanyWebSite,
-	}, nil
+	return company[0].toEntity(), nil
+}
+
+// ReadCompanyLocations returns every location of the parent company with the
+// given name
+func (r *PostgreCompanyRepository) ReadCompanyLocations(ctx context.Context, name string) ([]*entity.Companies, error) {
+	var companyModel []*CompanyModel
+	company := []*entity.Companies{}
+	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT c.* FROM companies_catalog_table c JOIN parent_companies_table p ON p.pc_parent_id = c.cc_parent_id WHERE p.pc_name = $1 AND c.cc_deleted_at IS NULL ORDER BY c.cc_zip`, name)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for index := range companyModel {
+		company = append(company, companyModel[index].toEntity())
+	}
+	return company, nil
+}
+
+func (r *PostgreCompanyRepository) ReadCompaniesByDomain(ctx context.Context, domain string) ([]*entity.Companies, error) {
+	var companyModel []*CompanyModel
+	company := []*entity.Companies{}
+	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT * FROM companies_catalog_table WHERE cc_domain = $1 AND cc_deleted_at IS NULL ORDER BY cc_zip`, domain)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for index := range companyModel {
+		company = append(company, companyModel[index].toEntity())
+	}
+	return company, nil
+}
+
+func (r *PostgreCompanyRepository) ReadParentCompany(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+	var parentModel []*ParentCompanyModel
+	err := pgxscan.Select(ctx, r.conn, &parentModel, `SELECT * FROM parent_companies_table WHERE pc_parent_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(parentModel) == 0 {
+		return nil, nil
+	}
+	return parentModel[0].toEntity(), nil
+}
+
+func (r *PostgreCompanyRepository) ReadCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+	var companyModel []*CompanyModel
+
+	// the ID of a company merged into another one resolves to the survivor
+	query := `SELECT * FROM companies_catalog_table WHERE cc_company_id = COALESCE((SELECT cc_merged_into FROM companies_catalog_table WHERE cc_company_id = $1), $1)`
+	if !includeDeleted {
+		query += ` AND cc_deleted_at IS NULL`
+	}
+
+	err := pgxscan.Select(ctx, r.conn, &companyModel, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(companyModel) == 0 {
+		return nil, nil
+	}
+
+	return companyModel[0].toEntity(), nil
 }
 
 func (r *PostgreCompanyRepository) SearchCompanyByNameAndZip(ctx context.Context, name string, zip string) (*entity.Companies, error) {
@@ -62,7 +224,9 @@ func (r *PostgreCompanyRepository) SearchCompanyByNameAndZip(ctx context.Context
 
 	pattern := fmt.Sprintf("%s%s%s", "%", name, "%")
 
-	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT * FROM companies_catalog_table WHERE cc_name LIKE $1 AND cc_zip = $2`, pattern, zip)
+	// the best matching parent company wins: an exact name first, then the
+	// shortest name containing the search term
+	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT c.* FROM companies_catalog_table c JOIN parent_companies_table p ON p.pc_parent_id = c.cc_parent_id WHERE p.pc_name LIKE $1 AND c.cc_zip_base = $2 AND c.cc_deleted_at IS NULL ORDER BY p.pc_name = $3 DESC, length(p.pc_name), p.pc_name`, pattern, zip, name)
 	if err != nil {
 		return nil, fmt.Errorf("error while executing query: %w", err)
 	}
@@ -70,35 +234,64 @@ func (r *PostgreCompanyRepository) SearchCompanyByNameAndZip(ctx context.Context
 	if len(companyModel) == 0 {
 		return nil, nil
 	}
-	return &entity.Companies{
-		ID:      companyModel[0].CompanyID,
-		Name:    companyModel[0].ComapanyName,
-		Zip:     companyModel[0].CompanyZIP,
-		Website: companyModel[0].CompanyWebSite,
-	}, nil
+	return companyModel[0].toEntity(), nil
 
 }
 
+// UpdateCompany writes the company only if its stored version still matches
+// company.Version, returning entity.ERR_VERSION_CONFLICT otherwise, or
+// entity.ERR_COMPANY_NOT_EXISTS when the company is missing or deleted. A
+// renamed location moves to the parent company with the new name
 func (r PostgreCompanyRepository) UpdateCompany(ctx context.Context, company entity.Companies) error {
-	_, err := r.conn.Exec(ctx, `UPDATE companies_catalog_table SET cc_name = $2, cc_zip = $3,  cc_website = $4 WHERE cc_company_id = $1`, company.ID, company.Name, company.Zip, company.Website)
+	return updateCompany(ctx, r.conn, company)
+}
+
+func updateCompany(ctx context.Context, conn executor, company entity.Companies) error {
+	tag, err := conn.Exec(ctx, parentCTE(7)+`UPDATE companies_catalog_table SET cc_name = $2, cc_zip = $3,  cc_website = $4, cc_domain = $8, cc_city = $9, cc_state = $10, cc_zip_unknown = $11, cc_country = $12, cc_attributes = $6, cc_parent_id = (SELECT pc_parent_id FROM parent), cc_version = cc_version + 1, cc_updated_at = now() WHERE cc_company_id = $1 AND cc_version = $5 AND cc_deleted_at IS NULL`, company.ID, company.Name, company.Zip, company.Website, company.Version, attributesColumn(company.Attributes), uuid.New(), company.Domain, company.City, company.State, company.ZipUnknown, countryColumn(company.Country))
 	if err != nil {
 		return err
 	}
+	if tag.RowsAffected() == 0 {
+		return companyNotUpdated(ctx, conn, company.ID)
+	}
 	return nil
 }
 
+// companyNotUpdated tells why an update matched no row: the company is gone,
+// or it is there with another version
+func companyNotUpdated(ctx context.Context, conn executor, id uuid.UUID) error {
+	tag, err := conn.Exec(ctx, `SELECT 1 FROM companies_catalog_table WHERE cc_company_id = $1 AND cc_deleted_at IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return entity.ERR_COMPANY_NOT_EXISTS
+	}
+	return entity.ERR_VERSION_CONFLICT
+}
+
 func (r PostgreCompanyRepository) DeleteCompany(ctx context.Context, company entity.Companies) error {
-	_, err := r.conn.Exec(ctx, `DELETE FROM companies_catalog_table WHERE cc_company_id = $1`, company.ID)
+	_, err := r.conn.Exec(ctx, `UPDATE companies_catalog_table SET cc_deleted_at = now() WHERE cc_company_id = $1 AND cc_deleted_at IS NULL`, company.ID)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// RestoreCompany clears the deletion mark of a company, reporting whether
+// there was a deleted company with the given ID.
+func (r PostgreCompanyRepository) RestoreCompany(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.conn.Exec(ctx, `UPDATE companies_catalog_table SET cc_deleted_at = NULL WHERE cc_company_id = $1 AND cc_deleted_at IS NOT NULL AND cc_merged_into IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
 func (r *PostgreCompanyRepository) GetCompany(ctx context.Context, key string) ([]*entity.Companies, error) {
 	var companyModel []*CompanyModel
 	company := []*entity.Companies{}
-	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT * FROM companies_catalog_table`)
+	err := pgxscan.Select(ctx, r.conn, &companyModel, `SELECT * FROM companies_catalog_table WHERE cc_deleted_at IS NULL`)
 	if err != nil {
 		return nil, fmt.Errorf("error while executing query: %w", err)
 	}
@@ -108,12 +301,60 @@ func (r *PostgreCompanyRepository) GetCompany(ctx context.Context, key string) (
 	}
 
 	for index := range companyModel {
-		company = append(company, &entity.Companies{
-			ID:      companyModel[index].CompanyID,
-			Name:    companyModel[index].ComapanyName,
-			Zip:     companyModel[index].CompanyZIP,
-			Website: companyModel[index].CompanyWebSite,
-		})
+		company = append(company, companyModel[index].toEntity())
+	}
+	return company, nil
+}
+
+// companyFilterQuery selects the companies matching the filter, ordered by
+// name and zip code
+func companyFilterQuery(filter entity.CompanyFilter) (string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if filter.Name != "" {
+		args = append(args, fmt.Sprintf("%s%s%s", "%", filter.Name, "%"))
+		conditions = append(conditions, fmt.Sprintf("cc_name LIKE $%d", len(args)))
+	}
+	if filter.Zip != "" {
+		args = append(args, filter.Zip)
+		conditions = append(conditions, fmt.Sprintf("cc_zip_base = $%d", len(args)))
+	}
+	if filter.Country != "" {
+		args = append(args, filter.Country)
+		conditions = append(conditions, fmt.Sprintf("cc_country = $%d", len(args)))
+	}
+	if filter.ParentID != uuid.Nil {
+		args = append(args, filter.ParentID)
+		conditions = append(conditions, fmt.Sprintf("cc_parent_id = $%d", len(args)))
+	}
+	if filter.ZipUnknown {
+		conditions = append(conditions, "cc_zip_unknown")
+	}
+	if !filter.IncludeDeleted {
+		conditions = append(conditions, "cc_deleted_at IS NULL")
+	}
+
+	query := `SELECT * FROM companies_catalog_table`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY cc_name, cc_zip"
+	return query, args
+}
+
+func (r *PostgreCompanyRepository) ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+	var companyModel []*CompanyModel
+	company := []*entity.Companies{}
+
+	query, args := companyFilterQuery(filter)
+	err := pgxscan.Select(ctx, r.conn, &companyModel, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for index := range companyModel {
+		company = append(company, companyModel[index].toEntity())
 	}
 	return company, nil
 }
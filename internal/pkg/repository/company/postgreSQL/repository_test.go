@@ -43,7 +43,7 @@ func TestAddCompany(t *testing.T) {
 		}
 
 		mock.ExpectExec("INSERT INTO companies_catalog_table").
-			WithArgs(company.ID, company.Name, company.Zip, company.Website).
+			WithArgs(company.ID, company.Name, company.Zip, company.Website, map[string]string{}, pgxmock.AnyArg(), company.Domain, company.City, company.State, company.ZipUnknown, entity.COUNTRY_US).
 			WillReturnResult(pgxmock.NewResult("INSERT", 1))
 
 		repository := NewPostgreCompanyRepository(mock)
@@ -140,7 +140,7 @@ func TestReadCompanyByName(t *testing.T) {
 func TestSearchCompanyByNameAndZip(t *testing.T) {
 	t.Run("no_rows", func(t *testing.T) {
 		mock, _ := pgxmock.NewConn()
-		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table WHERE (.+)").
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table c JOIN parent_companies_table p (.+) WHERE (.+)").
 			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website"}))
 
 		repository := NewPostgreCompanyRepository(mock)
@@ -166,7 +166,7 @@ func TestSearchCompanyByNameAndZip(t *testing.T) {
 			Website: "www.company.com",
 		}
 
-		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table WHERE (.+)").
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table c JOIN parent_companies_table p (.+) WHERE (.+)").
 			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website"}).
 				AddRow(company.ID, company.Name, company.Zip, company.Website))
 
@@ -186,7 +186,7 @@ func TestSearchCompanyByNameAndZip(t *testing.T) {
 	t.Run("with_error", func(t *testing.T) {
 		mock, _ := pgxmock.NewConn()
 
-		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table WHERE (.+)").
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table c JOIN parent_companies_table p (.+) WHERE (.+)").
 			WillReturnError(errors.New("error"))
 
 		repository := NewPostgreCompanyRepository(mock)
@@ -207,6 +207,10 @@ func TestUpdateComapany(t *testing.T) {
 		Name:    "Company",
 		Zip:     "12345",
 		Website: "www.company.com",
+		Attributes: map[string]string{
+			"phone": "555-0100",
+		},
+		Version: 3,
 	}
 
 	repository := NewPostgreCompanyRepository(mock)
@@ -214,7 +218,7 @@ func TestUpdateComapany(t *testing.T) {
 	t.Run("Updating Company", func(t *testing.T) {
 
 		mock.ExpectExec("UPDATE companies_catalog_table SET ").
-			WithArgs(company.ID, company.Name, company.Zip, company.Website).
+			WithArgs(company.ID, company.Name, company.Zip, company.Website, company.Version, company.Attributes, pgxmock.AnyArg(), company.Domain, company.City, company.State, company.ZipUnknown, entity.COUNTRY_US).
 			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 
 		err := repository.UpdateCompany(context.Background(), *company)
@@ -224,6 +228,35 @@ func TestUpdateComapany(t *testing.T) {
 		}
 	})
 
+	t.Run("version conflict", func(t *testing.T) {
+		mock.ExpectExec("UPDATE companies_catalog_table SET (.+) cc_version = cc_version \\+ 1").
+			WithArgs(company.ID, company.Name, company.Zip, company.Website, company.Version, company.Attributes, pgxmock.AnyArg(), company.Domain, company.City, company.State, company.ZipUnknown, entity.COUNTRY_US).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("SELECT 1 FROM companies_catalog_table").
+			WithArgs(company.ID).
+			WillReturnResult(pgxmock.NewResult("SELECT", 1))
+
+		err := repository.UpdateCompany(context.Background(), *company)
+
+		if !errors.Is(err, entity.ERR_VERSION_CONFLICT) {
+			t.Errorf("got %v want %v", err, entity.ERR_VERSION_CONFLICT)
+		}
+	})
+
+	t.Run("deleted or missing company", func(t *testing.T) {
+		mock.ExpectExec("UPDATE companies_catalog_table SET (.+) cc_version = cc_version \\+ 1").
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+		mock.ExpectExec("SELECT 1 FROM companies_catalog_table").
+			WithArgs(company.ID).
+			WillReturnResult(pgxmock.NewResult("SELECT", 0))
+
+		err := repository.UpdateCompany(context.Background(), *company)
+
+		if !errors.Is(err, entity.ERR_COMPANY_NOT_EXISTS) {
+			t.Errorf("got %v want %v", err, entity.ERR_COMPANY_NOT_EXISTS)
+		}
+	})
+
 	t.Run("with_error", func(t *testing.T) {
 		mock, _ := pgxmock.NewConn()
 
@@ -278,3 +311,262 @@ func TestGetCompany(t *testing.T) {
 		}
 	})
 }
+
+func TestDeleteCompany(t *testing.T) {
+	mock, _ := pgxmock.NewConn()
+
+	company := &entity.Companies{
+		ID:      uuid.New(),
+		Name:    "Company",
+		Zip:     "12345",
+		Website: "www.company.com",
+	}
+
+	repository := NewPostgreCompanyRepository(mock)
+
+	t.Run("Soft deleting Company", func(t *testing.T) {
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_deleted_at = now()").
+			WithArgs(company.ID).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		err := repository.DeleteCompany(context.Background(), *company)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Errorf("there were unfulfilled expectations: %v", err)
+		}
+	})
+}
+
+func TestRestoreCompany(t *testing.T) {
+	t.Run("Restoring Company", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		id := uuid.New()
+
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_deleted_at = NULL").
+			WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+		repository := NewPostgreCompanyRepository(mock)
+		restored, err := repository.RestoreCompany(context.Background(), id)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !restored {
+			t.Errorf("got %v want true", restored)
+		}
+	})
+
+	t.Run("not deleted", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		id := uuid.New()
+
+		mock.ExpectExec("UPDATE companies_catalog_table SET cc_deleted_at = NULL").
+			WithArgs(id).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		repository := NewPostgreCompanyRepository(mock)
+		restored, err := repository.RestoreCompany(context.Background(), id)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if restored {
+			t.Errorf("got %v want false", restored)
+		}
+	})
+}
+
+func TestListCompanies(t *testing.T) {
+	company := &entity.Companies{
+		ID:      uuid.New(),
+		Name:    "COMPANY",
+		Zip:     "12345",
+		Website: "www.company.com",
+	}
+
+	t.Run("excluding deleted", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM companies_catalog_table WHERE cc_name LIKE \$1 AND cc_deleted_at IS NULL`).
+			WithArgs("%COMP%").
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website"}).
+				AddRow(company.ID, company.Name, company.Zip, company.Website))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListCompanies(context.Background(), entity.CompanyFilter{Name: "COMP"})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(company, got[0]) {
+			t.Errorf("got %v want %v", got, company)
+		}
+	})
+
+	t.Run("by parent", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		parentID := uuid.New()
+		mock.ExpectQuery(`SELECT (.+) FROM companies_catalog_table WHERE cc_parent_id = \$1 AND cc_deleted_at IS NULL`).
+			WithArgs(parentID).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website"}))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListCompanies(context.Background(), entity.CompanyFilter{ParentID: parentID})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+	})
+
+	t.Run("with unknown zip", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM companies_catalog_table WHERE cc_zip_unknown AND cc_deleted_at IS NULL`).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_zip_unknown"}))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListCompanies(context.Background(), entity.CompanyFilter{ZipUnknown: true})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+	})
+
+	t.Run("including deleted", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM companies_catalog_table WHERE cc_zip_base = \$1 ORDER BY`).
+			WithArgs("12345").
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website"}))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListCompanies(context.Background(), entity.CompanyFilter{Zip: "12345", IncludeDeleted: true})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("got %v want empty", got)
+		}
+	})
+}
+
+func TestReadCompanyLocations(t *testing.T) {
+	parentID := uuid.New()
+	locations := []*entity.Companies{
+		{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "12345"},
+		{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "54321"},
+	}
+
+	t.Run("with locations", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		rows := mock.NewRows([]string{"cc_company_id", "cc_parent_id", "cc_name", "cc_zip"})
+		for _, location := range locations {
+			rows.AddRow(location.ID, location.ParentID, location.Name, location.Zip)
+		}
+		mock.ExpectQuery(`SELECT c.\* FROM companies_catalog_table c JOIN parent_companies_table p (.+) WHERE p.pc_name = \$1`).
+			WithArgs("COMPANY").
+			WillReturnRows(rows)
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadCompanyLocations(context.Background(), "COMPANY")
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(locations, got) {
+			t.Errorf("got %v want %v", got, locations)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ReadCompanyLocations(context.Background(), "COMPANY")
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestReadCompaniesByDomain(t *testing.T) {
+	companies := []*entity.Companies{
+		{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "https://www.company.com", Domain: "company.com"},
+		{ID: uuid.New(), Name: "COMPANY STORE", Zip: "54321", Website: "https://store.company.com", Domain: "company.com"},
+	}
+
+	t.Run("with companies", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		rows := mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "cc_website", "cc_domain"})
+		for _, company := range companies {
+			rows.AddRow(company.ID, company.Name, company.Zip, company.Website, company.Domain)
+		}
+		mock.ExpectQuery(`SELECT \* FROM companies_catalog_table WHERE cc_domain = \$1`).
+			WithArgs("company.com").
+			WillReturnRows(rows)
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadCompaniesByDomain(context.Background(), "company.com")
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(companies, got) {
+			t.Errorf("got %v want %v", got, companies)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ReadCompaniesByDomain(context.Background(), "company.com")
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestReadParentCompany(t *testing.T) {
+	t.Run("no_rows", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM parent_companies_table WHERE (.+)").
+			WillReturnRows(mock.NewRows([]string{"pc_parent_id", "pc_name"}))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadParentCompany(context.Background(), uuid.New())
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if got != nil {
+			t.Errorf("got %v want nil", got)
+		}
+	})
+
+	t.Run("with parent", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		want := &entity.ParentCompany{ID: uuid.New(), Name: "COMPANY", Locations: []entity.Companies{}}
+		mock.ExpectQuery("SELECT (.+) FROM parent_companies_table WHERE (.+)").
+			WithArgs(want.ID).
+			WillReturnRows(mock.NewRows([]string{"pc_parent_id", "pc_name"}).AddRow(want.ID, want.Name))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ReadParentCompany(context.Background(), want.ID)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+}
@@ -0,0 +1,250 @@
+package company
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+	"github.com/google/uuid"
+)
+
+type WebhookSubscriptionModel struct {
+	ID        uuid.UUID `db:"ws_id"`
+	URL       string    `db:"ws_url"`
+	Secret    string    `db:"ws_secret"`
+	Events    []string  `db:"ws_events"`
+	CreatedAt time.Time `db:"ws_created_at"`
+	UpdatedAt time.Time `db:"ws_updated_at"`
+}
+
+func (m *WebhookSubscriptionModel) toEntity() *entity.WebhookSubscription {
+	return &entity.WebhookSubscription{
+		ID:        m.ID,
+		URL:       m.URL,
+		Secret:    m.Secret,
+		Events:    m.Events,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+	}
+}
+
+type WebhookEventModel struct {
+	ID            int64      `db:"wo_id"`
+	Event         string     `db:"wo_event"`
+	CompanyID     uuid.UUID  `db:"wo_company_id"`
+	MergedInto    *uuid.UUID `db:"wo_merged_into"`
+	ChangedFields []string   `db:"wo_changed_fields"`
+	OccurredAt    time.Time  `db:"wo_occurred_at"`
+	DispatchedAt  *time.Time `db:"wo_dispatched_at"`
+	Company       []byte     `db:"wo_company"`
+}
+
+func (m *WebhookEventModel) toEntity() *entity.CompanyEvent {
+	return &entity.CompanyEvent{
+		ID:            m.ID,
+		Event:         m.Event,
+		CompanyID:     m.CompanyID,
+		MergedInto:    m.MergedInto,
+		ChangedFields: m.ChangedFields,
+		OccurredAt:    m.OccurredAt,
+	}
+}
+
+type WebhookDeliveryModel struct {
+	ID             uuid.UUID  `db:"wd_id"`
+	SubscriptionID uuid.UUID  `db:"wd_subscription_id"`
+	EventID        int64      `db:"wd_event_id"`
+	Event          string     `db:"wd_event"`
+	Payload        []byte     `db:"wd_payload"`
+	Status         string     `db:"wd_status"`
+	Attempts       int        `db:"wd_attempts"`
+	NextAttemptAt  time.Time  `db:"wd_next_attempt_at"`
+	LastError      string     `db:"wd_last_error"`
+	CreatedAt      time.Time  `db:"wd_created_at"`
+	DeliveredAt    *time.Time `db:"wd_delivered_at"`
+	URL            string     `db:"ws_url"`
+	Secret         string     `db:"ws_secret"`
+}
+
+func (m *WebhookDeliveryModel) toEntity() *entity.WebhookDelivery {
+	return &entity.WebhookDelivery{
+		ID:             m.ID,
+		SubscriptionID: m.SubscriptionID,
+		EventID:        m.EventID,
+		Event:          m.Event,
+		Payload:        m.Payload,
+		Status:         m.Status,
+		Attempts:       m.Attempts,
+		NextAttemptAt:  m.NextAttemptAt,
+		LastError:      m.LastError,
+		CreatedAt:      m.CreatedAt,
+		DeliveredAt:    m.DeliveredAt,
+		URL:            m.URL,
+		Secret:         m.Secret,
+	}
+}
+
+func (r *PostgreCompanyRepository) ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	var subscriptionModel []*WebhookSubscriptionModel
+	subscriptions := []*entity.WebhookSubscription{}
+
+	err := pgxscan.Select(ctx, r.conn, &subscriptionModel, `SELECT * FROM webhook_subscriptions ORDER BY ws_created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range subscriptionModel {
+		subscriptions = append(subscriptions, model.toEntity())
+	}
+	return subscriptions, nil
+}
+
+func (r *PostgreCompanyRepository) ReadWebhookSubscription(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	var subscriptionModel []*WebhookSubscriptionModel
+	err := pgxscan.Select(ctx, r.conn, &subscriptionModel, `SELECT * FROM webhook_subscriptions WHERE ws_id = $1`, id)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	if len(subscriptionModel) == 0 {
+		return nil, nil
+	}
+	return subscriptionModel[0].toEntity(), nil
+}
+
+// SaveWebhookSubscription creates the subscription or replaces the one with
+// the same ID
+func (r *PostgreCompanyRepository) SaveWebhookSubscription(ctx context.Context, subscription entity.WebhookSubscription) error {
+	_, err := r.conn.Exec(ctx, `INSERT INTO webhook_subscriptions(ws_id, ws_url, ws_secret, ws_events) values($1, $2, $3, $4)
+		ON CONFLICT (ws_id) DO UPDATE SET ws_url = EXCLUDED.ws_url, ws_secret = EXCLUDED.ws_secret, ws_events = EXCLUDED.ws_events, ws_updated_at = now()`,
+		subscription.ID, subscription.URL, subscription.Secret, subscription.Events)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// DeleteWebhookSubscription removes the subscription along with its
+// deliveries, telling whether it existed
+func (r *PostgreCompanyRepository) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.conn.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE ws_id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// ListPendingEvents returns up to limit events of the outbox not dispatched
+// yet, oldest first
+func (r *PostgreCompanyRepository) ListPendingEvents(ctx context.Context, limit int) ([]*entity.CompanyEvent, error) {
+	var eventModel []*WebhookEventModel
+	events := []*entity.CompanyEvent{}
+
+	err := pgxscan.Select(ctx, r.conn, &eventModel, `SELECT * FROM webhook_outbox WHERE wo_dispatched_at IS NULL ORDER BY wo_id LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range eventModel {
+		event := model.toEntity()
+		// the company is only recorded with created and updated events
+		if model.Company != nil {
+			if err := json.Unmarshal(model.Company, &event.Company); err != nil {
+				return nil, fmt.Errorf("error while reading the company of event %d: %w", model.ID, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// QueueWebhookDeliveries saves the deliveries of the events and marks the
+// events dispatched in a single transaction. A delivery already queued for
+// the same subscription and event is kept.
+func (r *PostgreCompanyRepository) QueueWebhookDeliveries(ctx context.Context, eventIDs []int64, deliveries []entity.WebhookDelivery) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for _, delivery := range deliveries {
+		_, err = tx.Exec(ctx, `INSERT INTO webhook_deliveries(wd_id, wd_subscription_id, wd_event_id, wd_event, wd_payload) values($1, $2, $3, $4, $5)
+			ON CONFLICT (wd_subscription_id, wd_event_id) DO NOTHING`,
+			delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.Event, []byte(delivery.Payload))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE webhook_outbox SET wo_dispatched_at = now() WHERE wo_id = ANY($1)`, eventIDs)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries that are due,
+// postponing them by lease so that no other dispatcher sends them meanwhile
+func (r *PostgreCompanyRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	var deliveryModel []*WebhookDeliveryModel
+	deliveries := []*entity.WebhookDelivery{}
+
+	err := pgxscan.Select(ctx, r.conn, &deliveryModel, `UPDATE webhook_deliveries d SET wd_next_attempt_at = now() + $2 * interval '1 millisecond'
+		FROM webhook_subscriptions s
+		WHERE s.ws_id = d.wd_subscription_id AND d.wd_id IN (SELECT wd_id FROM webhook_deliveries WHERE wd_status = 'pending' AND wd_next_attempt_at <= now() ORDER BY wd_next_attempt_at LIMIT $1 FOR UPDATE SKIP LOCKED)
+		RETURNING d.*, s.ws_url, s.ws_secret`, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range deliveryModel {
+		deliveries = append(deliveries, model.toEntity())
+	}
+	return deliveries, nil
+}
+
+// SaveWebhookDeliveryAttempt records the outcome of sending a delivery
+func (r *PostgreCompanyRepository) SaveWebhookDeliveryAttempt(ctx context.Context, delivery entity.WebhookDelivery) error {
+	_, err := r.conn.Exec(ctx, `UPDATE webhook_deliveries SET wd_status = $2, wd_attempts = $3, wd_next_attempt_at = $4, wd_last_error = $5, wd_delivered_at = $6 WHERE wd_id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the deliveries of a subscription, newest
+// first, only those with the status when it is set
+func (r *PostgreCompanyRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string) ([]*entity.WebhookDelivery, error) {
+	var deliveryModel []*WebhookDeliveryModel
+	deliveries := []*entity.WebhookDelivery{}
+
+	err := pgxscan.Select(ctx, r.conn, &deliveryModel, `SELECT * FROM webhook_deliveries WHERE wd_subscription_id = $1 AND ($2 = '' OR wd_status = $2) ORDER BY wd_created_at DESC`, subscriptionID, status)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range deliveryModel {
+		deliveries = append(deliveries, model.toEntity())
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery sends a dead delivery again from its first attempt,
+// telling whether there was a dead delivery with the ID
+func (r *PostgreCompanyRepository) RetryWebhookDelivery(ctx context.Context, id uuid.UUID) (bool, error) {
+	tag, err := r.conn.Exec(ctx, `UPDATE webhook_deliveries SET wd_status = 'pending', wd_attempts = 0, wd_next_attempt_at = now(), wd_last_error = '' WHERE wd_id = $1 AND wd_status = 'dead'`, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
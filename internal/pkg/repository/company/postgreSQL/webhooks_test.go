@@ -0,0 +1,152 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestListWebhookSubscriptions(t *testing.T) {
+	want := &entity.WebhookSubscription{ID: uuid.New(), URL: "https://crm.example.com/hooks", Secret: "secret", Events: []string{entity.EVENT_MERGED}}
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectQuery("SELECT (.+) FROM webhook_subscriptions ORDER BY ws_created_at").
+		WillReturnRows(mock.NewRows([]string{"ws_id", "ws_url", "ws_secret", "ws_events"}).
+			AddRow(want.ID, want.URL, want.Secret, want.Events))
+
+	repository := NewPostgreCompanyRepository(mock)
+	got, err := repository.ListWebhookSubscriptions(context.Background())
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(want, got[0]) {
+		t.Errorf("got %v want %v", got, want)
+	}
+}
+
+func TestListPendingEvents(t *testing.T) {
+	t.Run("with the company of the event", func(t *testing.T) {
+		company := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Country: entity.COUNTRY_US, Attributes: map[string]string{"phone": "555-0100"}, Version: 3}
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT (.+) FROM webhook_outbox`).
+			WithArgs(100).
+			WillReturnRows(mock.NewRows([]string{"wo_id", "wo_event", "wo_company_id", "wo_changed_fields", "wo_company"}).
+				AddRow(int64(8), entity.EVENT_UPDATED, company.ID, []string{entity.FIELD_NAME}, []byte(`{"_id":"`+company.ID.String()+`","name":"COMPANY","zipCode":"12345","country":"US","attributes":{"phone":"555-0100"},"version":3,"createdAt":"0001-01-01T00:00:00Z","updatedAt":"0001-01-01T00:00:00Z"}`)))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListPendingEvents(context.Background(), 100)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(company, got[0].Company) {
+			t.Errorf("got %+v want %+v", got, company)
+		}
+	})
+
+
+	survivor := uuid.New()
+	event := &entity.CompanyEvent{ID: 7, Event: entity.EVENT_MERGED, CompanyID: uuid.New(), MergedInto: &survivor, ChangedFields: []string{}, OccurredAt: time.Now()}
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectQuery(`SELECT (.+) FROM webhook_outbox WHERE wo_dispatched_at IS NULL ORDER BY wo_id LIMIT \$1`).
+		WithArgs(100).
+		WillReturnRows(mock.NewRows([]string{"wo_id", "wo_event", "wo_company_id", "wo_merged_into", "wo_changed_fields", "wo_occurred_at"}).
+			AddRow(event.ID, event.Event, event.CompanyID, event.MergedInto, event.ChangedFields, event.OccurredAt))
+
+	repository := NewPostgreCompanyRepository(mock)
+	got, err := repository.ListPendingEvents(context.Background(), 100)
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if len(got) != 1 || !reflect.DeepEqual(event, got[0]) {
+		t.Errorf("got %v want %v", got, event)
+	}
+}
+
+func TestQueueWebhookDeliveries(t *testing.T) {
+	delivery := entity.WebhookDelivery{ID: uuid.New(), SubscriptionID: uuid.New(), EventID: 7, Event: entity.EVENT_CREATED, Payload: []byte(`{"eventId":7}`)}
+
+	t.Run("in a transaction", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO webhook_deliveries").
+			WithArgs(delivery.ID, delivery.SubscriptionID, delivery.EventID, delivery.Event, []byte(delivery.Payload)).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectExec(`UPDATE webhook_outbox SET wo_dispatched_at = now\(\) WHERE wo_id = ANY\(\$1\)`).
+			WithArgs([]int64{7, 8}).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.QueueWebhookDeliveries(context.Background(), []int64{7, 8}, []entity.WebhookDelivery{delivery})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("rolling back", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO webhook_deliveries").WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.QueueWebhookDeliveries(context.Background(), []int64{7}, []entity.WebhookDelivery{delivery})
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	id := uuid.New()
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectQuery(`UPDATE webhook_deliveries d SET wd_next_attempt_at = (.+) FOR UPDATE SKIP LOCKED\) RETURNING d.\*, s.ws_url, s.ws_secret`).
+		WithArgs(50, int64(60000)).
+		WillReturnRows(mock.NewRows([]string{"wd_id", "wd_event", "wd_payload", "wd_status", "wd_attempts", "ws_url", "ws_secret"}).
+			AddRow(id, entity.EVENT_UPDATED, []byte(`{}`), entity.DELIVERY_PENDING, 2, "https://crm.example.com/hooks", "secret"))
+
+	repository := NewPostgreCompanyRepository(mock)
+	got, err := repository.ClaimWebhookDeliveries(context.Background(), 50, time.Minute)
+
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	if len(got) != 1 || got[0].ID != id || got[0].Attempts != 2 || got[0].URL != "https://crm.example.com/hooks" || got[0].Secret != "secret" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	id := uuid.New()
+
+	mock, _ := pgxmock.NewConn()
+	mock.ExpectExec(`UPDATE webhook_deliveries SET wd_status = 'pending', (.+) WHERE wd_id = \$1 AND wd_status = 'dead'`).
+		WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	repository := NewPostgreCompanyRepository(mock)
+	retried, err := repository.RetryWebhookDelivery(context.Background(), id)
+
+	if err != nil || !retried {
+		t.Errorf("got %v, %v want true, nil", retried, err)
+	}
+}
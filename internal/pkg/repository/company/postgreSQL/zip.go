@@ -0,0 +1,132 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+// zipBatchSize is the number of zip codes written by each statement
+const zipBatchSize = 1000
+
+type ZipReferenceModel struct {
+	Zip       string  `db:"zr_zip"`
+	City      string  `db:"zr_city"`
+	State     string  `db:"zr_state"`
+	Latitude  float64 `db:"zr_latitude"`
+	Longitude float64 `db:"zr_longitude"`
+}
+
+func (m *ZipReferenceModel) toEntity() *entity.ZipReference {
+	return &entity.ZipReference{
+		Zip:       m.Zip,
+		City:      m.City,
+		State:     m.State,
+		Latitude:  m.Latitude,
+		Longitude: m.Longitude,
+	}
+}
+
+func (r *PostgreCompanyRepository) ListZipReferences(ctx context.Context) ([]*entity.ZipReference, error) {
+	var zipModel []*ZipReferenceModel
+	references := []*entity.ZipReference{}
+
+	err := pgxscan.Select(ctx, r.conn, &zipModel, `SELECT * FROM zip_reference_table ORDER BY zr_zip`)
+	if err != nil {
+		return nil, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range zipModel {
+		references = append(references, model.toEntity())
+	}
+	return references, nil
+}
+
+// SaveZipReferences inserts or replaces the zip codes in a single transaction,
+// writing them in batches of zipBatchSize
+func (r *PostgreCompanyRepository) SaveZipReferences(ctx context.Context, references []entity.ZipReference) (err error) {
+	tx, err := r.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback(ctx)
+		}
+	}()
+
+	for start := 0; start < len(references); start += zipBatchSize {
+		end := start + zipBatchSize
+		if end > len(references) {
+			end = len(references)
+		}
+
+		batch := references[start:end]
+		zips := make([]string, len(batch))
+		cities := make([]string, len(batch))
+		states := make([]string, len(batch))
+		latitudes := make([]float64, len(batch))
+		longitudes := make([]float64, len(batch))
+		for index, reference := range batch {
+			zips[index] = reference.Zip
+			cities[index] = reference.City
+			states[index] = reference.State
+			latitudes[index] = reference.Latitude
+			longitudes[index] = reference.Longitude
+		}
+
+		_, err = tx.Exec(ctx, `INSERT INTO zip_reference_table(zr_zip, zr_city, zr_state, zr_latitude, zr_longitude)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::float8[], $5::float8[])
+			ON CONFLICT (zr_zip) DO UPDATE SET zr_city = EXCLUDED.zr_city, zr_state = EXCLUDED.zr_state, zr_latitude = EXCLUDED.zr_latitude, zr_longitude = EXCLUDED.zr_longitude`,
+			zips, cities, states, latitudes, longitudes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+type NearbyCompanyModel struct {
+	CompanyModel
+	Distance float64 `db:"distance"`
+}
+
+// nearbyCompanies selects the companies within the radius of a point along
+// with their distance, shared by the page and its count. A bounding box
+// crossing the antimeridian has a minimum longitude greater than its maximum
+// and covers the longitudes east of the minimum and west of the maximum.
+const nearbyCompanies = `FROM companies_catalog_table c
+		JOIN (SELECT zr_zip, 3958.8 * 2 * asin(least(1, sqrt(power(sin(radians(zr_latitude - $1) / 2), 2) + cos(radians($1)) * cos(radians(zr_latitude)) * power(sin(radians(zr_longitude - $2) / 2), 2)))) AS distance
+			FROM zip_reference_table WHERE zr_latitude BETWEEN $3 AND $4
+				AND (zr_longitude BETWEEN $5 AND $6 OR ($5 > $6 AND (zr_longitude >= $5 OR zr_longitude <= $6)))) n ON n.zr_zip = c.cc_zip_base
+		WHERE n.distance <= $7 AND c.cc_country = 'US' AND c.cc_deleted_at IS NULL`
+
+// ListCompaniesNearby returns the page of companies whose zip code lies within
+// the radius, closest first, along with how many there are in total, also
+// past the last page. Distances are only computed for the zip codes inside
+// the bounding box of the query.
+func (r *PostgreCompanyRepository) ListCompaniesNearby(ctx context.Context, query entity.NearbyQuery) ([]*entity.NearbyCompany, int, error) {
+	var nearbyModel []*NearbyCompanyModel
+	companies := []*entity.NearbyCompany{}
+
+	err := pgxscan.Select(ctx, r.conn, &nearbyModel, `SELECT c.*, n.distance `+nearbyCompanies+` ORDER BY n.distance, c.cc_name, c.cc_company_id LIMIT $8 OFFSET $9`,
+		query.Latitude, query.Longitude, query.MinLatitude, query.MaxLatitude, query.MinLongitude, query.MaxLongitude, query.RadiusMiles, query.Limit, query.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	var total int
+	err = pgxscan.Get(ctx, r.conn, &total, `SELECT COUNT(*) `+nearbyCompanies,
+		query.Latitude, query.Longitude, query.MinLatitude, query.MaxLatitude, query.MinLongitude, query.MaxLongitude, query.RadiusMiles)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error while executing query: %w", err)
+	}
+
+	for _, model := range nearbyModel {
+		companies = append(companies, &entity.NearbyCompany{Companies: *model.toEntity(), DistanceMiles: model.Distance})
+	}
+	return companies, total, nil
+}
@@ -0,0 +1,161 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock"
+)
+
+func TestListZipReferences(t *testing.T) {
+	t.Run("with zip codes", func(t *testing.T) {
+		want := []*entity.ZipReference{{Zip: "12345", City: "CITY", State: "ST", Latitude: 30.5, Longitude: -97.25}}
+
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM zip_reference_table").
+			WillReturnRows(mock.NewRows([]string{"zr_zip", "zr_city", "zr_state", "zr_latitude", "zr_longitude"}).
+				AddRow("12345", "CITY", "ST", 30.5, -97.25))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, err := repository.ListZipReferences(context.Background())
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM zip_reference_table").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, err := repository.ListZipReferences(context.Background())
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
+
+func TestSaveZipReferences(t *testing.T) {
+	references := []entity.ZipReference{}
+	for index := 0; index < zipBatchSize+1; index++ {
+		references = append(references, entity.ZipReference{Zip: fmt.Sprintf("%05d", index), City: "CITY", State: "ST"})
+	}
+
+	t.Run("in batches", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO zip_reference_table").
+			WillReturnResult(pgxmock.NewResult("INSERT", zipBatchSize))
+		mock.ExpectExec("INSERT INTO zip_reference_table").
+			WithArgs([]string{"01000"}, []string{"CITY"}, []string{"ST"}, []float64{0}, []float64{0}).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+		mock.ExpectCommit()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveZipReferences(context.Background(), references)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectBegin()
+		mock.ExpectExec("INSERT INTO zip_reference_table").
+			WillReturnError(errors.New("error"))
+		mock.ExpectRollback()
+
+		repository := NewPostgreCompanyRepository(mock)
+		err := repository.SaveZipReferences(context.Background(), references)
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+}
+
+func TestListCompaniesNearby(t *testing.T) {
+	query := entity.NearbyQuery{Latitude: 29.5, Longitude: -98.5, RadiusMiles: 10, MinLatitude: 29.35, MaxLatitude: 29.65, MinLongitude: -98.67, MaxLongitude: -98.33, Limit: 50}
+
+	t.Run("with companies", func(t *testing.T) {
+		company := entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345"}
+		want := []*entity.NearbyCompany{{Companies: company, DistanceMiles: 2.5}}
+
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT c.\*, n.distance FROM companies_catalog_table c (.+) ORDER BY n.distance`).
+			WithArgs(query.Latitude, query.Longitude, query.MinLatitude, query.MaxLatitude, query.MinLongitude, query.MaxLongitude, query.RadiusMiles, query.Limit, query.Offset).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "distance"}).
+				AddRow(company.ID, company.Name, company.Zip, 2.5))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM companies_catalog_table c`).
+			WithArgs(query.Latitude, query.Longitude, query.MinLatitude, query.MaxLatitude, query.MinLongitude, query.MaxLongitude, query.RadiusMiles).
+			WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, total, err := repository.ListCompaniesNearby(context.Background(), query)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if total != 3 {
+			t.Errorf("got %v total want 3", total)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("past the last page", func(t *testing.T) {
+		pastLastPage := query
+		pastLastPage.Offset = 100
+
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery(`SELECT c.\*, n.distance FROM companies_catalog_table c`).
+			WillReturnRows(mock.NewRows([]string{"cc_company_id", "cc_name", "cc_zip", "distance"}))
+		mock.ExpectQuery(`SELECT COUNT\(\*\) FROM companies_catalog_table c`).
+			WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+
+		repository := NewPostgreCompanyRepository(mock)
+		got, total, err := repository.ListCompaniesNearby(context.Background(), pastLastPage)
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if total != 3 || len(got) != 0 {
+			t.Errorf("got %v, %v total want no company and 3", got, total)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("with_error", func(t *testing.T) {
+		mock, _ := pgxmock.NewConn()
+		mock.ExpectQuery("SELECT (.+) FROM companies_catalog_table").
+			WillReturnError(errors.New("error"))
+
+		repository := NewPostgreCompanyRepository(mock)
+		_, _, err := repository.ListCompaniesNearby(context.Background(), query)
+
+		if err == nil {
+			t.Errorf("got %v want error", err)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package company
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// Limits guarding against archives that decompress to far more than they weigh
+const (
+	MAX_DECOMPRESSED_SIZE = 512 << 20
+	MAX_ARCHIVE_ENTRIES   = 100
+)
+
+var (
+	ERR_NOT_VALID_ARCHIVE = errors.New("Error: archive is not valid")
+	ERR_ARCHIVE_TOO_LARGE = errors.New("Error: archive exceeds the decompression limits")
+)
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// sizeLimitedReader fails once more than remaining bytes were read, instead of
+// silently truncating the file like io.LimitReader
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining *int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > *r.remaining+1 {
+		p = p[:*r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	*r.remaining -= int64(n)
+	if *r.remaining < 0 {
+		return n, ERR_ARCHIVE_TOO_LARGE
+	}
+	return n, err
+}
+
+// Decompress returns the content of a gzip file, recognized by its magic
+// number, with its name without the .gz extension. Any other file is returned
+// as it is. The content read counts against the remaining budget, or one of
+// MAX_DECOMPRESSED_SIZE when none is given; a file that is not a gzip file
+// only counts against a given budget.
+func Decompress(file io.Reader, fileName string, remaining *int64) (io.Reader, string, error) {
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(gzipMagic))
+	if !bytes.Equal(magic, gzipMagic) {
+		if remaining != nil {
+			return &sizeLimitedReader{reader: buffered, remaining: remaining}, fileName, nil
+		}
+		return buffered, fileName, nil
+	}
+
+	decompressed, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fileName, fmt.Errorf("%w: %v", ERR_NOT_VALID_ARCHIVE, err)
+	}
+
+	if remaining == nil {
+		budget := int64(MAX_DECOMPRESSED_SIZE)
+		remaining = &budget
+	}
+	fileName = strings.TrimSuffix(strings.TrimSuffix(fileName, ".gz"), ".GZ")
+	return &sizeLimitedReader{reader: decompressed, remaining: remaining}, fileName, nil
+}
+
+// ArchiveFile is a file held by a zip archive
+type ArchiveFile struct {
+	Name string
+	file *zip.File
+	// remaining is the decompression budget shared by the files of the archive
+	remaining *int64
+}
+
+// Open returns the content of the file, decompressed as well when it is a
+// gzip file. What is read counts against the budget of the archive once
+// fully decompressed, so gzip files inside it cannot expand past it either
+func (f ArchiveFile) Open() (io.ReadCloser, error) {
+	file, err := f.file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ERR_NOT_VALID_ARCHIVE, err)
+	}
+
+	content, _, err := Decompress(file, f.Name, f.remaining)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{content, file}, nil
+}
+
+// ReadArchive lists the files of a zip archive, leaving out directories and
+// the metadata macOS adds. The archive may hold at most MAX_ARCHIVE_ENTRIES
+// files decompressing to MAX_DECOMPRESSED_SIZE in total.
+func ReadArchive(file io.Reader) ([]ArchiveFile, error) {
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ERR_NOT_VALID_ARCHIVE, err)
+	}
+
+	remaining := int64(MAX_DECOMPRESSED_SIZE)
+	declared := uint64(0)
+	files := []ArchiveFile{}
+	for _, entry := range archive.File {
+		name := entry.Name
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+
+		files = append(files, ArchiveFile{Name: name, file: entry, remaining: &remaining})
+		if len(files) > MAX_ARCHIVE_ENTRIES {
+			return nil, fmt.Errorf("%w: more than %d files", ERR_ARCHIVE_TOO_LARGE, MAX_ARCHIVE_ENTRIES)
+		}
+
+		declared += entry.UncompressedSize64
+		if declared > MAX_DECOMPRESSED_SIZE {
+			return nil, fmt.Errorf("%w: more than %d bytes once decompressed", ERR_ARCHIVE_TOO_LARGE, MAX_DECOMPRESSED_SIZE)
+		}
+	}
+	return files, nil
+}
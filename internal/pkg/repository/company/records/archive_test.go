@@ -0,0 +1,156 @@
+package company
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func gzipped(content string) []byte {
+	buffer := &bytes.Buffer{}
+	writer := gzip.NewWriter(buffer)
+	writer.Write([]byte(content))
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func zipped(files map[string][]byte) []byte {
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+	for name, content := range files {
+		part, _ := archive.Create(name)
+		part.Write(content)
+	}
+	archive.Close()
+	return buffer.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	t.Run("gzip file", func(t *testing.T) {
+		reader, name, err := Decompress(bytes.NewReader(gzipped("name;addresszip\ntola;78229")), "clients.csv.gz", nil)
+		content, _ := ioutil.ReadAll(reader)
+
+		if err != nil || name != "clients.csv" || string(content) != "name;addresszip\ntola;78229" {
+			t.Errorf("got %q, %v, %v", content, name, err)
+		}
+	})
+
+	t.Run("plain file", func(t *testing.T) {
+		reader, name, err := Decompress(strings.NewReader("name;addresszip"), "clients.csv", nil)
+		content, _ := ioutil.ReadAll(reader)
+
+		if err != nil || name != "clients.csv" || string(content) != "name;addresszip" {
+			t.Errorf("got %q, %v, %v", content, name, err)
+		}
+	})
+
+	t.Run("within a budget", func(t *testing.T) {
+		remaining := int64(20)
+		reader, _, _ := Decompress(bytes.NewReader(gzipped("name;addresszip\ntola;78229")), "clients.csv.gz", &remaining)
+		if _, err := ioutil.ReadAll(reader); !errors.Is(err, ERR_ARCHIVE_TOO_LARGE) {
+			t.Errorf("got %v error want %v", err, ERR_ARCHIVE_TOO_LARGE)
+		}
+
+		remaining = 10
+		reader, _, _ = Decompress(strings.NewReader("name;addresszip"), "clients.csv", &remaining)
+		if _, err := ioutil.ReadAll(reader); !errors.Is(err, ERR_ARCHIVE_TOO_LARGE) {
+			t.Errorf("got %v error want %v", err, ERR_ARCHIVE_TOO_LARGE)
+		}
+	})
+
+	t.Run("corrupted gzip file", func(t *testing.T) {
+		_, _, err := Decompress(bytes.NewReader(gzipMagic), "clients.csv.gz", nil)
+
+		if !errors.Is(err, ERR_NOT_VALID_ARCHIVE) {
+			t.Errorf("got %v error want %v", err, ERR_NOT_VALID_ARCHIVE)
+		}
+	})
+}
+
+func TestSizeLimitedReader(t *testing.T) {
+	remaining := int64(10)
+	reader := &sizeLimitedReader{reader: strings.NewReader(strings.Repeat("a", 10)), remaining: &remaining}
+	if content, err := ioutil.ReadAll(reader); err != nil || len(content) != 10 {
+		t.Errorf("got %v bytes, %v error want 10 bytes", len(content), err)
+	}
+
+	remaining = 10
+	reader = &sizeLimitedReader{reader: strings.NewReader(strings.Repeat("a", 11)), remaining: &remaining}
+	if _, err := ioutil.ReadAll(reader); !errors.Is(err, ERR_ARCHIVE_TOO_LARGE) {
+		t.Errorf("got %v error want %v", err, ERR_ARCHIVE_TOO_LARGE)
+	}
+}
+
+func TestReadArchive(t *testing.T) {
+	t.Run("files of the archive", func(t *testing.T) {
+		archive := zipped(map[string][]byte{
+			"january.csv":        []byte("name;addresszip\ntola;78229"),
+			"february.csv.gz":    gzipped("name;addresszip\nmaple;94002"),
+			"__MACOSX/._january": []byte("metadata"),
+			"reports/.DS_Store":  []byte("metadata"),
+		})
+
+		files, err := ReadArchive(bytes.NewReader(archive))
+
+		if err != nil || len(files) != 2 {
+			t.Fatalf("got %v files, %v error want 2 files", len(files), err)
+		}
+		for _, file := range files {
+			reader, err := file.Open()
+			if err != nil {
+				t.Fatalf("got %v error, it should be nil", err)
+			}
+			content, _ := ioutil.ReadAll(reader)
+			reader.Close()
+			if !strings.HasPrefix(string(content), "name;addresszip\n") {
+				t.Errorf("got %q from %v", content, file.Name)
+			}
+		}
+	})
+
+	t.Run("gzip files sharing the budget of the archive", func(t *testing.T) {
+		content := "name;addresszip\n" + strings.Repeat("tola;78229\n", 10)
+		archive := zipped(map[string][]byte{"january.csv.gz": gzipped(content), "february.csv.gz": gzipped(content)})
+
+		files, err := ReadArchive(bytes.NewReader(archive))
+		if err != nil || len(files) != 2 {
+			t.Fatalf("got %v files, %v error want 2 files", len(files), err)
+		}
+		// room for the first file once decompressed, not for the second
+		*files[0].remaining = int64(len(content) + len(content)/2)
+
+		for index, file := range files {
+			reader, _ := file.Open()
+			_, err := ioutil.ReadAll(reader)
+			reader.Close()
+			if index == 0 && err != nil {
+				t.Errorf("got %v error, it should be nil", err)
+			}
+			if index == 1 && !errors.Is(err, ERR_ARCHIVE_TOO_LARGE) {
+				t.Errorf("got %v error want %v", err, ERR_ARCHIVE_TOO_LARGE)
+			}
+		}
+	})
+
+	t.Run("too many files", func(t *testing.T) {
+		files := map[string][]byte{}
+		for index := 0; index <= MAX_ARCHIVE_ENTRIES; index++ {
+			files[fmt.Sprintf("file%d.csv", index)] = []byte("name")
+		}
+
+		if _, err := ReadArchive(bytes.NewReader(zipped(files))); !errors.Is(err, ERR_ARCHIVE_TOO_LARGE) {
+			t.Errorf("got %v error want %v", err, ERR_ARCHIVE_TOO_LARGE)
+		}
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		if _, err := ReadArchive(strings.NewReader("name;addresszip")); !errors.Is(err, ERR_NOT_VALID_ARCHIVE) {
+			t.Errorf("got %v error want %v", err, ERR_NOT_VALID_ARCHIVE)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package company
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Formats of the files the merge reads
+const (
+	FORMAT_CSV    = "csv"
+	FORMAT_JSON   = "json"
+	FORMAT_NDJSON = "ndjson"
+	FORMAT_XLSX   = "xlsx"
+	FORMAT_ZIP    = "zip"
+)
+
+var (
+	ERR_UNKNOWN_FORMAT    = errors.New("Error: unknown file format")
+	ERR_NOT_VALID_RECORDS = errors.New("Error: records are not valid")
+)
+
+// coreKeys are the keys of a JSON record read first, in the order the CSV
+// layout has its core columns
+var coreKeys = []string{"name", "zipCode", "website"}
+
+// RecordReader reads the records of a file as rows, the first one holding the
+// headers, so every format goes through the same mapping as the CSV files
+type RecordReader interface {
+	ReadRecords(file io.Reader) ([][]string, error)
+}
+
+// ReaderOptions are the settings of the readers: Comma separates the columns
+// of CSV files and Sheet names the sheet read from a workbook
+type ReaderOptions struct {
+	Comma rune
+	Sheet string
+}
+
+// NewRecordReader returns the reader of the format
+func NewRecordReader(format string, options ReaderOptions) (RecordReader, error) {
+	switch format {
+	case FORMAT_CSV, "":
+		return &CSVRecordReader{Comma: options.Comma}, nil
+	case FORMAT_JSON:
+		return &JSONRecordReader{}, nil
+	case FORMAT_NDJSON:
+		return &JSONRecordReader{Lines: true}, nil
+	case FORMAT_XLSX:
+		return &XLSXRecordReader{Sheet: options.Sheet}, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ERR_UNKNOWN_FORMAT, format)
+}
+
+// DetectFormat tells the format of a file from its content type, then from the
+// extension of its name, CSV when neither is known
+func DetectFormat(contentType string, fileName string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return FORMAT_JSON
+	case "application/x-ndjson", "application/ndjson", "application/jsonlines", "application/x-jsonlines":
+		return FORMAT_NDJSON
+	case "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet":
+		return FORMAT_XLSX
+	case "application/zip", "application/x-zip-compressed":
+		return FORMAT_ZIP
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".json":
+		return FORMAT_JSON
+	case ".ndjson", ".jsonl":
+		return FORMAT_NDJSON
+	case ".xlsx":
+		return FORMAT_XLSX
+	case ".zip":
+		return FORMAT_ZIP
+	}
+	return FORMAT_CSV
+}
+
+type CSVRecordReader struct {
+	Comma rune
+}
+
+func (reader *CSVRecordReader) ReadRecords(file io.Reader) ([][]string, error) {
+	csvReader := csv.NewReader(file)
+	csvReader.Comma = reader.Comma
+	if csvReader.Comma == 0 {
+		csvReader.Comma = ';'
+	}
+	return csvReader.ReadAll()
+}
+
+// JSONRecordReader reads a JSON array of company records or, with Lines, one
+// record per line. The keys of the records are the headers: name, zipCode and
+// website come first and the keys of an "attributes" object are read as
+// columns of their own.
+type JSONRecordReader struct {
+	Lines bool
+}
+
+func (reader *JSONRecordReader) ReadRecords(file io.Reader) ([][]string, error) {
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+
+	if !reader.Lines {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ERR_NOT_VALID_RECORDS, err)
+		}
+		if delim, ok := token.(json.Delim); !ok || delim != '[' {
+			return nil, fmt.Errorf("%w: the file must hold an array of records", ERR_NOT_VALID_RECORDS)
+		}
+	}
+
+	records := []map[string]string{}
+	for index := 1; ; index++ {
+		if !reader.Lines && !decoder.More() {
+			break
+		}
+
+		var object map[string]interface{}
+		err := decoder.Decode(&object)
+		if reader.Lines && err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ERR_NOT_VALID_RECORDS, index, err)
+		}
+
+		record, err := flattenRecord(object)
+		if err != nil {
+			return nil, fmt.Errorf("%w: record %d: %v", ERR_NOT_VALID_RECORDS, index, err)
+		}
+		records = append(records, record)
+	}
+
+	if !reader.Lines {
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ERR_NOT_VALID_RECORDS, err)
+		}
+	}
+
+	if len(records) == 0 {
+		return nil, nil
+	}
+	return recordRows(records), nil
+}
+
+// flattenRecord turns the values of a record into strings, reading the keys of
+// its attributes object as keys of the record
+func flattenRecord(object map[string]interface{}) (map[string]string, error) {
+	record := map[string]string{}
+	for key, value := range object {
+		if attributes, ok := value.(map[string]interface{}); ok && key == "attributes" {
+			for name, value := range attributes {
+				text, err := scalarText(name, value)
+				if err != nil {
+					return nil, err
+				}
+				record[name] = text
+			}
+			continue
+		}
+
+		text, err := scalarText(key, value)
+		if err != nil {
+			return nil, err
+		}
+		record[key] = text
+	}
+	return record, nil
+}
+
+func scalarText(key string, value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+	return "", fmt.Errorf("%q must be a string, a number or a boolean", key)
+}
+
+// recordRows lays the records out as rows: the core keys, then every other
+// key in alphabetical order
+func recordRows(records []map[string]string) [][]string {
+	others := map[string]bool{}
+	for _, record := range records {
+		for key := range record {
+			others[key] = true
+		}
+	}
+	for _, key := range coreKeys {
+		delete(others, key)
+	}
+
+	extra := []string{}
+	for key := range others {
+		extra = append(extra, key)
+	}
+	sort.Strings(extra)
+	headers := append(append([]string{}, coreKeys...), extra...)
+
+	rows := [][]string{headers}
+	for _, record := range records {
+		row := make([]string, len(headers))
+		for index, header := range headers {
+			row[index] = record[header]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
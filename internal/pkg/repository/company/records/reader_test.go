@@ -0,0 +1,114 @@
+package company
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		contentType string
+		fileName    string
+		want        string
+	}{
+		{"application/json; charset=utf-8", "", FORMAT_JSON},
+		{"application/x-ndjson", "", FORMAT_NDJSON},
+		{"application/octet-stream", "partner.jsonl", FORMAT_NDJSON},
+		{"", "partner.JSON", FORMAT_JSON},
+		{"text/csv", "partner.csv", FORMAT_CSV},
+		{"", "partner", FORMAT_CSV},
+		{"application/octet-stream", "Clients 2022.XLSX", FORMAT_XLSX},
+	}
+
+	for _, test := range tests {
+		if got := DetectFormat(test.contentType, test.fileName); got != test.want {
+			t.Errorf("got %v want %v for %q, %q", got, test.want, test.contentType, test.fileName)
+		}
+	}
+}
+
+func TestNewRecordReader(t *testing.T) {
+	if _, err := NewRecordReader("xml", ReaderOptions{}); !errors.Is(err, ERR_UNKNOWN_FORMAT) {
+		t.Errorf("got %v error want %v", err, ERR_UNKNOWN_FORMAT)
+	}
+}
+
+func TestReadRecords(t *testing.T) {
+	want := [][]string{
+		{"name", "zipCode", "website", "country", "employees", "externalId", "phone"},
+		{"tola sales group", "78229", "http://repsources.com", "", "12", "C-1001", "210-555-0100"},
+		{"maple supply", "K1A 0B1", "", "CA", "", "", ""},
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		file   string
+	}{
+		{"json", FORMAT_JSON, `[
+			{"name": "tola sales group", "zipCode": "78229", "website": "http://repsources.com", "externalId": "C-1001", "attributes": {"phone": "210-555-0100", "employees": 12}},
+			{"name": "maple supply", "zipCode": "K1A 0B1", "country": "CA", "website": null}
+		]`},
+		{"ndjson", FORMAT_NDJSON, `{"name": "tola sales group", "zipCode": "78229", "website": "http://repsources.com", "externalId": "C-1001", "phone": "210-555-0100", "employees": 12}
+
+{"name": "maple supply", "zipCode": "K1A 0B1", "country": "CA"}
+`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, _ := NewRecordReader(test.format, ReaderOptions{})
+
+			got, err := reader.ReadRecords(strings.NewReader(test.file))
+
+			if err != nil {
+				t.Errorf("got %v error, it should be nil", err)
+			}
+			if !reflect.DeepEqual(want, got) {
+				t.Errorf("got %v want %v", got, want)
+			}
+		})
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		reader, _ := NewRecordReader(FORMAT_CSV, ReaderOptions{Comma: '|'})
+
+		got, err := reader.ReadRecords(strings.NewReader("name|zip\ntola sales group|78229"))
+
+		if err != nil || !reflect.DeepEqual(got, [][]string{{"name", "zip"}, {"tola sales group", "78229"}}) {
+			t.Errorf("got %v, %v", got, err)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		tests := []struct {
+			format string
+			file   string
+		}{
+			{FORMAT_JSON, `{"name": "tola sales group"}`},
+			{FORMAT_JSON, `[{"name": "tola sales group"}`},
+			{FORMAT_JSON, `[{"name": ["tola", "sales"]}]`},
+			{FORMAT_NDJSON, "{\"name\": \"tola sales group\"}\n{\"name\": "},
+			{FORMAT_NDJSON, `{"name": "tola", "attributes": {"address": {"city": "SAN ANTONIO"}}}`},
+		}
+		for _, test := range tests {
+			reader, _ := NewRecordReader(test.format, ReaderOptions{})
+
+			if _, err := reader.ReadRecords(strings.NewReader(test.file)); !errors.Is(err, ERR_NOT_VALID_RECORDS) {
+				t.Errorf("got %v error want %v for %v", err, ERR_NOT_VALID_RECORDS, test.file)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		reader, _ := NewRecordReader(FORMAT_JSON, ReaderOptions{})
+
+		got, err := reader.ReadRecords(strings.NewReader(`[]`))
+
+		if err != nil || len(got) != 0 {
+			t.Errorf("got %v, %v want no rows", got, err)
+		}
+	})
+}
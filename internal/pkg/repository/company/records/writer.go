@@ -0,0 +1,156 @@
+package company
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// FORMAT_PARQUET is only written, by the export
+const FORMAT_PARQUET = "parquet"
+
+// PARQUET_ROW_GROUP_SIZE bounds the rows the Parquet writer holds in memory
+// before flushing them as a row group
+const PARQUET_ROW_GROUP_SIZE = 16 << 20
+
+// CSVHeaders are the headers of the core columns the CSV files are read and
+// written with
+var CSVHeaders = []string{"name", "addresszip", "website", "country"}
+
+// RecordWriter writes companies one at a time; Close flushes what it holds
+// and ends the file, without closing the underlying writer
+type RecordWriter interface {
+	Write(company *entity.Companies) error
+	Close() error
+}
+
+// NewRecordWriter returns the writer of the format. CSV files get a column
+// per attribute, in the given order, after the core columns.
+func NewRecordWriter(format string, file io.Writer, attributes []string) (RecordWriter, error) {
+	switch format {
+	case FORMAT_CSV:
+		return newCSVRecordWriter(file, attributes)
+	case FORMAT_NDJSON:
+		return &NDJSONRecordWriter{encoder: json.NewEncoder(file)}, nil
+	case FORMAT_PARQUET:
+		return newParquetRecordWriter(file)
+	}
+	return nil, fmt.Errorf("%w: %q", ERR_UNKNOWN_FORMAT, format)
+}
+
+// CSVRecordWriter writes companies in the layout the merge reads, so an
+// exported file can be merged back
+type CSVRecordWriter struct {
+	writer     *csv.Writer
+	attributes []string
+}
+
+func newCSVRecordWriter(file io.Writer, attributes []string) (*CSVRecordWriter, error) {
+	csvWriter := csv.NewWriter(file)
+	csvWriter.Comma = ';'
+
+	err := csvWriter.Write(append(append([]string{}, CSVHeaders...), attributes...))
+	if err != nil {
+		return nil, err
+	}
+	return &CSVRecordWriter{writer: csvWriter, attributes: attributes}, nil
+}
+
+func (w *CSVRecordWriter) Write(company *entity.Companies) error {
+	row := []string{company.Name, company.Zip, company.Website, company.Country}
+	for _, attribute := range w.attributes {
+		row = append(row, company.Attributes[attribute])
+	}
+	return w.writer.Write(row)
+}
+
+func (w *CSVRecordWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// NDJSONRecordWriter writes a company per line, as the API returns them
+type NDJSONRecordWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *NDJSONRecordWriter) Write(company *entity.Companies) error {
+	return w.encoder.Encode(company)
+}
+
+func (w *NDJSONRecordWriter) Close() error {
+	return nil
+}
+
+type parquetCompany struct {
+	ID         string            `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ParentID   string            `parquet:"name=parent_id, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Name       string            `parquet:"name=name, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Zip        string            `parquet:"name=zip_code, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Country    string            `parquet:"name=country, type=BYTE_ARRAY, convertedtype=UTF8"`
+	City       string            `parquet:"name=city, type=BYTE_ARRAY, convertedtype=UTF8"`
+	State      string            `parquet:"name=state, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ZipUnknown bool              `parquet:"name=zip_unknown, type=BOOLEAN"`
+	Website    string            `parquet:"name=website, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Domain     string            `parquet:"name=domain, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Attributes map[string]string `parquet:"name=attributes, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	DeletedAt  *int64            `parquet:"name=deleted_at, type=INT64, convertedtype=TIMESTAMP_MILLIS, repetitiontype=OPTIONAL"`
+	CreatedAt  int64             `parquet:"name=created_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	UpdatedAt  int64             `parquet:"name=updated_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	Version    int32             `parquet:"name=version, type=INT32"`
+}
+
+// ParquetRecordWriter writes the companies as the rows of a Parquet file,
+// compressed with snappy
+type ParquetRecordWriter struct {
+	writer *writer.ParquetWriter
+}
+
+func newParquetRecordWriter(file io.Writer) (*ParquetRecordWriter, error) {
+	parquetWriter, err := writer.NewParquetWriterFromWriter(file, new(parquetCompany), 1)
+	if err != nil {
+		return nil, err
+	}
+	parquetWriter.RowGroupSize = PARQUET_ROW_GROUP_SIZE
+	parquetWriter.CompressionType = parquet.CompressionCodec_SNAPPY
+	return &ParquetRecordWriter{writer: parquetWriter}, nil
+}
+
+func (w *ParquetRecordWriter) Write(company *entity.Companies) error {
+	row := parquetCompany{
+		ID:         company.ID.String(),
+		Name:       company.Name,
+		Zip:        company.Zip,
+		Country:    company.Country,
+		City:       company.City,
+		State:      company.State,
+		ZipUnknown: company.ZipUnknown,
+		Website:    company.Website,
+		Domain:     company.Domain,
+		Attributes: company.Attributes,
+		CreatedAt:  company.CreatedAt.UnixMilli(),
+		UpdatedAt:  company.UpdatedAt.UnixMilli(),
+		Version:    int32(company.Version),
+	}
+	if company.ParentID != uuid.Nil {
+		row.ParentID = company.ParentID.String()
+	}
+	if row.Attributes == nil {
+		row.Attributes = map[string]string{}
+	}
+	if company.DeletedAt != nil {
+		deletedAt := company.DeletedAt.UnixMilli()
+		row.DeletedAt = &deletedAt
+	}
+	return w.writer.Write(row)
+}
+
+func (w *ParquetRecordWriter) Close() error {
+	return w.writer.WriteStop()
+}
@@ -0,0 +1,100 @@
+package company
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+)
+
+var exported = []*entity.Companies{
+	{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "78229", Country: entity.COUNTRY_US, Website: "http://repsources.com", Attributes: map[string]string{"phone": "210-555-0100"}, CreatedAt: time.Date(2022, 7, 25, 9, 0, 0, 0, time.UTC), Version: 2},
+	{ID: uuid.New(), Name: "MAPLE; SUPPLY", Zip: "K1A 0B1", Country: entity.COUNTRY_CA},
+}
+
+func writeAll(t *testing.T, format string, attributes []string) []byte {
+	file := &bytes.Buffer{}
+	recordWriter, err := NewRecordWriter(format, file, attributes)
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	for _, company := range exported {
+		if err := recordWriter.Write(company); err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+	}
+	if err := recordWriter.Close(); err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	return file.Bytes()
+}
+
+func TestCSVRecordWriter(t *testing.T) {
+	file := writeAll(t, FORMAT_CSV, []string{"phone"})
+
+	got, err := (&CSVRecordReader{}).ReadRecords(bytes.NewReader(file))
+	want := [][]string{
+		{"name", "addresszip", "website", "country", "phone"},
+		{"TOLA SALES GROUP", "78229", "http://repsources.com", "US", "210-555-0100"},
+		{"MAPLE; SUPPLY", "K1A 0B1", "", "CA", ""},
+	}
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("got %q want %q", got, want)
+	}
+}
+
+func TestNDJSONRecordWriter(t *testing.T) {
+	file := writeAll(t, FORMAT_NDJSON, nil)
+
+	got, err := (&JSONRecordReader{Lines: true}).ReadRecords(bytes.NewReader(file))
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if len(got) != 3 || got[1][0] != "TOLA SALES GROUP" || got[2][1] != "K1A 0B1" {
+		t.Errorf("got %q", got)
+	}
+	if lines := strings.Count(string(file), "\n"); lines != 2 {
+		t.Errorf("got %v lines want 2", lines)
+	}
+}
+
+func TestParquetRecordWriter(t *testing.T) {
+	file := writeAll(t, FORMAT_PARQUET, nil)
+
+	parquetFile, _ := buffer.NewBufferFile(file)
+	parquetReader, err := reader.NewParquetReader(parquetFile, new(parquetCompany), 1)
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	defer parquetReader.ReadStop()
+
+	rows := make([]parquetCompany, parquetReader.GetNumRows())
+	if err := parquetReader.Read(&rows); err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+
+	if len(rows) != 2 || rows[0].ID != exported[0].ID.String() || rows[0].Attributes["phone"] != "210-555-0100" || rows[0].Version != 2 {
+		t.Errorf("got %+v", rows)
+	}
+	if rows[0].CreatedAt != exported[0].CreatedAt.UnixMilli() || rows[1].Country != entity.COUNTRY_CA || rows[1].DeletedAt != nil {
+		t.Errorf("got %+v", rows)
+	}
+}
+
+func TestNewRecordWriter(t *testing.T) {
+	if _, err := NewRecordWriter(FORMAT_XLSX, &bytes.Buffer{}, nil); !errors.Is(err, ERR_UNKNOWN_FORMAT) {
+		t.Errorf("got %v error want %v", err, ERR_UNKNOWN_FORMAT)
+	}
+}
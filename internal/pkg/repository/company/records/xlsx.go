@@ -0,0 +1,337 @@
+package company
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// MAX_SPREADSHEET_PART_SIZE bounds each decompressed part of a spreadsheet
+const MAX_SPREADSHEET_PART_SIZE = 64 << 20
+
+var ERR_NOT_VALID_SPREADSHEET = errors.New("Error: spreadsheet is not valid")
+
+// XLSXRecordReader reads the rows of a sheet of an Excel workbook, the first
+// one when Sheet is not set. Rows before the header, such as a title, are
+// skipped; cells holding formulas and merged cells are refused since their
+// values would not be the ones the user sees. Numbers are read as their number
+// format shows them when it pads them with zeros, so zip codes formatted as
+// such keep their leading zeros.
+type XLSXRecordReader struct {
+	Sheet string
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		Name string `xml:"name,attr"`
+		ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxText struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (text xlsxText) String() string {
+	value := text.Text
+	for _, run := range text.Runs {
+		value += run.Text
+	}
+	return value
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxText `xml:"si"`
+}
+
+// xlsxStyles holds the number formats of the cells, a cell naming its format
+// in CellFormats by its style
+type xlsxStyles struct {
+	NumberFormats []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellFormats []struct {
+		NumberFormatID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+// formatCode returns the custom number format of a cell style, empty for the
+// built-in formats, none of which pads numbers with zeros
+func (styles xlsxStyles) formatCode(style string) string {
+	index, err := strconv.Atoi(style)
+	if err != nil || index < 0 || index >= len(styles.CellFormats) {
+		return ""
+	}
+	for _, format := range styles.NumberFormats {
+		if format.ID == styles.CellFormats[index].NumberFormatID {
+			return format.Code
+		}
+	}
+	return ""
+}
+
+type xlsxWorksheet struct {
+	Rows []struct {
+		Cells []struct {
+			Reference string    `xml:"r,attr"`
+			Type      string    `xml:"t,attr"`
+			Style     string    `xml:"s,attr"`
+			Formula   *struct{} `xml:"f"`
+			Value     string    `xml:"v"`
+			Inline    xlsxText  `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+	MergeCells []struct {
+		Reference string `xml:"ref,attr"`
+	} `xml:"mergeCells>mergeCell"`
+}
+
+func (reader *XLSXRecordReader) ReadRecords(file io.Reader) ([][]string, error) {
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ERR_NOT_VALID_SPREADSHEET, err)
+	}
+	parts := map[string]*zip.File{}
+	for _, part := range archive.File {
+		parts[part.Name] = part
+	}
+
+	sheetPath, err := reader.sheetPath(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	var sharedStrings xlsxSharedStrings
+	if _, ok := parts["xl/sharedStrings.xml"]; ok {
+		if err := decodePart(parts, "xl/sharedStrings.xml", &sharedStrings); err != nil {
+			return nil, err
+		}
+	}
+
+	var styles xlsxStyles
+	if _, ok := parts["xl/styles.xml"]; ok {
+		if err := decodePart(parts, "xl/styles.xml", &styles); err != nil {
+			return nil, err
+		}
+	}
+
+	var sheet xlsxWorksheet
+	if err := decodePart(parts, sheetPath, &sheet); err != nil {
+		return nil, err
+	}
+	if len(sheet.MergeCells) > 0 {
+		return nil, fmt.Errorf("%w: cells %s are merged, unmerge them before sending the file", ERR_NOT_VALID_SPREADSHEET, sheet.MergeCells[0].Reference)
+	}
+
+	rows := [][]string{}
+	header := false
+	for _, sheetRow := range sheet.Rows {
+		row := []string{}
+		filled, texts := 0, 0
+		for _, cell := range sheetRow.Cells {
+			if cell.Formula != nil {
+				return nil, fmt.Errorf("%w: cell %s holds a formula, paste its value instead", ERR_NOT_VALID_SPREADSHEET, cell.Reference)
+			}
+
+			column := len(row)
+			if cell.Reference != "" {
+				column, err = columnIndex(cell.Reference)
+				if err != nil {
+					return nil, err
+				}
+			}
+			for len(row) < column {
+				row = append(row, "")
+			}
+
+			value, err := cellValue(cell.Type, cell.Value, cell.Inline, sharedStrings)
+			if err != nil {
+				return nil, fmt.Errorf("%w: cell %s: %v", ERR_NOT_VALID_SPREADSHEET, cell.Reference, err)
+			}
+			if cell.Type == "" || cell.Type == "n" {
+				value = padNumber(value, styles.formatCode(cell.Style))
+			}
+			row = append(row, value)
+
+			if strings.TrimSpace(value) != "" {
+				filled++
+				if cell.Type == "s" || cell.Type == "inlineStr" || cell.Type == "str" {
+					texts++
+				}
+			}
+		}
+
+		// the header is the first row with several cells, all of them text
+		if !header {
+			if filled < 2 || texts < filled {
+				continue
+			}
+			header = true
+		}
+		if filled > 0 {
+			rows = append(rows, row)
+		}
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	return rows, nil
+}
+
+// sheetPath finds the part holding the sheet, following the relationships of
+// the workbook
+func (reader *XLSXRecordReader) sheetPath(parts map[string]*zip.File) (string, error) {
+	var workbook xlsxWorkbook
+	if err := decodePart(parts, "xl/workbook.xml", &workbook); err != nil {
+		return "", err
+	}
+	var relationships xlsxRelationships
+	if err := decodePart(parts, "xl/_rels/workbook.xml.rels", &relationships); err != nil {
+		return "", err
+	}
+
+	if len(workbook.Sheets) == 0 {
+		return "", fmt.Errorf("%w: the workbook has no sheet", ERR_NOT_VALID_SPREADSHEET)
+	}
+	sheet := workbook.Sheets[0]
+	if reader.Sheet != "" {
+		found := false
+		for _, candidate := range workbook.Sheets {
+			if strings.EqualFold(candidate.Name, reader.Sheet) {
+				sheet, found = candidate, true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("%w: there is no sheet named %q", ERR_NOT_VALID_SPREADSHEET, reader.Sheet)
+		}
+	}
+
+	for _, relationship := range relationships.Relationships {
+		if relationship.ID == sheet.ID {
+			if strings.HasPrefix(relationship.Target, "/") {
+				return strings.TrimPrefix(relationship.Target, "/"), nil
+			}
+			return path.Join("xl", relationship.Target), nil
+		}
+	}
+	return "", fmt.Errorf("%w: sheet %q has no part", ERR_NOT_VALID_SPREADSHEET, sheet.Name)
+}
+
+func decodePart(parts map[string]*zip.File, name string, value interface{}) error {
+	part, ok := parts[name]
+	if !ok {
+		return fmt.Errorf("%w: %s is missing", ERR_NOT_VALID_SPREADSHEET, name)
+	}
+
+	file, err := part.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ERR_NOT_VALID_SPREADSHEET, err)
+	}
+	defer file.Close()
+
+	content, err := ioutil.ReadAll(io.LimitReader(file, MAX_SPREADSHEET_PART_SIZE+1))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ERR_NOT_VALID_SPREADSHEET, err)
+	}
+	if len(content) > MAX_SPREADSHEET_PART_SIZE {
+		return fmt.Errorf("%w: %s is too large", ERR_NOT_VALID_SPREADSHEET, name)
+	}
+
+	if err := xml.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("%w: %s: %v", ERR_NOT_VALID_SPREADSHEET, name, err)
+	}
+	return nil
+}
+
+// MAX_SPREADSHEET_COLUMNS is the number of columns of a worksheet, the last
+// one being XFD
+const MAX_SPREADSHEET_COLUMNS = 16384
+
+// columnIndex reads the column of a cell reference, e.g. 2 for "C7"
+func columnIndex(reference string) (int, error) {
+	column := 0
+	for index, letter := range reference {
+		if letter >= 'A' && letter <= 'Z' {
+			column = column*26 + int(letter-'A') + 1
+			if column > MAX_SPREADSHEET_COLUMNS {
+				return 0, fmt.Errorf("%w: %q is past the last column", ERR_NOT_VALID_SPREADSHEET, reference)
+			}
+			continue
+		}
+		if index == 0 {
+			break
+		}
+		return column - 1, nil
+	}
+	return 0, fmt.Errorf("%w: %q is not a cell reference", ERR_NOT_VALID_SPREADSHEET, reference)
+}
+
+// padNumber formats a whole number with a format of zeros such as the zip
+// code formats 00000 and 00000-0000, e.g. 2134 as 02134. Other numbers and
+// formats leave the value as stored.
+func padNumber(value string, code string) string {
+	// literals of the format are escaped or quoted
+	code = strings.NewReplacer(`\`, "", `"`, "").Replace(code)
+	if code == "" || code[0] != '0' || strings.Trim(code, "0- ") != "" {
+		return value
+	}
+	if value == "" || strings.Trim(value, "0123456789") != "" || len(value) > strings.Count(code, "0") {
+		return value
+	}
+
+	padded := []byte(code)
+	digit := len(value) - 1
+	for index := len(padded) - 1; index >= 0; index-- {
+		if padded[index] != '0' {
+			continue
+		}
+		if digit >= 0 {
+			padded[index] = value[digit]
+			digit--
+		}
+	}
+	return string(padded)
+}
+
+func cellValue(cellType string, value string, inline xlsxText, sharedStrings xlsxSharedStrings) (string, error) {
+	switch cellType {
+	case "s":
+		var index int
+		if _, err := fmt.Sscanf(value, "%d", &index); err != nil || index < 0 || index >= len(sharedStrings.Items) {
+			return "", fmt.Errorf("unknown shared string %q", value)
+		}
+		return sharedStrings.Items[index].String(), nil
+	case "inlineStr":
+		return inline.String(), nil
+	case "b":
+		return fmt.Sprint(value == "1"), nil
+	case "e":
+		return "", fmt.Errorf("holds the error %s", value)
+	}
+	return value, nil
+}
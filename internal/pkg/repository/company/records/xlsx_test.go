@@ -0,0 +1,156 @@
+package company
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// newWorkbook zips a workbook holding one sheet per content, the sheets named
+// in order of the names
+func newWorkbook(names []string, sheets []string, sharedStrings []string) []byte {
+	return newStyledWorkbook(names, sheets, sharedStrings, "")
+}
+
+// newStyledWorkbook zips a workbook like newWorkbook, along with the styles
+// when given
+func newStyledWorkbook(names []string, sheets []string, sharedStrings []string, styles string) []byte {
+	buffer := &bytes.Buffer{}
+	archive := zip.NewWriter(buffer)
+	write := func(name string, content string) {
+		part, _ := archive.Create(name)
+		part.Write([]byte(content))
+	}
+	if styles != "" {
+		write("xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+styles+`</styleSheet>`)
+	}
+
+	workbook := `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`
+	relationships := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`
+	for index, name := range names {
+		workbook += fmt.Sprintf(`<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, name, index+1, index+1)
+		relationships += fmt.Sprintf(`<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, index+1, index+1)
+		write(fmt.Sprintf("xl/worksheets/sheet%d.xml", index+1), `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`+sheets[index]+`</worksheet>`)
+	}
+	write("xl/workbook.xml", workbook+`</sheets></workbook>`)
+	write("xl/_rels/workbook.xml.rels", relationships+`</Relationships>`)
+
+	strings := `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`
+	for _, value := range sharedStrings {
+		strings += `<si><t>` + value + `</t></si>`
+	}
+	write("xl/sharedStrings.xml", strings+`<si><r><t>tola </t></r><r><t>sales group</t></r></si></sst>`)
+
+	archive.Close()
+	return buffer.Bytes()
+}
+
+var clientsSheet = `<sheetData>
+	<row r="1"><c r="A1" t="inlineStr"><is><t>Clients of 2022</t></is></c></row>
+	<row r="3"><c r="A3" t="s"><v>0</v></c><c r="B3" t="s"><v>1</v></c><c r="C3" t="s"><v>2</v></c><c r="E3" t="s"><v>3</v></c></row>
+	<row r="4"><c r="A4" t="s"><v>4</v></c><c r="B4"><v>78229</v></c><c r="C4" t="str"><v>http://repsources.com</v></c><c r="E4" t="b"><v>1</v></c></row>
+	<row r="5"/>
+</sheetData>`
+
+var sheetStrings = []string{"name", "addresszip", "website", "Active"}
+
+func TestReadXLSXRecords(t *testing.T) {
+	t.Run("detecting the header", func(t *testing.T) {
+		file := newWorkbook([]string{"Clients"}, []string{clientsSheet}, sheetStrings)
+		reader, _ := NewRecordReader(FORMAT_XLSX, ReaderOptions{})
+
+		got, err := reader.ReadRecords(bytes.NewReader(file))
+		want := [][]string{
+			{"name", "addresszip", "website", "", "Active"},
+			{"tola sales group", "78229", "http://repsources.com", "", "true"},
+		}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("picking the sheet", func(t *testing.T) {
+		summary := `<sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>total</t></is></c><c r="B1"><v>1</v></c></row></sheetData>`
+		file := newWorkbook([]string{"Summary", "Clients"}, []string{summary, clientsSheet}, sheetStrings)
+		reader, _ := NewRecordReader(FORMAT_XLSX, ReaderOptions{Sheet: "clients"})
+
+		got, err := reader.ReadRecords(bytes.NewReader(file))
+
+		if err != nil || len(got) != 2 || got[1][0] != "tola sales group" {
+			t.Errorf("got %q, %v", got, err)
+		}
+	})
+
+	t.Run("numeric zip codes", func(t *testing.T) {
+		styles := `<numFmts count="2"><numFmt numFmtId="164" formatCode="00000"/><numFmt numFmtId="165" formatCode="00000\-0000"/></numFmts>
+			<cellXfs count="3"><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="165"/></cellXfs>`
+		sheet := `<sheetData>
+			<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+			<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2" s="1"><v>2134</v></c></row>
+			<row r="3"><c r="A3" t="s"><v>4</v></c><c r="B3" s="2"><v>21341234</v></c></row>
+			<row r="4"><c r="A4" t="s"><v>4</v></c><c r="B4" s="0"><v>2134</v></c></row>
+		</sheetData>`
+		file := newStyledWorkbook([]string{"Clients"}, []string{sheet}, sheetStrings, styles)
+		reader, _ := NewRecordReader(FORMAT_XLSX, ReaderOptions{})
+
+		got, err := reader.ReadRecords(bytes.NewReader(file))
+		want := [][]string{
+			{"name", "addresszip"},
+			{"tola sales group", "02134"},
+			{"tola sales group", "02134-1234"},
+			{"tola sales group", "2134"},
+		}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %q want %q", got, want)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			sheet string
+			file  []byte
+		}{
+			{"formula", "", newWorkbook([]string{"Clients"}, []string{`<sheetData>
+				<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>
+				<row r="2"><c r="A2" t="s"><v>4</v></c><c r="B2"><f>SUM(B3:B4)</f><v>78229</v></c></row>
+			</sheetData>`}, sheetStrings)},
+			{"merged cells", "", newWorkbook([]string{"Clients"}, []string{clientsSheet + `<mergeCells count="1"><mergeCell ref="A1:C1"/></mergeCells>`}, sheetStrings)},
+			{"unknown sheet", "Prospects", newWorkbook([]string{"Clients"}, []string{clientsSheet}, sheetStrings)},
+			{"not a workbook", "", []byte("name;addresszip;website")},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				reader, _ := NewRecordReader(FORMAT_XLSX, ReaderOptions{Sheet: test.sheet})
+
+				if _, err := reader.ReadRecords(bytes.NewReader(test.file)); !errors.Is(err, ERR_NOT_VALID_SPREADSHEET) {
+					t.Errorf("got %v error want %v", err, ERR_NOT_VALID_SPREADSHEET)
+				}
+			})
+		}
+	})
+}
+
+func TestColumnIndex(t *testing.T) {
+	for reference, want := range map[string]int{"A1": 0, "C7": 2, "Z10": 25, "AA3": 26, "AB12": 27, "XFD1": 16383} {
+		if got, err := columnIndex(reference); err != nil || got != want {
+			t.Errorf("got %v, %v want %v for %v", got, err, want, reference)
+		}
+	}
+	for _, reference := range []string{"12", "XFE1", "ZZZZZZ1", "ZZZZZZZZZZZZZZ1"} {
+		if _, err := columnIndex(reference); !errors.Is(err, ERR_NOT_VALID_SPREADSHEET) {
+			t.Errorf("got %v error want %v for %v", err, ERR_NOT_VALID_SPREADSHEET, reference)
+		}
+	}
+}
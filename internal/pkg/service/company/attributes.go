@@ -0,0 +1,218 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var (
+	ERR_UNKNOWN_ATTRIBUTE          = errors.New("Error: attribute is not registered")
+	ERR_NOT_VALID_ATTRIBUTE        = errors.New("Error: attribute value is not valid")
+	ERR_NOT_VALID_ATTRIBUTE_SCHEMA = errors.New("Error: attribute definition is not valid")
+)
+
+var attributeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// attributeSchema holds the registered attributes along with their compiled
+// patterns. It is shared by concurrent requests.
+type attributeSchema struct {
+	mutex       sync.RWMutex
+	definitions map[string]entity.AttributeDefinition
+	patterns    map[string]*regexp.Regexp
+}
+
+func newAttributeSchema() *attributeSchema {
+	return &attributeSchema{
+		definitions: map[string]entity.AttributeDefinition{},
+		patterns:    map[string]*regexp.Regexp{},
+	}
+}
+
+func (a *attributeSchema) register(definition entity.AttributeDefinition, pattern *regexp.Regexp) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.definitions[definition.Name] = definition
+	if pattern != nil {
+		a.patterns[definition.Name] = pattern
+	} else {
+		delete(a.patterns, definition.Name)
+	}
+}
+
+func (a *attributeSchema) lookup(name string) (entity.AttributeDefinition, *regexp.Regexp, bool) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	definition, ok := a.definitions[name]
+	return definition, a.patterns[name], ok
+}
+
+func (a *attributeSchema) names() []string {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+
+	names := []string{}
+	for name := range a.definitions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeAttributeName turns a source header such as "Industry Code" into
+// an attribute name such as "industry_code"
+func NormalizeAttributeName(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9')
+	}), "_")
+}
+
+func validateAttributeDefinition(definition entity.AttributeDefinition) (*regexp.Regexp, error) {
+	if !attributeNamePattern.MatchString(definition.Name) || isCoreField(definition.Name) || isLocationField(definition.Name) {
+		return nil, fmt.Errorf("%w: invalid name %q", ERR_NOT_VALID_ATTRIBUTE_SCHEMA, definition.Name)
+	}
+
+	switch definition.Type {
+	case entity.ATTRIBUTE_TYPE_STRING, entity.ATTRIBUTE_TYPE_INTEGER, entity.ATTRIBUTE_TYPE_NUMBER, entity.ATTRIBUTE_TYPE_BOOLEAN:
+	default:
+		return nil, fmt.Errorf("%w: invalid type %q", ERR_NOT_VALID_ATTRIBUTE_SCHEMA, definition.Type)
+	}
+
+	if definition.MergePolicy != "" && !isMergePolicy(MergePolicy(definition.MergePolicy)) {
+		return nil, fmt.Errorf("%w: invalid merge policy %q", ERR_NOT_VALID_ATTRIBUTE_SCHEMA, definition.MergePolicy)
+	}
+
+	if definition.Pattern == "" {
+		return nil, nil
+	}
+	pattern, err := regexp.Compile(definition.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid pattern: %v", ERR_NOT_VALID_ATTRIBUTE_SCHEMA, err)
+	}
+	return pattern, nil
+}
+
+// LoadAttributeSchema reads the registered attributes from the repository
+func (s *CompanyService) LoadAttributeSchema(ctx context.Context) error {
+	definitions, err := s.dbRepository.ListAttributeDefinitions(ctx)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	for _, definition := range definitions {
+		pattern, err := validateAttributeDefinition(*definition)
+		if err != nil {
+			return err
+		}
+		s.attributes.register(*definition, pattern)
+	}
+	return nil
+}
+
+// RegisterAttribute adds or replaces an attribute of the schema. Values of a
+// registered attribute are accepted and merged like any core field.
+func (s *CompanyService) RegisterAttribute(ctx context.Context, definition entity.AttributeDefinition) error {
+	if definition.Type == "" {
+		definition.Type = entity.ATTRIBUTE_TYPE_STRING
+	}
+
+	pattern, err := validateAttributeDefinition(definition)
+	if err != nil {
+		return err
+	}
+
+	err = s.dbRepository.SaveAttributeDefinition(ctx, definition)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	s.attributes.register(definition, pattern)
+	return nil
+}
+
+// AttributeSchema lists the registered attributes ordered by name
+func (s *CompanyService) AttributeSchema() []entity.AttributeDefinition {
+	definitions := []entity.AttributeDefinition{}
+	for _, name := range s.attributes.names() {
+		definition, _, _ := s.attributes.lookup(name)
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// NormalizeAttributes trims the values and names of the attributes, dropping
+// the empty ones
+func NormalizeAttributes(attributes map[string]string) map[string]string {
+	if attributes == nil {
+		return nil
+	}
+
+	normalized := map[string]string{}
+	for name, value := range attributes {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		normalized[NormalizeAttributeName(name)] = value
+	}
+	return normalized
+}
+
+// CheckAttributesValidity checks every attribute against the registered schema
+func (s *CompanyService) CheckAttributesValidity(attributes map[string]string) error {
+	for _, name := range sortedKeys(attributes) {
+		definition, pattern, ok := s.attributes.lookup(name)
+		if !ok {
+			return fmt.Errorf("%w: %s", ERR_UNKNOWN_ATTRIBUTE, name)
+		}
+		if !checkAttributeValue(definition, pattern, attributes[name]) {
+			return fmt.Errorf("%w: %s", ERR_NOT_VALID_ATTRIBUTE, name)
+		}
+	}
+	return nil
+}
+
+func checkAttributeValue(definition entity.AttributeDefinition, pattern *regexp.Regexp, value string) bool {
+	var err error
+	switch definition.Type {
+	case entity.ATTRIBUTE_TYPE_INTEGER:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case entity.ATTRIBUTE_TYPE_NUMBER:
+		_, err = strconv.ParseFloat(value, 64)
+	case entity.ATTRIBUTE_TYPE_BOOLEAN:
+		_, err = strconv.ParseBool(value)
+	}
+	if err != nil {
+		return false
+	}
+
+	return pattern == nil || pattern.MatchString(value)
+}
+
+// attributeField merges a registered attribute like a core field
+func attributeField(name string) companyField {
+	return companyField{
+		name: name,
+		get: func(company *entity.Companies) string {
+			return company.Attributes[name]
+		},
+		set: func(company *entity.Companies, value string) {
+			attributes := map[string]string{}
+			for key, current := range company.Attributes {
+				attributes[key] = current
+			}
+			attributes[name] = value
+			company.Attributes = attributes
+		},
+	}
+}
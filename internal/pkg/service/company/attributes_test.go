@@ -0,0 +1,167 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func newServiceWithSchema(dbRepository *MockCompanyRepository) *CompanyService {
+	dbRepository.ListAttributeDefinitionsMock = func(ctx context.Context) ([]*entity.AttributeDefinition, error) {
+		return []*entity.AttributeDefinition{
+			{Name: "phone", Type: entity.ATTRIBUTE_TYPE_STRING, Pattern: `^[0-9-]{7,20}$`},
+			{Name: "employees", Type: entity.ATTRIBUTE_TYPE_INTEGER, MergePolicy: string(POLICY_OVERWRITE)},
+		}, nil
+	}
+
+	service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+	service.LoadAttributeSchema(context.Background())
+	return service
+}
+
+func TestNormalizeAttributes(t *testing.T) {
+	got := NormalizeAttributes(map[string]string{" Industry Code ": " 5112 ", "Phone": ""})
+	want := map[string]string{"industry_code": "5112"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, but got %v", want, got)
+	}
+}
+
+func TestRegisterAttribute(t *testing.T) {
+	t.Run("Registering attribute", func(t *testing.T) {
+		var saved entity.AttributeDefinition
+		dbRepository := &MockCompanyRepository{
+			SaveAttributeDefinitionMock: func(ctx context.Context, definition entity.AttributeDefinition) error {
+				saved = definition
+				return nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.RegisterAttribute(context.Background(), entity.AttributeDefinition{Name: "country_code", Pattern: "^[A-Z]{2}$"})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if saved.Type != entity.ATTRIBUTE_TYPE_STRING {
+			t.Errorf("expected default type, but got %v", saved)
+		}
+		if err := service.CheckAttributesValidity(map[string]string{"country_code": "US"}); err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+	})
+
+	t.Run("Invalid definitions", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, &MockCsvCompanyRepository{})
+
+		definitions := []entity.AttributeDefinition{
+			{Name: "Phone", Type: entity.ATTRIBUTE_TYPE_STRING},
+			{Name: entity.FIELD_WEBSITE, Type: entity.ATTRIBUTE_TYPE_STRING},
+			{Name: "city", Type: entity.ATTRIBUTE_TYPE_STRING},
+			{Name: "phone", Type: "date"},
+			{Name: "phone", Type: entity.ATTRIBUTE_TYPE_STRING, Pattern: "(["},
+			{Name: "phone", Type: entity.ATTRIBUTE_TYPE_STRING, MergePolicy: "random"},
+		}
+
+		for _, definition := range definitions {
+			err := service.RegisterAttribute(context.Background(), definition)
+			if !errors.Is(err, ERR_NOT_VALID_ATTRIBUTE_SCHEMA) {
+				t.Errorf("expected %v for %v, but got %v", ERR_NOT_VALID_ATTRIBUTE_SCHEMA, definition, err)
+			}
+		}
+	})
+}
+
+func TestCheckAttributesValidity(t *testing.T) {
+	service := newServiceWithSchema(&MockCompanyRepository{})
+
+	t.Run("Valid attributes", func(t *testing.T) {
+		err := service.CheckAttributesValidity(map[string]string{"phone": "210-555-0100", "employees": "12"})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+	})
+
+	t.Run("Unknown attribute", func(t *testing.T) {
+		err := service.CheckAttributesValidity(map[string]string{"fax": "210-555-0100"})
+
+		if !errors.Is(err, ERR_UNKNOWN_ATTRIBUTE) {
+			t.Errorf("expected %v, but got %v", ERR_UNKNOWN_ATTRIBUTE, err)
+		}
+	})
+
+	t.Run("Invalid values", func(t *testing.T) {
+		for _, attributes := range []map[string]string{{"phone": "call me"}, {"employees": "twelve"}} {
+			err := service.CheckAttributesValidity(attributes)
+
+			if !errors.Is(err, ERR_NOT_VALID_ATTRIBUTE) {
+				t.Errorf("expected %v, but got %v", ERR_NOT_VALID_ATTRIBUTE, err)
+			}
+		}
+	})
+}
+
+func TestMergeAttributes(t *testing.T) {
+	t.Run("Merging registered attributes", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Attributes: map[string]string{"employees": "10"}, Version: 1}
+
+		var written entity.Companies
+		var saved []entity.Provenance
+		service := newServiceWithSchema(&MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{stored}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				written = company
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				saved = provenance
+				return nil
+			},
+		})
+
+		incoming := &entity.Companies{Name: "COMPANY", Attributes: map[string]string{"Phone": "210-555-0100", "employees": "12", "fax": "1"}}
+		result, err := service.MergeCompany(context.Background(), incoming, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		want := map[string]string{"phone": "210-555-0100", "employees": "12"}
+		if !reflect.DeepEqual(written.Attributes, want) {
+			t.Errorf("expected %v, but got %v", want, written.Attributes)
+		}
+		if stored.Attributes["employees"] != "10" {
+			t.Errorf("stored company must not be changed, but got %v", stored.Attributes)
+		}
+		if len(saved) != 2 {
+			t.Errorf("expected provenance for two attributes, but got %v", saved)
+		}
+
+		var fax entity.FieldDecision
+		for _, decision := range result.Fields {
+			if decision.Field == "fax" {
+				fax = decision
+			}
+		}
+		if fax.Applied || fax.Reason != ERR_UNKNOWN_ATTRIBUTE.Error() {
+			t.Errorf("got decision %v", fax)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var ERR_NOT_VALID_CURSOR = errors.New("Error: the cursor is not valid")
+
+// ListChanges returns the page of changes after the cursor, the feed starting
+// from the first change when the cursor is empty. A limit of 0 gives
+// DEFAULT_PAGE_SIZE changes.
+func (s *CompanyService) ListChanges(ctx context.Context, cursor string, limit int) (*entity.ChangePage, error) {
+	since := int64(0)
+	if cursor != "" {
+		var err error
+		since, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil || since < 0 {
+			return nil, ERR_NOT_VALID_CURSOR
+		}
+	}
+
+	if limit == 0 {
+		limit = DEFAULT_PAGE_SIZE
+	}
+	if limit < 0 || limit > MAX_PAGE_SIZE {
+		return nil, ERR_NOT_VALID_PAGE
+	}
+
+	// one more change than asked tells whether there is a next page
+	references, err := s.dbRepository.ListCompanyChanges(ctx, since, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	page := &entity.ChangePage{Changes: []entity.CompanyChange{}, NextCursor: strconv.FormatInt(since, 10)}
+	if len(references) > limit {
+		references = references[:limit]
+		page.HasMore = true
+	}
+	for _, change := range references {
+		page.Changes = append(page.Changes, *change)
+		page.NextCursor = strconv.FormatInt(change.Sequence, 10)
+	}
+	return page, nil
+}
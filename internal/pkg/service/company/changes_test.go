@@ -0,0 +1,79 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestListChanges(t *testing.T) {
+	changes := func(since int64, count int) []*entity.CompanyChange {
+		references := []*entity.CompanyChange{}
+		for index := 1; index <= count; index++ {
+			references = append(references, &entity.CompanyChange{Sequence: since + int64(index), Operation: entity.CHANGE_UPDATE, CompanyID: uuid.New()})
+		}
+		return references
+	}
+
+	t.Run("with a next page", func(t *testing.T) {
+		var gotSince int64
+		var gotLimit int
+		repository := &MockCompanyRepository{
+			ListCompanyChangesMock: func(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error) {
+				gotSince, gotLimit = since, limit
+				return changes(since, limit), nil
+			},
+		}
+		service := NewCompanyService(repository, nil)
+
+		page, err := service.ListChanges(context.Background(), "40", 2)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if gotSince != 40 || gotLimit != 3 {
+			t.Errorf("got since %v and limit %v want 40 and 3", gotSince, gotLimit)
+		}
+		if len(page.Changes) != 2 || !page.HasMore || page.NextCursor != "42" {
+			t.Errorf("got %+v", page)
+		}
+	})
+
+	t.Run("caught up", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ListCompanyChangesMock: func(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error) {
+				return nil, nil
+			},
+		}
+		service := NewCompanyService(repository, nil)
+
+		page, err := service.ListChanges(context.Background(), "", 0)
+
+		if err != nil || len(page.Changes) != 0 || page.HasMore || page.NextCursor != "0" {
+			t.Errorf("got %+v, %v", page, err)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, nil)
+
+		tests := []struct {
+			cursor  string
+			limit   int
+			wantErr error
+		}{
+			{"abc", 10, ERR_NOT_VALID_CURSOR},
+			{"-1", 10, ERR_NOT_VALID_CURSOR},
+			{"10", MAX_PAGE_SIZE + 1, ERR_NOT_VALID_PAGE},
+			{"10", -1, ERR_NOT_VALID_PAGE},
+		}
+		for _, test := range tests {
+			if _, err := service.ListChanges(context.Background(), test.cursor, test.limit); !errors.Is(err, test.wantErr) {
+				t.Errorf("got %v error want %v", err, test.wantErr)
+			}
+		}
+	})
+}
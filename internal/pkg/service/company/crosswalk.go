@@ -0,0 +1,61 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// matchByCrosswalk finds the company the source of an incoming record matched
+// to the same external ID in earlier merges. Records without one are only
+// matched by name and zip code.
+func (s *CompanyService) matchByCrosswalk(ctx context.Context, company *entity.Companies, source entity.Source) (*entity.Companies, error) {
+	if company.ExternalID == "" {
+		return nil, nil
+	}
+
+	crosswalk, err := s.dbRepository.ReadCrosswalk(ctx, source.System, company.ExternalID)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if crosswalk == nil {
+		return nil, nil
+	}
+
+	readCompany, err := s.dbRepository.ReadCompanyByID(ctx, crosswalk.CompanyID, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	return readCompany, nil
+}
+
+// crosswalkFor remembers that the external ID of an incoming record refers
+// to companyID, or is nil when the source gave none
+func crosswalkFor(company *entity.Companies, companyID uuid.UUID, source entity.Source) *entity.Crosswalk {
+	if company.ExternalID == "" {
+		return nil
+	}
+
+	return &entity.Crosswalk{
+		SourceSystem: source.System,
+		ExternalKey:  company.ExternalID,
+		CompanyID:    companyID,
+	}
+}
+
+// FindBySource returns the company a source system knows by externalID, or
+// nil if the key was never matched
+func (s *CompanyService) FindBySource(ctx context.Context, sourceSystem string, externalID string) (*entity.Companies, error) {
+	crosswalk, err := s.dbRepository.ReadCrosswalk(ctx, sourceSystem, externalID)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if crosswalk == nil {
+		return nil, nil
+	}
+	return s.GetCompanyByID(ctx, crosswalk.CompanyID, false)
+}
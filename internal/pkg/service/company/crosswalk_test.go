@@ -0,0 +1,164 @@
+package company
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestMergeCompanyCrosswalk(t *testing.T) {
+	stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 1}
+	source := NewSource(SOURCE_CLIENT, "clients.csv")
+
+	newRepository := func(crosswalk *entity.Crosswalk, saved *[]entity.Crosswalk) *MockCompanyRepository {
+		return &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				if sourceSystem != SOURCE_CLIENT || externalKey != "C-1001" {
+					t.Errorf("got %v, %v want %v, C-1001", sourceSystem, externalKey, SOURCE_CLIENT)
+				}
+				return crosswalk, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				*saved = append(*saved, crosswalk)
+				return nil
+			},
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return stored, nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				if name == stored.Name {
+					return []*entity.Companies{stored}, nil
+				}
+				return nil, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("recording the key of a name match", func(t *testing.T) {
+		saved := []entity.Crosswalk{}
+		service := NewCompanyService(newRepository(nil, &saved), nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{ExternalID: "C-1001", Name: "COMPANY", Zip: "12345"}, source)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.MatchedBy != entity.MATCH_NAME {
+			t.Errorf("got %v want %v", result.MatchedBy, entity.MATCH_NAME)
+		}
+		want := entity.Crosswalk{SourceSystem: SOURCE_CLIENT, ExternalKey: "C-1001", CompanyID: stored.ID}
+		if len(saved) != 1 || saved[0] != want {
+			t.Errorf("got %v want %v", saved, want)
+		}
+	})
+
+	t.Run("matching by the stored key first", func(t *testing.T) {
+		saved := []entity.Crosswalk{}
+		crosswalk := &entity.Crosswalk{SourceSystem: SOURCE_CLIENT, ExternalKey: "C-1001", CompanyID: stored.ID}
+		service := NewCompanyService(newRepository(crosswalk, &saved), nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{ExternalID: "C-1001", Name: "COMPANY LLC", Zip: "12345"}, source)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.MatchedBy != entity.MATCH_CROSSWALK || result.CompanyID != stored.ID {
+			t.Errorf("got %v, %v want %v, %v", result.MatchedBy, result.CompanyID, entity.MATCH_CROSSWALK, stored.ID)
+		}
+		if len(saved) != 0 {
+			t.Errorf("got %v want no new crosswalk", saved)
+		}
+	})
+
+	t.Run("no key for a record without external ID", func(t *testing.T) {
+		saved := []entity.Crosswalk{}
+		service := NewCompanyService(newRepository(nil, &saved), nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "12345"}, source)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.MatchedBy != entity.MATCH_NAME || len(saved) != 0 {
+			t.Errorf("got %v, %v want %v without crosswalk", result.MatchedBy, saved, entity.MATCH_NAME)
+		}
+	})
+
+	t.Run("rejected merge leaving no key behind", func(t *testing.T) {
+		saved := []entity.Crosswalk{}
+		repository := newRepository(nil, &saved)
+		repository.SaveMergedCompanyMock = func(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error {
+			t.Errorf("got a write of %v, %v want none", company, crosswalk)
+			return nil
+		}
+		service := NewCompanyService(repository, nil)
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{ExternalID: "C-1001", Name: "COMPANY", Zip: "12345", Website: "ftp://company.com"}, source)
+
+		if result.Status != entity.MERGE_STATUS_REJECTED || len(saved) != 0 {
+			t.Errorf("got %v, %v want %v without crosswalk", result.Status, saved, entity.MERGE_STATUS_REJECTED)
+		}
+	})
+
+	t.Run("writing the key with the company", func(t *testing.T) {
+		saved := []entity.Crosswalk{}
+		repository := newRepository(nil, &saved)
+		var gotCompany *entity.Companies
+		var gotCrosswalk *entity.Crosswalk
+		var gotProvenance []entity.Provenance
+		repository.SaveMergedCompanyMock = func(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error {
+			gotCompany, gotCrosswalk, gotProvenance = company, crosswalk, provenance
+			return nil
+		}
+		service := NewCompanyService(repository, nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{ExternalID: "C-1001", Name: "COMPANY", Zip: "12345", Website: "http://company.net"}, source)
+
+		if err != nil || result.Status != entity.MERGE_STATUS_MERGED {
+			t.Fatalf("got %v, %v want merged", result.Status, err)
+		}
+		if gotCompany == nil || gotCompany.Website != "http://company.net" || gotCrosswalk == nil || gotCrosswalk.ExternalKey != "C-1001" {
+			t.Errorf("got %v, %v want the company and its key", gotCompany, gotCrosswalk)
+		}
+		if len(gotProvenance) != 1 || gotProvenance[0].Field != entity.FIELD_WEBSITE {
+			t.Errorf("got %v want the provenance of the website", gotProvenance)
+		}
+	})
+}
+
+func TestFindBySource(t *testing.T) {
+	stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY"}
+	repository := &MockCompanyRepository{
+		ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+			if externalKey != "C-1001" {
+				return nil, nil
+			}
+			return &entity.Crosswalk{SourceSystem: sourceSystem, ExternalKey: externalKey, CompanyID: stored.ID}, nil
+		},
+		ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+			return stored, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	got, err := service.FindBySource(context.Background(), SOURCE_CLIENT, "C-1001")
+	if err != nil || got != stored {
+		t.Errorf("got %v, %v want %v", got, err, stored)
+	}
+
+	got, err = service.FindBySource(context.Background(), SOURCE_CLIENT, "C-9999")
+	if err != nil || got != nil {
+		t.Errorf("got %v, %v want nil", got, err)
+	}
+}
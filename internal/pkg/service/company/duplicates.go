@@ -0,0 +1,133 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// DUPLICATE_THRESHOLD is the name similarity from which two companies sharing
+// a zip code are reported as duplicate candidates
+const DUPLICATE_THRESHOLD = 0.88
+
+var (
+	ERR_DUPLICATE_NOT_FOUND = errors.New("Error: there is no pending duplicate candidate with this ID")
+	ERR_NOT_VALID_SURVIVOR  = errors.New("Error: the surviving company must be one of the candidate pair")
+)
+
+// DetectDuplicates compares the names of the companies sharing a zip code and
+// stores the likely duplicates as pending candidates, returning the pending
+// candidates with their stored IDs. Pairs already confirmed or rejected are
+// not reported again
+func (s *CompanyService) DetectDuplicates(ctx context.Context) ([]entity.DuplicateCandidate, error) {
+	companies, err := s.dbRepository.ListCompanies(ctx, entity.CompanyFilter{})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	blocks := map[string][]*entity.Companies{}
+	zips := []string{}
+	for _, company := range companies {
+		key := postalKey(company)
+		if _, ok := blocks[key]; !ok {
+			zips = append(zips, key)
+		}
+		blocks[key] = append(blocks[key], company)
+	}
+
+	candidates := []entity.DuplicateCandidate{}
+	for _, zip := range zips {
+		block := blocks[zip]
+		for i := 0; i < len(block); i++ {
+			for j := i + 1; j < len(block); j++ {
+				score := nameSimilarity(block[i].Name, block[j].Name)
+				if score < DUPLICATE_THRESHOLD {
+					continue
+				}
+				candidates = append(candidates, duplicatePair(block[i], block[j], score))
+			}
+		}
+	}
+
+	saved, err := s.dbRepository.SaveDuplicateCandidates(ctx, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return saved, nil
+}
+
+// duplicatePair orders the pair by creation so the same two companies always
+// give the same candidate, the older company first
+func duplicatePair(a *entity.Companies, b *entity.Companies, score float64) entity.DuplicateCandidate {
+	if b.CreatedAt.Before(a.CreatedAt) || (b.CreatedAt.Equal(a.CreatedAt) && b.ID.String() < a.ID.String()) {
+		a, b = b, a
+	}
+	return entity.DuplicateCandidate{
+		ID:          uuid.New(),
+		CompanyID:   a.ID,
+		DuplicateID: b.ID,
+		Score:       score,
+		Status:      entity.DUPLICATE_STATUS_PENDING,
+	}
+}
+
+func (s *CompanyService) ListDuplicateCandidates(ctx context.Context, status string) ([]entity.DuplicateCandidate, error) {
+	references, err := s.dbRepository.ListDuplicateCandidates(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	candidates := []entity.DuplicateCandidate{}
+	for _, candidate := range references {
+		candidates = append(candidates, *candidate)
+	}
+	return candidates, nil
+}
+
+// RejectDuplicate marks a pending candidate as not being a duplicate
+func (s *CompanyService) RejectDuplicate(ctx context.Context, candidateID uuid.UUID) error {
+	resolved, err := s.dbRepository.ResolveDuplicateCandidate(ctx, candidateID, entity.DUPLICATE_STATUS_REJECTED)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	if !resolved {
+		return ERR_DUPLICATE_NOT_FOUND
+	}
+	return nil
+}
+
+// ConfirmDuplicate merges a pending candidate pair into survivorID, which
+// defaults to the older company of the pair, see MergeInto
+func (s *CompanyService) ConfirmDuplicate(ctx context.Context, candidateID uuid.UUID, survivorID uuid.UUID) (*entity.Companies, error) {
+	candidate, err := s.dbRepository.ReadDuplicateCandidate(ctx, candidateID)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if candidate == nil || candidate.Status != entity.DUPLICATE_STATUS_PENDING {
+		return nil, ERR_DUPLICATE_NOT_FOUND
+	}
+
+	duplicateID := candidate.DuplicateID
+	switch survivorID {
+	case uuid.Nil, candidate.CompanyID:
+		survivorID = candidate.CompanyID
+	case candidate.DuplicateID:
+		duplicateID = candidate.CompanyID
+	default:
+		return nil, ERR_NOT_VALID_SURVIVOR
+	}
+
+	// the candidate is confirmed in the transaction of the merge
+	merge, err := s.mergeInto(ctx, duplicateID, survivorID, func(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error {
+		return s.dbRepository.ConfirmDuplicateCandidate(ctx, candidateID, survivor, merged, provenance)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &merge.Survivor, nil
+}
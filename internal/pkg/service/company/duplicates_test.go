@@ -0,0 +1,157 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestDetectDuplicates(t *testing.T) {
+	older := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "78229", CreatedAt: time.Now().Add(-time.Hour)}
+	newer := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP INC", Zip: "78229", CreatedAt: time.Now()}
+	otherZip := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "10001", CreatedAt: time.Now()}
+	different := &entity.Companies{ID: uuid.New(), Name: "ACME WIDGETS", Zip: "78229", CreatedAt: time.Now()}
+
+	// the pair was already detected, it keeps the ID it was stored with
+	storedID := uuid.New()
+	var saved []entity.DuplicateCandidate
+	repository := &MockCompanyRepository{
+		ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+			return []*entity.Companies{newer, different, otherZip, older}, nil
+		},
+		SaveDuplicateCandidatesMock: func(ctx context.Context, candidates []entity.DuplicateCandidate) ([]entity.DuplicateCandidate, error) {
+			saved = candidates
+			stored := []entity.DuplicateCandidate{}
+			for _, candidate := range candidates {
+				candidate.ID = storedID
+				stored = append(stored, candidate)
+			}
+			return stored, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	got, err := service.DetectDuplicates(context.Background())
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if len(got) != 1 || len(saved) != 1 {
+		t.Fatalf("got %v want one candidate", got)
+	}
+	if got[0].ID != storedID {
+		t.Errorf("got ID %v want the stored one %v", got[0].ID, storedID)
+	}
+	if got[0].CompanyID != older.ID || got[0].DuplicateID != newer.ID {
+		t.Errorf("got pair %v, %v want %v, %v", got[0].CompanyID, got[0].DuplicateID, older.ID, newer.ID)
+	}
+	if got[0].Score < DUPLICATE_THRESHOLD || got[0].Status != entity.DUPLICATE_STATUS_PENDING {
+		t.Errorf("got %v", got[0])
+	}
+}
+
+func TestConfirmDuplicate(t *testing.T) {
+	survivor := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "78229", Version: 3}
+	duplicate := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP INC", Zip: "78229", Website: "http://repsources.com", Version: 1}
+	candidate := &entity.DuplicateCandidate{ID: uuid.New(), CompanyID: survivor.ID, DuplicateID: duplicate.ID, Status: entity.DUPLICATE_STATUS_PENDING}
+
+	newRepository := func() (*MockCompanyRepository, *[]string) {
+		calls := &[]string{}
+		stored := survivor
+		return &MockCompanyRepository{
+			ReadDuplicateCandidateMock: func(ctx context.Context, id uuid.UUID) (*entity.DuplicateCandidate, error) {
+				if id != candidate.ID {
+					return nil, nil
+				}
+				return candidate, nil
+			},
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				if id == survivor.ID {
+					return stored, nil
+				}
+				return duplicate, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return []*entity.Provenance{{CompanyID: duplicate.ID, Field: entity.FIELD_WEBSITE, SourceSystem: SOURCE_CLIENT}}, nil
+			},
+			ConfirmDuplicateCandidateMock: func(ctx context.Context, candidateID uuid.UUID, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error {
+				*calls = append(*calls, "confirm "+candidateID.String())
+				*calls = append(*calls, "merge "+merged.ID.String()+" into "+survivor.ID.String()+" "+survivor.Website)
+				for _, record := range provenance {
+					*calls = append(*calls, "provenance "+record.CompanyID.String()+" "+record.Field)
+				}
+				stored = &survivor
+				return nil
+			},
+		}, calls
+	}
+
+	t.Run("keeping the older company", func(t *testing.T) {
+		repository, calls := newRepository()
+		service := NewCompanyService(repository, repository)
+
+		got, err := service.ConfirmDuplicate(context.Background(), candidate.ID, uuid.Nil)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if got.ID != survivor.ID || got.Name != survivor.Name || got.Website != duplicate.Website {
+			t.Errorf("got %v", got)
+		}
+
+		want := []string{
+			"confirm " + candidate.ID.String(),
+			"merge " + duplicate.ID.String() + " into " + survivor.ID.String() + " " + duplicate.Website,
+			"provenance " + survivor.ID.String() + " " + entity.FIELD_WEBSITE,
+		}
+		if len(*calls) != len(want) {
+			t.Fatalf("got %v want %v", *calls, want)
+		}
+		for index := range want {
+			if (*calls)[index] != want[index] {
+				t.Errorf("got %v want %v", (*calls)[index], want[index])
+			}
+		}
+	})
+
+	t.Run("survivor outside the pair", func(t *testing.T) {
+		repository, _ := newRepository()
+		service := NewCompanyService(repository, repository)
+
+		_, err := service.ConfirmDuplicate(context.Background(), candidate.ID, uuid.New())
+
+		if !errors.Is(err, ERR_NOT_VALID_SURVIVOR) {
+			t.Errorf("got %v error want %v", err, ERR_NOT_VALID_SURVIVOR)
+		}
+	})
+
+	t.Run("unknown candidate", func(t *testing.T) {
+		repository, _ := newRepository()
+		service := NewCompanyService(repository, repository)
+
+		_, err := service.ConfirmDuplicate(context.Background(), uuid.New(), uuid.Nil)
+
+		if !errors.Is(err, ERR_DUPLICATE_NOT_FOUND) {
+			t.Errorf("got %v error want %v", err, ERR_DUPLICATE_NOT_FOUND)
+		}
+	})
+}
+
+func TestRejectDuplicate(t *testing.T) {
+	repository := &MockCompanyRepository{
+		ResolveDuplicateCandidateMock: func(ctx context.Context, id uuid.UUID, status string) (bool, error) {
+			return false, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	err := service.RejectDuplicate(context.Background(), uuid.New())
+
+	if !errors.Is(err, ERR_DUPLICATE_NOT_FOUND) {
+		t.Errorf("got %v error want %v", err, ERR_DUPLICATE_NOT_FOUND)
+	}
+}
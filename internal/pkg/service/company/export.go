@@ -0,0 +1,23 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+// ExportCompanies hands the companies matching the filter to each as they are
+// read from the database, stopping at the first error it returns
+func (s *CompanyService) ExportCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return err
+	}
+
+	err = s.dbRepository.StreamCompanies(ctx, filter, each)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	return nil
+}
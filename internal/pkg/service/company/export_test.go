@@ -0,0 +1,74 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestExportCompanies(t *testing.T) {
+	t.Run("streaming the filtered companies", func(t *testing.T) {
+		var gotFilter entity.CompanyFilter
+		repository := &MockCompanyRepository{
+			StreamCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+				gotFilter = filter
+				for _, name := range []string{"COMPANY", "OTHER"} {
+					if err := each(&entity.Companies{ID: uuid.New(), Name: name}); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		}
+		service := NewCompanyService(repository, nil)
+
+		names := []string{}
+		err := service.ExportCompanies(context.Background(), entity.CompanyFilter{Name: "company", Country: "usa", Zip: "78229-1234"}, func(company *entity.Companies) error {
+			names = append(names, company.Name)
+			return nil
+		})
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if len(names) != 2 {
+			t.Errorf("got %v want 2 companies", names)
+		}
+		if gotFilter.Name != "COMPANY" || gotFilter.Country != entity.COUNTRY_US || gotFilter.Zip != "78229" {
+			t.Errorf("got filter %+v", gotFilter)
+		}
+	})
+
+	t.Run("unknown country", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, nil)
+
+		err := service.ExportCompanies(context.Background(), entity.CompanyFilter{Country: "Atlantis"}, func(company *entity.Companies) error {
+			return nil
+		})
+
+		if !errors.Is(err, ERR_UNKNOWN_COUNTRY) {
+			t.Errorf("got %v error want %v", err, ERR_UNKNOWN_COUNTRY)
+		}
+	})
+
+	t.Run("error while writing", func(t *testing.T) {
+		wantErr := errors.New("broken pipe")
+		repository := &MockCompanyRepository{
+			StreamCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+				return each(&entity.Companies{Name: "COMPANY"})
+			},
+		}
+		service := NewCompanyService(repository, nil)
+
+		err := service.ExportCompanies(context.Background(), entity.CompanyFilter{}, func(company *entity.Companies) error {
+			return wantErr
+		})
+
+		if !errors.Is(err, wantErr) {
+			t.Errorf("got %v error want %v", err, wantErr)
+		}
+	})
+}
@@ -0,0 +1,28 @@
+package company
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+// FindIngestedFile returns the file already merged with the checksum, or nil
+// if there is none
+func (s *CompanyService) FindIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error) {
+	file, err := s.dbRepository.ReadIngestedFile(ctx, checksum)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	return file, nil
+}
+
+// RecordIngestedFile remembers a merged file so that the same content is not
+// merged again
+func (s *CompanyService) RecordIngestedFile(ctx context.Context, file entity.IngestedFile) error {
+	err := s.dbRepository.SaveIngestedFile(ctx, file)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return nil
+}
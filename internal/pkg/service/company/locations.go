@@ -0,0 +1,73 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+var ERR_AMBIGUOUS_LOCATION = errors.New("Error: the company has several locations and none of them has this zip code")
+
+// resolveLocation finds the stored location an incoming record refers to.
+// Locations of the parent company with the same name are matched by postal
+// code, see samePostalCode;
+// a company with a single location matches it whatever the zip, so a moved
+// company still merges. It returns nil when the company is unknown and
+// ERR_AMBIGUOUS_LOCATION when several locations exist and none has the zip.
+func (s *CompanyService) resolveLocation(ctx context.Context, company *entity.Companies) (*entity.Companies, error) {
+	locations, err := s.dbRepository.ReadCompanyLocations(ctx, company.Name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	for _, location := range locations {
+		if samePostalCode(location, company) {
+			return location, nil
+		}
+	}
+
+	switch len(locations) {
+	case 0:
+		return nil, nil
+	case 1:
+		return locations[0], nil
+	default:
+		return nil, ERR_AMBIGUOUS_LOCATION
+	}
+}
+
+// GetLocations returns the parent company of the given company together with
+// all of its locations, or nil if there is no such company
+func (s *CompanyService) GetLocations(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+	company, err := s.dbRepository.ReadCompanyByID(ctx, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if company == nil {
+		return nil, nil
+	}
+
+	parent, err := s.dbRepository.ReadParentCompany(ctx, company.ParentID)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if parent == nil {
+		return nil, nil
+	}
+
+	locations, err := s.dbRepository.ListCompanies(ctx, entity.CompanyFilter{ParentID: parent.ID})
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	parent.Locations = []entity.Companies{}
+	for _, location := range locations {
+		parent.Locations = append(parent.Locations, *location)
+	}
+	return parent, nil
+}
@@ -0,0 +1,164 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestResolveLocation(t *testing.T) {
+	parentID := uuid.New()
+	downtown := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "12345"}
+	airport := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "54321"}
+
+	tests := []struct {
+		name      string
+		locations []*entity.Companies
+		zip       string
+		want      *entity.Companies
+		wantErr   error
+	}{
+		{"unknown company", nil, "12345", nil, nil},
+		{"matching zip", []*entity.Companies{downtown, airport}, "54321", airport, nil},
+		{"single location", []*entity.Companies{downtown}, "99999", downtown, nil},
+		{"ambiguous", []*entity.Companies{downtown, airport}, "99999", nil, ERR_AMBIGUOUS_LOCATION},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &MockCompanyRepository{
+				ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+					return test.locations, nil
+				},
+			}
+			service := NewCompanyService(repository, repository)
+
+			got, err := service.resolveLocation(context.Background(), &entity.Companies{Name: "COMPANY", Zip: test.zip})
+
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("got %v error want %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("got %v want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMergeCompanyLocation(t *testing.T) {
+	parentID := uuid.New()
+	downtown := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "12345", Version: 1}
+	airport := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "54321", Version: 1}
+
+	t.Run("updates the location with the zip", func(t *testing.T) {
+		var updated entity.Companies
+		repository := &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{downtown, airport}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				updated = company
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "company", Zip: "54321", Website: "https://www.airport.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if result.CompanyID != airport.ID || updated.ID != airport.ID {
+			t.Errorf("got %v want %v", updated.ID, airport.ID)
+		}
+	})
+
+	t.Run("rejects an ambiguous record", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{downtown, airport}, nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "99999", Website: "www.company.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if !errors.Is(err, ERR_AMBIGUOUS_LOCATION) {
+			t.Errorf("got %v error want %v", err, ERR_AMBIGUOUS_LOCATION)
+		}
+		if result.Status != entity.MERGE_STATUS_REJECTED {
+			t.Errorf("got %v want %v", result.Status, entity.MERGE_STATUS_REJECTED)
+		}
+	})
+}
+
+func TestGetLocations(t *testing.T) {
+	parentID := uuid.New()
+	company := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "12345"}
+	other := &entity.Companies{ID: uuid.New(), ParentID: parentID, Name: "COMPANY", Zip: "54321"}
+
+	t.Run("with locations", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return company, nil
+			},
+			ReadParentCompanyMock: func(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+				return &entity.ParentCompany{ID: id, Name: "COMPANY"}, nil
+			},
+			ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+				if filter.ParentID != parentID {
+					t.Errorf("got %v want %v", filter.ParentID, parentID)
+				}
+				return []*entity.Companies{company, other}, nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		got, err := service.GetLocations(context.Background(), company.ID)
+		want := &entity.ParentCompany{ID: parentID, Name: "COMPANY", Locations: []entity.Companies{*company, *other}}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("unknown company", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return nil, nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		got, err := service.GetLocations(context.Background(), uuid.New())
+
+		if err != nil || got != nil {
+			t.Errorf("got %v, %v want nil, nil", got, err)
+		}
+	})
+}
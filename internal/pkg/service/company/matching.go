@@ -0,0 +1,117 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var ERR_UNKNOWN_MATCH_STRATEGY = errors.New("Error: unknown match strategy")
+
+// matchStrategy finds the stored company an incoming record refers to, or nil
+type matchStrategy func(s *CompanyService, ctx context.Context, company *entity.Companies, source entity.Source) (*entity.Companies, error)
+
+var matchStrategies = map[string]matchStrategy{
+	entity.MATCH_CROSSWALK: (*CompanyService).matchByCrosswalk,
+	entity.MATCH_NAME: func(s *CompanyService, ctx context.Context, company *entity.Companies, source entity.Source) (*entity.Companies, error) {
+		return s.resolveLocation(ctx, company)
+	},
+	entity.MATCH_WEBSITE: func(s *CompanyService, ctx context.Context, company *entity.Companies, source entity.Source) (*entity.Companies, error) {
+		return s.matchByWebsite(ctx, company)
+	},
+}
+
+// DefaultMatchStrategies tries the key the source used before, then name and
+// zip code, then the website domain
+func DefaultMatchStrategies() []string {
+	return []string{entity.MATCH_CROSSWALK, entity.MATCH_NAME, entity.MATCH_WEBSITE}
+}
+
+// ParseMatchStrategies reads a comma separated strategy order such as
+// "website,name". An empty value gives the default order.
+func ParseMatchStrategies(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultMatchStrategies(), nil
+	}
+
+	order := []string{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := matchStrategies[name]; !ok {
+			return nil, fmt.Errorf("%w: %s", ERR_UNKNOWN_MATCH_STRATEGY, name)
+		}
+		order = append(order, name)
+	}
+	return order, nil
+}
+
+// SetMatchStrategies sets the order in which the merge tries the strategies,
+// strategies left out are not used
+func (s *CompanyService) SetMatchStrategies(order []string) error {
+	for _, name := range order {
+		if _, ok := matchStrategies[name]; !ok {
+			return fmt.Errorf("%w: %s", ERR_UNKNOWN_MATCH_STRATEGY, name)
+		}
+	}
+	s.matchOrder = append([]string{}, order...)
+	return nil
+}
+
+func (s *CompanyService) MatchStrategies() []string {
+	return append([]string{}, s.matchOrder...)
+}
+
+// matchCompany runs the match strategies in order and returns the first
+// company found along with the strategy that found it. A record that is
+// ambiguous by name is only rejected when no other strategy matches it.
+func (s *CompanyService) matchCompany(ctx context.Context, company *entity.Companies, source entity.Source) (*entity.Companies, string, error) {
+	var ambiguous error
+
+	for _, name := range s.matchOrder {
+		readCompany, err := matchStrategies[name](s, ctx, company, source)
+		if errors.Is(err, ERR_AMBIGUOUS_LOCATION) {
+			ambiguous = err
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		if readCompany != nil {
+			return readCompany, name, nil
+		}
+	}
+	return nil, "", ambiguous
+}
+
+// matchByWebsite finds the company with the same website domain, in the same
+// zip code or, failing that, anywhere when it is the only one with the
+// domain. Several companies sharing the domain and the zip code match none.
+func (s *CompanyService) matchByWebsite(ctx context.Context, company *entity.Companies) (*entity.Companies, error) {
+	if company.Domain == "" {
+		return nil, nil
+	}
+
+	companies, err := s.dbRepository.ReadCompaniesByDomain(ctx, company.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	sameZip := []*entity.Companies{}
+	for _, candidate := range companies {
+		if samePostalCode(candidate, company) {
+			sameZip = append(sameZip, candidate)
+		}
+	}
+
+	switch {
+	case len(sameZip) == 1:
+		return sameZip[0], nil
+	case len(sameZip) == 0 && len(companies) == 1:
+		return companies[0], nil
+	default:
+		return nil, nil
+	}
+}
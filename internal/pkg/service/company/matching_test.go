@@ -0,0 +1,159 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestParseMatchStrategies(t *testing.T) {
+	got, err := ParseMatchStrategies("website, name")
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	want := []string{entity.MATCH_WEBSITE, entity.MATCH_NAME}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v want %v", got, want)
+	}
+
+	got, _ = ParseMatchStrategies("")
+	if !reflect.DeepEqual(got, DefaultMatchStrategies()) {
+		t.Errorf("got %v want %v", got, DefaultMatchStrategies())
+	}
+
+	if _, err := ParseMatchStrategies("name,phone"); !errors.Is(err, ERR_UNKNOWN_MATCH_STRATEGY) {
+		t.Errorf("got %v error want %v", err, ERR_UNKNOWN_MATCH_STRATEGY)
+	}
+}
+
+func TestMatchByWebsite(t *testing.T) {
+	downtown := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Domain: "company.com"}
+	airport := &entity.Companies{ID: uuid.New(), Name: "COMPANY AIRPORT", Zip: "54321", Domain: "company.com"}
+	store := &entity.Companies{ID: uuid.New(), Name: "COMPANY STORE", Zip: "54321", Domain: "company.com"}
+
+	tests := []struct {
+		name      string
+		companies []*entity.Companies
+		zip       string
+		want      *entity.Companies
+	}{
+		{"unknown domain", nil, "12345", nil},
+		{"same zip", []*entity.Companies{downtown, airport}, "12345", downtown},
+		{"unique domain", []*entity.Companies{airport}, "99999", airport},
+		{"several elsewhere", []*entity.Companies{downtown, airport}, "99999", nil},
+		{"several in the zip", []*entity.Companies{downtown, airport, store}, "54321", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repository := &MockCompanyRepository{
+				ReadCompaniesByDomainMock: func(ctx context.Context, domain string) ([]*entity.Companies, error) {
+					if domain != "company.com" {
+						t.Errorf("got %v want company.com", domain)
+					}
+					return test.companies, nil
+				},
+			}
+			service := NewCompanyService(repository, repository)
+
+			got, err := service.matchByWebsite(context.Background(), &entity.Companies{Zip: test.zip, Domain: "company.com"})
+
+			if err != nil {
+				t.Errorf("got %v error, it should be nil", err)
+			}
+			if got != test.want {
+				t.Errorf("got %v want %v", got, test.want)
+			}
+		})
+	}
+
+	t.Run("without website", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, nil)
+
+		got, err := service.matchByWebsite(context.Background(), &entity.Companies{Zip: "12345"})
+
+		if err != nil || got != nil {
+			t.Errorf("got %v, %v want nil, nil", got, err)
+		}
+	})
+}
+
+func TestMergeCompanyByWebsite(t *testing.T) {
+	stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "https://www.company.com", Domain: "company.com", Version: 1}
+	other := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "54321", Domain: "other.com", Version: 1}
+
+	newRepository := func(locations []*entity.Companies, updated *entity.Companies) *MockCompanyRepository {
+		return &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return locations, nil
+			},
+			ReadCompaniesByDomainMock: func(ctx context.Context, domain string) ([]*entity.Companies, error) {
+				return []*entity.Companies{stored}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				*updated = company
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+	}
+
+	t.Run("keeping the stored name", func(t *testing.T) {
+		var updated entity.Companies
+		service := NewCompanyService(newRepository(nil, &updated), nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "Company Trading", Zip: "12345", Website: "https://company.com/contact"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.MatchedBy != entity.MATCH_WEBSITE || result.CompanyID != stored.ID {
+			t.Errorf("got %v, %v want %v, %v", result.MatchedBy, result.CompanyID, entity.MATCH_WEBSITE, stored.ID)
+		}
+		if updated.Name != stored.Name {
+			t.Errorf("got %v want %v", updated.Name, stored.Name)
+		}
+	})
+
+	t.Run("resolving an ambiguous name", func(t *testing.T) {
+		var updated entity.Companies
+		service := NewCompanyService(newRepository([]*entity.Companies{stored, other}, &updated), nil)
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "99999", Website: "https://company.com/contact"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.MatchedBy != entity.MATCH_WEBSITE || result.CompanyID != stored.ID {
+			t.Errorf("got %v, %v want %v, %v", result.MatchedBy, result.CompanyID, entity.MATCH_WEBSITE, stored.ID)
+		}
+	})
+
+	t.Run("following the configured order", func(t *testing.T) {
+		var updated entity.Companies
+		service := NewCompanyService(newRepository([]*entity.Companies{other}, &updated), nil)
+		service.SetMatchStrategies([]string{entity.MATCH_WEBSITE, entity.MATCH_NAME})
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "12345", Website: "https://company.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if result.MatchedBy != entity.MATCH_WEBSITE || result.CompanyID != stored.ID {
+			t.Errorf("got %v, %v want %v, %v", result.MatchedBy, result.CompanyID, entity.MATCH_WEBSITE, stored.ID)
+		}
+	})
+}
@@ -0,0 +1,360 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// MergePolicy decides whether an incoming value replaces the stored one
+type MergePolicy string
+
+const (
+	POLICY_OVERWRITE       MergePolicy = "overwrite"
+	POLICY_FILL_IF_EMPTY   MergePolicy = "fill-if-empty"
+	POLICY_PREFER_PRIORITY MergePolicy = "prefer-priority"
+	POLICY_NEWEST_WINS     MergePolicy = "newest-wins"
+)
+
+var (
+	ERR_UNKNOWN_MERGE_POLICY = errors.New("Error: unknown merge policy")
+	ERR_UNKNOWN_MERGE_FIELD  = errors.New("Error: unknown merge field")
+)
+
+// companyField reads and writes one mergeable field of a company. carry,
+// when set, copies the fields that go along with it from the company the
+// value is taken from.
+type companyField struct {
+	name  string
+	get   func(company *entity.Companies) string
+	set   func(company *entity.Companies, value string)
+	carry func(to *entity.Companies, from *entity.Companies)
+}
+
+// take sets the field of to to its value in from
+func (field companyField) take(to *entity.Companies, from *entity.Companies) {
+	field.set(to, field.get(from))
+	if field.carry != nil {
+		field.carry(to, from)
+	}
+}
+
+var companyFields = []companyField{
+	{
+		name: entity.FIELD_NAME,
+		get:  func(company *entity.Companies) string { return company.Name },
+		set:  func(company *entity.Companies, value string) { company.Name = value },
+	},
+	{
+		name: entity.FIELD_ZIP,
+		get:  func(company *entity.Companies) string { return company.Zip },
+		set:  func(company *entity.Companies, value string) { company.Zip = value },
+		// a postal code only makes sense along with its country
+		carry: func(to *entity.Companies, from *entity.Companies) { to.Country = from.Country },
+	},
+	{
+		name: entity.FIELD_WEBSITE,
+		get:  func(company *entity.Companies) string { return company.Website },
+		set:  func(company *entity.Companies, value string) { company.Website = value },
+	},
+}
+
+// DefaultMergePolicies keeps curated values from being clobbered by
+// lower-priority sources
+func DefaultMergePolicies() map[string]MergePolicy {
+	return map[string]MergePolicy{
+		entity.FIELD_NAME:    POLICY_OVERWRITE,
+		entity.FIELD_ZIP:     POLICY_PREFER_PRIORITY,
+		entity.FIELD_WEBSITE: POLICY_PREFER_PRIORITY,
+	}
+}
+
+func isMergePolicy(policy MergePolicy) bool {
+	switch policy {
+	case POLICY_OVERWRITE, POLICY_FILL_IF_EMPTY, POLICY_PREFER_PRIORITY, POLICY_NEWEST_WINS:
+		return true
+	}
+	return false
+}
+
+func isCoreField(field string) bool {
+	for _, companyField := range companyFields {
+		if companyField.name == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *CompanyService) isMergeField(field string) bool {
+	_, _, registered := s.attributes.lookup(field)
+	return isCoreField(field) || registered
+}
+
+// mergeFields lists the core fields followed by the registered attributes
+func (s *CompanyService) mergeFields() []companyField {
+	fields := append([]companyField{}, companyFields...)
+	for _, name := range s.attributes.names() {
+		fields = append(fields, attributeField(name))
+	}
+	return fields
+}
+
+// ParseMergePolicies reads policies written as "website=fill-if-empty,zipCode=overwrite"
+func ParseMergePolicies(value string) (map[string]MergePolicy, error) {
+	policies := map[string]MergePolicy{}
+
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %s", ERR_UNKNOWN_MERGE_POLICY, pair)
+		}
+		policies[strings.TrimSpace(parts[0])] = MergePolicy(strings.TrimSpace(parts[1]))
+	}
+	return policies, nil
+}
+
+// SetMergePolicies overrides the policy of the given fields, the others keep
+// their current policy
+func (s *CompanyService) SetMergePolicies(policies map[string]MergePolicy) error {
+	for field, policy := range policies {
+		if !s.isMergeField(field) {
+			return fmt.Errorf("%w: %s", ERR_UNKNOWN_MERGE_FIELD, field)
+		}
+		if !isMergePolicy(policy) {
+			return fmt.Errorf("%w: %s", ERR_UNKNOWN_MERGE_POLICY, policy)
+		}
+	}
+
+	for field, policy := range policies {
+		s.mergePolicies[field] = policy
+	}
+	return nil
+}
+
+// mergePolicy returns the configured policy of a field. Attributes without
+// one use the policy of their definition, or prefer-priority.
+func (s *CompanyService) mergePolicy(field string) MergePolicy {
+	if policy, ok := s.mergePolicies[field]; ok {
+		return policy
+	}
+	if definition, _, ok := s.attributes.lookup(field); ok {
+		if definition.MergePolicy != "" {
+			return MergePolicy(definition.MergePolicy)
+		}
+		return POLICY_PREFER_PRIORITY
+	}
+	return POLICY_OVERWRITE
+}
+
+// decide applies the policy of a field to an incoming value
+func decide(policy MergePolicy, stored string, incoming string, provenance *entity.Provenance, source entity.Source) (bool, string) {
+	if incoming == "" {
+		return false, "incoming value is empty"
+	}
+	if incoming == stored {
+		return false, "value is unchanged"
+	}
+
+	switch policy {
+	case POLICY_OVERWRITE:
+		return true, "incoming value overwrites"
+	case POLICY_FILL_IF_EMPTY:
+		if stored == "" {
+			return true, "stored value was empty"
+		}
+		return false, "stored value is kept"
+	case POLICY_PREFER_PRIORITY:
+		if provenance == nil {
+			return true, "stored value has no recorded source"
+		}
+		if source.Priority >= provenance.Priority {
+			return true, fmt.Sprintf("source priority %d is not lower than %d of %s", source.Priority, provenance.Priority, provenance.SourceSystem)
+		}
+		return false, fmt.Sprintf("source priority %d is lower than %d of %s", source.Priority, provenance.Priority, provenance.SourceSystem)
+	case POLICY_NEWEST_WINS:
+		if provenance == nil {
+			return true, "stored value has no recorded source"
+		}
+		if !source.Timestamp.Before(provenance.RecordedAt) {
+			return true, "incoming value is newer"
+		}
+		return false, "stored value is newer"
+	}
+	return false, ERR_UNKNOWN_MERGE_POLICY.Error()
+}
+
+// MergeCompany integrates the data sent by source into the company with the
+// same name. Every field is decided by its merge policy and the fields taken
+// from the source have it recorded as their origin. Nothing is written when
+// the merged company is not valid; otherwise the company, the crosswalk key
+// of the record and the provenance are written together.
+func (s *CompanyService) MergeCompany(ctx context.Context, company *entity.Companies, source entity.Source) (*entity.MergeResult, error) {
+	company.Name = strings.ToUpper(company.Name)
+	company.Attributes = NormalizeAttributes(company.Attributes)
+	canonicalizeWebsite(company)
+	normalizePostalCode(company)
+	result := &entity.MergeResult{Name: company.Name, Zip: company.Zip}
+
+	readCompany, matchedBy, err := s.matchCompany(ctx, company, source)
+	if errors.Is(err, ERR_AMBIGUOUS_LOCATION) {
+		return s.failMerge(result, entity.MERGE_STATUS_REJECTED, err)
+	}
+	if err != nil {
+		return s.failMerge(result, entity.MERGE_STATUS_FAILED, err)
+	}
+
+	if readCompany == nil {
+		return s.failMerge(result, entity.MERGE_STATUS_NOT_FOUND, ERR_COMPANY_NOT_EXISTS)
+	}
+	result.CompanyID = readCompany.ID
+	result.MatchedBy = matchedBy
+
+	provenance, err := s.GetProvenance(ctx, []uuid.UUID{readCompany.ID})
+	if err != nil {
+		return s.failMerge(result, entity.MERGE_STATUS_FAILED, err)
+	}
+
+	merged := *readCompany
+	applied := []string{}
+
+	for _, field := range s.mergeFields() {
+		policy := s.mergePolicy(field.name)
+
+		var stored *entity.Provenance
+		if record, ok := provenance[readCompany.ID][field.name]; ok {
+			stored = &record
+		}
+
+		apply, reason := decide(policy, field.get(readCompany), field.get(company), stored, source)
+		if matchedBy == entity.MATCH_WEBSITE && field.name == entity.FIELD_NAME && apply {
+			// a record found through its website may use a trading or
+			// misspelled name, it does not rename the company
+			apply, reason = false, "record matched by website, its name is not trusted"
+		}
+		if apply {
+			field.take(&merged, company)
+			applied = append(applied, field.name)
+		}
+
+		result.Fields = append(result.Fields, entity.FieldDecision{
+			Field:   field.name,
+			Policy:  string(policy),
+			Applied: apply,
+			Reason:  reason,
+		})
+	}
+
+	for _, name := range sortedKeys(company.Attributes) {
+		if _, _, registered := s.attributes.lookup(name); !registered {
+			result.Fields = append(result.Fields, entity.FieldDecision{
+				Field:  name,
+				Reason: ERR_UNKNOWN_ATTRIBUTE.Error(),
+			})
+		}
+	}
+
+	var crosswalk *entity.Crosswalk
+	if matchedBy != entity.MATCH_CROSSWALK {
+		crosswalk = crosswalkFor(company, readCompany.ID, source)
+	}
+
+	if len(applied) == 0 {
+		if crosswalk != nil {
+			err = s.dbRepository.SaveMergedCompany(ctx, nil, crosswalk, nil)
+			if err != nil {
+				err = fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+				return s.failMerge(result, entity.MERGE_STATUS_FAILED, err)
+			}
+		}
+		result.Status = entity.MERGE_STATUS_UNCHANGED
+		return result, nil
+	}
+
+	canonicalizeWebsite(&merged)
+	normalizePostalCode(&merged)
+	err = s.checkCompany(&merged)
+	if err != nil {
+		return s.failMerge(result, entity.MERGE_STATUS_REJECTED, err)
+	}
+
+	err = s.dbRepository.SaveMergedCompany(ctx, &merged, crosswalk, provenanceRecords(merged.ID, applied, source))
+	if err != nil {
+		err = fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+		return s.failMerge(result, entity.MERGE_STATUS_FAILED, err)
+	}
+	*company = merged
+
+	result.Status = entity.MERGE_STATUS_MERGED
+	return result, nil
+}
+
+func (s *CompanyService) failMerge(result *entity.MergeResult, status string, err error) (*entity.MergeResult, error) {
+	result.Status = status
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result, err
+}
+
+// MergeCompanies merges every record sent by source, reporting the outcome
+// of each one. Records are numbered from line 2, after the header.
+func (s *CompanyService) MergeCompanies(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+	report := &entity.MergeReport{
+		ImportJob:    source.ImportJob,
+		SourceSystem: source.System,
+		SourceFile:   source.File,
+		Results:      []entity.MergeResult{},
+	}
+
+	for index, company := range companies {
+		result, _ := s.MergeCompany(ctx, company, source)
+		result.Line = index + 2
+		addMergeResult(report, *result)
+	}
+	return report
+}
+
+func addMergeResult(report *entity.MergeReport, result entity.MergeResult) {
+	report.Total++
+	switch result.Status {
+	case entity.MERGE_STATUS_MERGED:
+		report.Merged++
+	case entity.MERGE_STATUS_UNCHANGED:
+		report.Unchanged++
+	case entity.MERGE_STATUS_NOT_FOUND:
+		report.NotFound++
+	case entity.MERGE_STATUS_REJECTED:
+		report.Rejected++
+	default:
+		report.Failed++
+	}
+	report.Results = append(report.Results, result)
+}
+
+// MergePolicies returns the policy of each mergeable field
+func (s *CompanyService) MergePolicies() map[string]MergePolicy {
+	policies := map[string]MergePolicy{}
+	for _, field := range s.mergeFields() {
+		policies[field.name] = s.mergePolicy(field.name)
+	}
+	return policies
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
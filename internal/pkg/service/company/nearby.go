@@ -0,0 +1,103 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+const (
+	EARTH_RADIUS_MILES = 3958.8
+	MAX_RADIUS_MILES   = 500
+	DEFAULT_PAGE_SIZE  = 50
+	MAX_PAGE_SIZE      = 500
+)
+
+var (
+	ERR_NOT_VALID_RADIUS = errors.New("Error: the radius must be greater than 0 and at most 500 miles")
+	ERR_NOT_VALID_PAGE   = errors.New("Error: the limit must be between 1 and 500 and the offset not negative")
+)
+
+// FindNearby returns a page of the companies within radiusMiles of the zip
+// code, closest first. A limit of 0 gives DEFAULT_PAGE_SIZE companies.
+func (s *CompanyService) FindNearby(ctx context.Context, zip string, radiusMiles float64, limit int, offset int) (*entity.NearbyPage, error) {
+	if math.IsNaN(radiusMiles) || radiusMiles <= 0 || radiusMiles > MAX_RADIUS_MILES {
+		return nil, ERR_NOT_VALID_RADIUS
+	}
+
+	if limit == 0 {
+		limit = DEFAULT_PAGE_SIZE
+	}
+	if limit < 0 || limit > MAX_PAGE_SIZE || offset < 0 {
+		return nil, ERR_NOT_VALID_PAGE
+	}
+
+	zip, err := NormalizePostalCode(entity.COUNTRY_US, zip)
+	if err != nil {
+		return nil, ERR_ZIP_UNKNOWN
+	}
+
+	center, ok := s.LookupZip(PostalBase(entity.COUNTRY_US, zip))
+	if !ok {
+		return nil, ERR_ZIP_UNKNOWN
+	}
+
+	query := boundingBox(center.Latitude, center.Longitude, radiusMiles)
+	query.Limit = limit
+	query.Offset = offset
+
+	references, total, err := s.dbRepository.ListCompaniesNearby(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	page := &entity.NearbyPage{
+		Zip:         zip,
+		RadiusMiles: radiusMiles,
+		Total:       total,
+		Limit:       limit,
+		Offset:      offset,
+		Companies:   []entity.NearbyCompany{},
+	}
+	for _, company := range references {
+		page.Companies = append(page.Companies, *company)
+	}
+	return page, nil
+}
+
+// boundingBox encloses the circle of radiusMiles around a point. When the
+// circle reaches a pole it spans every longitude, and when it crosses the
+// antimeridian its longitudes wrap around, the minimum then being east of
+// the maximum.
+func boundingBox(latitude float64, longitude float64, radiusMiles float64) entity.NearbyQuery {
+	angle := radiusMiles / EARTH_RADIUS_MILES
+	latitudeDelta := angle * 180 / math.Pi
+
+	query := entity.NearbyQuery{
+		Latitude:     latitude,
+		Longitude:    longitude,
+		RadiusMiles:  radiusMiles,
+		MinLatitude:  math.Max(latitude-latitudeDelta, -90),
+		MaxLatitude:  math.Min(latitude+latitudeDelta, 90),
+		MinLongitude: -180,
+		MaxLongitude: 180,
+	}
+
+	// widest longitude of the circle, reached north of its center
+	ratio := math.Sin(angle) / math.Cos(latitude*math.Pi/180)
+	if query.MaxLatitude < 90 && query.MinLatitude > -90 && ratio < 1 {
+		longitudeDelta := math.Asin(ratio) * 180 / math.Pi
+		query.MinLongitude = longitude - longitudeDelta
+		query.MaxLongitude = longitude + longitudeDelta
+		if query.MinLongitude < -180 {
+			query.MinLongitude += 360
+		}
+		if query.MaxLongitude > 180 {
+			query.MaxLongitude -= 360
+		}
+	}
+	return query
+}
@@ -0,0 +1,143 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// destination is the point distanceMiles away from a point along bearing
+func destination(latitude float64, longitude float64, bearing float64, distanceMiles float64) (float64, float64) {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	toDegrees := func(radians float64) float64 { return radians * 180 / math.Pi }
+
+	angle := distanceMiles / EARTH_RADIUS_MILES
+	latitude1, longitude1, theta := toRadians(latitude), toRadians(longitude), toRadians(bearing)
+
+	latitude2 := math.Asin(math.Sin(latitude1)*math.Cos(angle) + math.Cos(latitude1)*math.Sin(angle)*math.Cos(theta))
+	longitude2 := longitude1 + math.Atan2(math.Sin(theta)*math.Sin(angle)*math.Cos(latitude1), math.Cos(angle)-math.Sin(latitude1)*math.Sin(latitude2))
+	return toDegrees(latitude2), math.Remainder(toDegrees(longitude2), 360)
+}
+
+// inBox tells whether a point lies in the bounding box, which may cross the
+// antimeridian
+func inBox(box entity.NearbyQuery, latitude float64, longitude float64) bool {
+	if latitude < box.MinLatitude || latitude > box.MaxLatitude {
+		return false
+	}
+	if box.MinLongitude > box.MaxLongitude {
+		return longitude >= box.MinLongitude || longitude <= box.MaxLongitude
+	}
+	return longitude >= box.MinLongitude && longitude <= box.MaxLongitude
+}
+
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name        string
+		latitude    float64
+		longitude   float64
+		radiusMiles float64
+	}{
+		{"texas", 29.5, -98.5, 25},
+		{"alaska", 64.8, -147.7, 300},
+		{"small radius", 40.7, -74.0, 0.5},
+		{"east of the antimeridian", 51.9, 179.5, 100},
+		{"west of the antimeridian", -17.8, -179.9, 50},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			box := boundingBox(test.latitude, test.longitude, test.radiusMiles)
+
+			for bearing := 0.0; bearing < 360; bearing += 5 {
+				latitude, longitude := destination(test.latitude, test.longitude, bearing, test.radiusMiles*0.999)
+				if !inBox(box, latitude, longitude) {
+					t.Errorf("point %v, %v at bearing %v is outside of %+v", latitude, longitude, bearing, box)
+				}
+			}
+		})
+	}
+
+	t.Run("crossing the antimeridian", func(t *testing.T) {
+		box := boundingBox(51.9, 179.5, 100)
+
+		if box.MinLongitude > 180 || box.MaxLongitude < -180 || box.MinLongitude <= box.MaxLongitude {
+			t.Errorf("got %+v want the longitudes wrapped around", box)
+		}
+		if inBox(box, 51.9, 0) {
+			t.Errorf("got %+v holding the other side of the earth", box)
+		}
+	})
+
+	t.Run("reaching the pole", func(t *testing.T) {
+		box := boundingBox(89.9, 10, 50)
+
+		if box.MaxLatitude != 90 || box.MinLongitude != -180 || box.MaxLongitude != 180 {
+			t.Errorf("got %+v want every longitude", box)
+		}
+	})
+}
+
+func TestFindNearby(t *testing.T) {
+	zipRepository := func(nearby []*entity.NearbyCompany, gotQuery *entity.NearbyQuery) *MockCompanyRepository {
+		return &MockCompanyRepository{
+			ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+				return []*entity.ZipReference{{Zip: "78229", City: "SAN ANTONIO", State: "TX", Latitude: 29.5, Longitude: -98.5}}, nil
+			},
+			ListCompaniesNearbyMock: func(ctx context.Context, query entity.NearbyQuery) ([]*entity.NearbyCompany, int, error) {
+				*gotQuery = query
+				return nearby, len(nearby), nil
+			},
+		}
+	}
+
+	t.Run("closest companies", func(t *testing.T) {
+		company := entity.NearbyCompany{Companies: entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "78229"}, DistanceMiles: 0}
+		var gotQuery entity.NearbyQuery
+		service := NewCompanyService(zipRepository([]*entity.NearbyCompany{&company}, &gotQuery), nil)
+		service.LoadZipReference(context.Background())
+
+		got, err := service.FindNearby(context.Background(), "78229", 25, 0, 10)
+		want := &entity.NearbyPage{Zip: "78229", RadiusMiles: 25, Total: 1, Limit: DEFAULT_PAGE_SIZE, Offset: 10, Companies: []entity.NearbyCompany{company}}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+		if gotQuery.Latitude != 29.5 || gotQuery.Longitude != -98.5 || gotQuery.Limit != DEFAULT_PAGE_SIZE || gotQuery.Offset != 10 {
+			t.Errorf("got query %+v", gotQuery)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		var gotQuery entity.NearbyQuery
+		service := NewCompanyService(zipRepository(nil, &gotQuery), nil)
+		service.LoadZipReference(context.Background())
+
+		tests := []struct {
+			zip     string
+			radius  float64
+			limit   int
+			offset  int
+			wantErr error
+		}{
+			{"78229", 0, 10, 0, ERR_NOT_VALID_RADIUS},
+			{"78229", MAX_RADIUS_MILES + 1, 10, 0, ERR_NOT_VALID_RADIUS},
+			{"78229", 10, MAX_PAGE_SIZE + 1, 0, ERR_NOT_VALID_PAGE},
+			{"78229", 10, 10, -1, ERR_NOT_VALID_PAGE},
+			{"00000", 10, 10, 0, ERR_ZIP_UNKNOWN},
+		}
+		for _, test := range tests {
+			if _, err := service.FindNearby(context.Background(), test.zip, test.radius, test.limit, test.offset); !errors.Is(err, test.wantErr) {
+				t.Errorf("got %v error want %v", err, test.wantErr)
+			}
+		}
+	})
+}
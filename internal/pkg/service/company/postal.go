@@ -0,0 +1,152 @@
+package company
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var (
+	ERR_UNKNOWN_COUNTRY       = errors.New("Error: postal codes of this country are not supported")
+	ERR_NOT_VALID_POSTAL_CODE = errors.New("Error: the postal code is not valid for its country")
+)
+
+var supportedCountries = []string{entity.COUNTRY_US, entity.COUNTRY_CA, entity.COUNTRY_GB}
+
+var countryAliases = map[string]string{"USA": entity.COUNTRY_US, "CAN": entity.COUNTRY_CA, "UK": entity.COUNTRY_GB, "GBR": entity.COUNTRY_GB}
+
+var separators = strings.NewReplacer(" ", "", "-", "")
+
+// postalFormat validates the postal codes of a country, written without
+// spaces or dashes, and formats them
+type postalFormat struct {
+	pattern *regexp.Regexp
+	format  func(compact string) string
+	// base is the part of a formatted code that locates the company
+	base func(code string) string
+}
+
+func wholeCode(code string) string { return code }
+
+var postalFormats = map[string]postalFormat{
+	// 12345 or ZIP+4 12345-6789, matched on the first five digits
+	entity.COUNTRY_US: {
+		pattern: regexp.MustCompile(`^[0-9]{5}([0-9]{4})?$`),
+		format: func(compact string) string {
+			if len(compact) == 9 {
+				return compact[:5] + "-" + compact[5:]
+			}
+			return compact
+		},
+		base: func(code string) string { return code[:5] },
+	},
+	// A1A 1A1, letters D, F, I, O, Q and U are never used
+	entity.COUNTRY_CA: {
+		pattern: regexp.MustCompile(`^[ABCEGHJ-NPRSTVXY][0-9][ABCEGHJ-NPRSTV-Z][0-9][ABCEGHJ-NPRSTV-Z][0-9]$`),
+		format:  func(compact string) string { return compact[:3] + " " + compact[3:] },
+		base:    wholeCode,
+	},
+	// outward code (A9, A99, A9A, AA9, AA99, AA9A) and inward code (9AA)
+	entity.COUNTRY_GB: {
+		pattern: regexp.MustCompile(`^([A-Z]{1,2}[0-9][A-Z0-9]?|GIR)[0-9][A-Z]{2}$`),
+		format:  func(compact string) string { return compact[:len(compact)-3] + " " + compact[len(compact)-3:] },
+		base:    wholeCode,
+	},
+}
+
+// NormalizeCountry returns the ISO code of a supported country, US when the
+// country is not given
+func NormalizeCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country == "" {
+		return entity.COUNTRY_US, nil
+	}
+	if alias, ok := countryAliases[country]; ok {
+		country = alias
+	}
+	if _, ok := postalFormats[country]; !ok {
+		return "", fmt.Errorf("%w: %s", ERR_UNKNOWN_COUNTRY, country)
+	}
+	return country, nil
+}
+
+// NormalizePostalCode validates a postal code of the country and returns it
+// in its standard form, e.g. "k1a0b1" => "K1A 0B1", "123456789" => "12345-6789"
+func NormalizePostalCode(country string, code string) (string, error) {
+	country, err := NormalizeCountry(country)
+	if err != nil {
+		return "", err
+	}
+
+	format := postalFormats[country]
+	compact := separators.Replace(strings.ToUpper(strings.TrimSpace(code)))
+	if !format.pattern.MatchString(compact) {
+		return "", ERR_NOT_VALID_POSTAL_CODE
+	}
+	return format.format(compact), nil
+}
+
+// CheckPostalCodeValidity tells whether code is a valid postal code of the
+// country
+func CheckPostalCodeValidity(country string, code string) bool {
+	_, err := NormalizePostalCode(country, code)
+	return err == nil
+}
+
+// PostalBase is the part of a postal code companies are matched on: the five
+// digit zip code of a ZIP+4 and the whole code elsewhere. Codes that are not
+// valid are returned as given.
+func PostalBase(country string, code string) string {
+	normalized, err := NormalizePostalCode(country, code)
+	if err != nil {
+		return code
+	}
+	country, _ = NormalizeCountry(country)
+	return postalFormats[country].base(normalized)
+}
+
+// SearchPostalBase is the base of a postal code given without its country,
+// the first supported country it is valid for decides
+func SearchPostalBase(code string) string {
+	for _, country := range supportedCountries {
+		if CheckPostalCodeValidity(country, code) {
+			return PostalBase(country, code)
+		}
+	}
+	return strings.TrimSpace(code)
+}
+
+// normalizePostalCode writes the country and postal code of a company in
+// standard form. Invalid ones are left as they are for the validation to
+// reject.
+func normalizePostalCode(company *entity.Companies) {
+	country, err := NormalizeCountry(company.Country)
+	if err != nil {
+		return
+	}
+	company.Country = country
+
+	code, err := NormalizePostalCode(country, company.Zip)
+	if err != nil {
+		return
+	}
+	company.Zip = code
+}
+
+// postalKey identifies where a company is located, see PostalBase
+func postalKey(company *entity.Companies) string {
+	country, err := NormalizeCountry(company.Country)
+	if err != nil {
+		country = company.Country
+	}
+	return country + " " + PostalBase(country, company.Zip)
+}
+
+// samePostalCode tells whether two companies are located at the same postal
+// code, comparing ZIP+4 codes on their first five digits
+func samePostalCode(a *entity.Companies, b *entity.Companies) bool {
+	return postalKey(a) == postalKey(b)
+}
@@ -0,0 +1,131 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestNormalizePostalCode(t *testing.T) {
+	tests := []struct {
+		country string
+		code    string
+		want    string
+		wantErr error
+	}{
+		{"", "78229", "78229", nil},
+		{"US", "78229-1234", "78229-1234", nil},
+		{"usa", "782291234", "78229-1234", nil},
+		{"US", "7822", "", ERR_NOT_VALID_POSTAL_CODE},
+		{"US", "78229-12", "", ERR_NOT_VALID_POSTAL_CODE},
+		{"CA", "k1a0b1", "K1A 0B1", nil},
+		{"CA", "K1A-0B1", "K1A 0B1", nil},
+		{"CA", "D1A 0B1", "", ERR_NOT_VALID_POSTAL_CODE},
+		{"GB", "sw1a1aa", "SW1A 1AA", nil},
+		{"UK", "M1 1AE", "M1 1AE", nil},
+		{"GB", "EC1A 1BB", "EC1A 1BB", nil},
+		{"GB", "78229", "", ERR_NOT_VALID_POSTAL_CODE},
+		{"FR", "75001", "", ERR_UNKNOWN_COUNTRY},
+	}
+
+	for _, test := range tests {
+		got, err := NormalizePostalCode(test.country, test.code)
+
+		if !errors.Is(err, test.wantErr) {
+			t.Errorf("%v %v: got %v error want %v", test.country, test.code, err, test.wantErr)
+		}
+		if got != test.want {
+			t.Errorf("%v %v: got %v want %v", test.country, test.code, got, test.want)
+		}
+	}
+}
+
+func TestPostalBase(t *testing.T) {
+	tests := []struct {
+		country string
+		code    string
+		want    string
+	}{
+		{"US", "78229-1234", "78229"},
+		{"US", "78229", "78229"},
+		{"CA", "k1a 0b1", "K1A 0B1"},
+		{"GB", "SW1A 1AA", "SW1A 1AA"},
+		{"US", "not valid", "not valid"},
+	}
+
+	for _, test := range tests {
+		if got := PostalBase(test.country, test.code); got != test.want {
+			t.Errorf("%v %v: got %v want %v", test.country, test.code, got, test.want)
+		}
+	}
+
+	if got := SearchPostalBase("782291234"); got != "78229" {
+		t.Errorf("got %v want 78229", got)
+	}
+	if got := SearchPostalBase("sw1a1aa"); got != "SW1A 1AA" {
+		t.Errorf("got %v want SW1A 1AA", got)
+	}
+}
+
+func TestSamePostalCode(t *testing.T) {
+	if !samePostalCode(&entity.Companies{Zip: "78229"}, &entity.Companies{Country: "US", Zip: "78229-1234"}) {
+		t.Errorf("ZIP+4 should match its five digit zip code")
+	}
+	if samePostalCode(&entity.Companies{Country: "CA", Zip: "K1A 0B1"}, &entity.Companies{Country: "GB", Zip: "K1A 0B1"}) {
+		t.Errorf("postal codes of different countries should not match")
+	}
+}
+
+func TestMergeCompanyPostalCode(t *testing.T) {
+	downtown := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "78229", Country: "US", Version: 1}
+	toronto := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "M5V 3L9", Country: "CA", Version: 1}
+
+	var updated entity.Companies
+	repository := &MockCompanyRepository{
+		ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+			return nil, nil
+		},
+		SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+			return nil
+		},
+		ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+			return []*entity.Companies{downtown, toronto}, nil
+		},
+		ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+			return nil, nil
+		},
+		UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+			updated = company
+			return nil
+		},
+		SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	t.Run("ZIP+4 matches the location", func(t *testing.T) {
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "78229 1234", Website: "https://company.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.CompanyID != downtown.ID || updated.Zip != "78229-1234" {
+			t.Errorf("got %v, %v want %v, 78229-1234", result.CompanyID, updated.Zip, downtown.ID)
+		}
+	})
+
+	t.Run("postal code of another country", func(t *testing.T) {
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "m5v3l9", Country: "can", Website: "https://company.ca"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if result.CompanyID != toronto.ID || updated.Country != "CA" {
+			t.Errorf("got %v, %v want %v, CA", result.CompanyID, updated.Country, toronto.ID)
+		}
+	})
+}
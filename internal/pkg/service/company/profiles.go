@@ -0,0 +1,241 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+// DEFAULT_DELIMITER separates the columns of files read without a profile
+const DEFAULT_DELIMITER = ";"
+
+var (
+	ERR_NOT_VALID_IMPORT_PROFILE = errors.New("Error: import profile is not valid")
+	ERR_IMPORT_PROFILE_NOT_FOUND = errors.New("Error: there is no import profile with this name")
+	ERR_NOT_VALID_IMPORT_FILE    = errors.New("Error: the file does not match its import profile")
+)
+
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// importTransforms are the transforms a profile can apply to a value
+var importTransforms = map[string]func(value string) string{
+	"trim":            strings.TrimSpace,
+	"upper":           strings.ToUpper,
+	"lower":           strings.ToLower,
+	"collapse-spaces": func(value string) string { return strings.Join(strings.Fields(value), " ") },
+	// digits keeps only the digits, e.g. of "TX 78229" or a phone number
+	"digits": func(value string) string {
+		return strings.Map(func(r rune) rune {
+			if r >= '0' && r <= '9' {
+				return r
+			}
+			return -1
+		}, value)
+	},
+	// zero-pad-5 restores the leading zeros spreadsheets drop from zip codes
+	"zero-pad-5": func(value string) string {
+		if value != "" && len(value) < 5 && strings.Trim(value, "0123456789") == "" {
+			return strings.Repeat("0", 5-len(value)) + value
+		}
+		return value
+	},
+}
+
+// headerKey compares headers regardless of case, spaces, underscores and
+// dashes, so "Zip Code" and "zip_code" are the same header
+func headerKey(header string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(header)))
+}
+
+func isImportField(field string) bool {
+	switch field {
+	case entity.IMPORT_FIELD_NAME, entity.IMPORT_FIELD_ZIP, entity.IMPORT_FIELD_WEBSITE, entity.IMPORT_FIELD_COUNTRY, entity.IMPORT_FIELD_EXTERNAL_ID:
+		return true
+	}
+	return attributeNamePattern.MatchString(field)
+}
+
+func validateImportProfile(profile *entity.ImportProfile) error {
+	if !profileNamePattern.MatchString(profile.Name) {
+		return fmt.Errorf("%w: invalid name %q", ERR_NOT_VALID_IMPORT_PROFILE, profile.Name)
+	}
+
+	if profile.Delimiter == "" {
+		profile.Delimiter = DEFAULT_DELIMITER
+	}
+	delimiter, size := utf8.DecodeRuneInString(profile.Delimiter)
+	if size != len(profile.Delimiter) || delimiter == utf8.RuneError || delimiter == '"' || delimiter == '\r' || delimiter == '\n' {
+		return fmt.Errorf("%w: the delimiter must be a single character other than a quote or a line break", ERR_NOT_VALID_IMPORT_PROFILE)
+	}
+
+	mapsName := false
+	fields := map[string]bool{}
+	for header, field := range profile.Columns {
+		if !isImportField(field) {
+			return fmt.Errorf("%w: column %q maps to unknown field %q", ERR_NOT_VALID_IMPORT_PROFILE, header, field)
+		}
+		if fields[field] {
+			return fmt.Errorf("%w: several columns map to %q", ERR_NOT_VALID_IMPORT_PROFILE, field)
+		}
+		fields[field] = true
+		mapsName = mapsName || field == entity.IMPORT_FIELD_NAME
+	}
+	if !mapsName {
+		return fmt.Errorf("%w: no column maps to %q", ERR_NOT_VALID_IMPORT_PROFILE, entity.IMPORT_FIELD_NAME)
+	}
+
+	headers := map[string]string{}
+	for header := range profile.Columns {
+		headers[headerKey(header)] = header
+	}
+	for header, aliases := range profile.HeaderAliases {
+		if _, ok := profile.Columns[header]; !ok {
+			return fmt.Errorf("%w: aliases of %q, which is not a mapped column", ERR_NOT_VALID_IMPORT_PROFILE, header)
+		}
+		for _, alias := range aliases {
+			if other, ok := headers[headerKey(alias)]; ok && other != header {
+				return fmt.Errorf("%w: alias %q is already a header of %q", ERR_NOT_VALID_IMPORT_PROFILE, alias, other)
+			}
+			headers[headerKey(alias)] = header
+		}
+	}
+
+	for field, transforms := range profile.Transforms {
+		if !fields[field] {
+			return fmt.Errorf("%w: transforms of %q, which no column maps to", ERR_NOT_VALID_IMPORT_PROFILE, field)
+		}
+		for _, transform := range transforms {
+			if _, ok := importTransforms[transform]; !ok {
+				return fmt.Errorf("%w: unknown transform %q", ERR_NOT_VALID_IMPORT_PROFILE, transform)
+			}
+		}
+	}
+
+	if profile.Priority < 0 {
+		return fmt.Errorf("%w: the priority cannot be negative", ERR_NOT_VALID_IMPORT_PROFILE)
+	}
+	return nil
+}
+
+func (s *CompanyService) ListImportProfiles(ctx context.Context) ([]entity.ImportProfile, error) {
+	references, err := s.dbRepository.ListImportProfiles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	profiles := []entity.ImportProfile{}
+	for _, profile := range references {
+		profiles = append(profiles, *profile)
+	}
+	return profiles, nil
+}
+
+// GetImportProfile returns the profile with the name, or nil if there is none
+func (s *CompanyService) GetImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error) {
+	profile, err := s.dbRepository.ReadImportProfile(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	return profile, nil
+}
+
+// SaveImportProfile validates the profile and creates or replaces it
+func (s *CompanyService) SaveImportProfile(ctx context.Context, profile *entity.ImportProfile) error {
+	err := validateImportProfile(profile)
+	if err != nil {
+		return err
+	}
+
+	err = s.dbRepository.SaveImportProfile(ctx, *profile)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return nil
+}
+
+func (s *CompanyService) DeleteImportProfile(ctx context.Context, name string) error {
+	deleted, err := s.dbRepository.DeleteImportProfile(ctx, name)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	if !deleted {
+		return ERR_IMPORT_PROFILE_NOT_FOUND
+	}
+	return nil
+}
+
+// MapImportRows turns the rows of a file into companies following the
+// profile. The first row holds the headers; columns the profile does not map
+// are ignored and the file must have the column mapped to the name.
+func MapImportRows(profile *entity.ImportProfile, rows [][]string) ([]*entity.Companies, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: the file is empty", ERR_NOT_VALID_IMPORT_FILE)
+	}
+
+	headers := map[string]string{}
+	for header := range profile.Columns {
+		headers[headerKey(header)] = header
+	}
+	for header, aliases := range profile.HeaderAliases {
+		for _, alias := range aliases {
+			headers[headerKey(alias)] = header
+		}
+	}
+
+	fields := make([]string, len(rows[0]))
+	mapsName := false
+	for index, header := range rows[0] {
+		if mapped, ok := headers[headerKey(header)]; ok {
+			fields[index] = profile.Columns[mapped]
+			mapsName = mapsName || fields[index] == entity.IMPORT_FIELD_NAME
+		}
+	}
+	if !mapsName {
+		return nil, fmt.Errorf("%w: no column holds the %q", ERR_NOT_VALID_IMPORT_FILE, entity.IMPORT_FIELD_NAME)
+	}
+
+	companies := []*entity.Companies{}
+	for _, row := range rows[1:] {
+		company := &entity.Companies{}
+		for index, value := range row {
+			if index >= len(fields) || fields[index] == "" {
+				continue
+			}
+			for _, transform := range profile.Transforms[fields[index]] {
+				value = importTransforms[transform](value)
+			}
+			setImportField(company, fields[index], value)
+		}
+		companies = append(companies, company)
+	}
+	return companies, nil
+}
+
+func setImportField(company *entity.Companies, field string, value string) {
+	switch field {
+	case entity.IMPORT_FIELD_NAME:
+		company.Name = strings.ToUpper(value)
+	case entity.IMPORT_FIELD_ZIP:
+		company.Zip = value
+	case entity.IMPORT_FIELD_WEBSITE:
+		company.Website = value
+	case entity.IMPORT_FIELD_COUNTRY:
+		company.Country = strings.TrimSpace(value)
+	case entity.IMPORT_FIELD_EXTERNAL_ID:
+		company.ExternalID = strings.TrimSpace(value)
+	default:
+		if strings.TrimSpace(value) == "" {
+			return
+		}
+		if company.Attributes == nil {
+			company.Attributes = map[string]string{}
+		}
+		company.Attributes[field] = strings.TrimSpace(value)
+	}
+}
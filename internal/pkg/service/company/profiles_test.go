@@ -0,0 +1,145 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+func newImportProfile() *entity.ImportProfile {
+	return &entity.ImportProfile{
+		Name:          "crm",
+		HeaderAliases: map[string][]string{"Company": {"Company Name", "razao_social"}},
+		Columns:       map[string]string{"Company": entity.IMPORT_FIELD_NAME, "Postal": entity.IMPORT_FIELD_ZIP, "Site": entity.IMPORT_FIELD_WEBSITE, "Sector": "industry"},
+		Transforms:    map[string][]string{entity.IMPORT_FIELD_NAME: {"collapse-spaces"}, entity.IMPORT_FIELD_ZIP: {"digits", "zero-pad-5"}},
+	}
+}
+
+func TestSaveImportProfile(t *testing.T) {
+	t.Run("saving with the default delimiter", func(t *testing.T) {
+		var saved entity.ImportProfile
+		repository := &MockCompanyRepository{
+			SaveImportProfileMock: func(ctx context.Context, profile entity.ImportProfile) error {
+				saved = profile
+				return nil
+			},
+		}
+		service := NewCompanyService(repository, nil)
+
+		err := service.SaveImportProfile(context.Background(), newImportProfile())
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if saved.Name != "crm" || saved.Delimiter != DEFAULT_DELIMITER {
+			t.Errorf("got %v want crm with %q", saved, DEFAULT_DELIMITER)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, nil)
+
+		tests := []struct {
+			name   string
+			change func(profile *entity.ImportProfile)
+		}{
+			{"name", func(profile *entity.ImportProfile) { profile.Name = "Crm Export" }},
+			{"delimiter", func(profile *entity.ImportProfile) { profile.Delimiter = ";;" }},
+			{"quote delimiter", func(profile *entity.ImportProfile) { profile.Delimiter = `"` }},
+			{"unknown field", func(profile *entity.ImportProfile) { profile.Columns["Phone"] = "phone number" }},
+			{"duplicated field", func(profile *entity.ImportProfile) { profile.Columns["Url"] = entity.IMPORT_FIELD_WEBSITE }},
+			{"without name", func(profile *entity.ImportProfile) { delete(profile.Columns, "Company") }},
+			{"alias of unmapped header", func(profile *entity.ImportProfile) { profile.HeaderAliases["Phone"] = []string{"Tel"} }},
+			{"alias of another header", func(profile *entity.ImportProfile) { profile.HeaderAliases["Site"] = []string{"postal"} }},
+			{"unknown transform", func(profile *entity.ImportProfile) { profile.Transforms[entity.IMPORT_FIELD_ZIP] = []string{"reverse"} }},
+			{"transform of unmapped field", func(profile *entity.ImportProfile) {
+				profile.Transforms[entity.IMPORT_FIELD_COUNTRY] = []string{"upper"}
+			}},
+			{"negative priority", func(profile *entity.ImportProfile) { profile.Priority = -1 }},
+		}
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				profile := newImportProfile()
+				test.change(profile)
+
+				if err := service.SaveImportProfile(context.Background(), profile); !errors.Is(err, ERR_NOT_VALID_IMPORT_PROFILE) {
+					t.Errorf("got %v error want %v", err, ERR_NOT_VALID_IMPORT_PROFILE)
+				}
+			})
+		}
+	})
+}
+
+func TestDeleteImportProfile(t *testing.T) {
+	repository := &MockCompanyRepository{
+		DeleteImportProfileMock: func(ctx context.Context, name string) (bool, error) {
+			return name == "crm", nil
+		},
+	}
+	service := NewCompanyService(repository, nil)
+
+	if err := service.DeleteImportProfile(context.Background(), "crm"); err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if err := service.DeleteImportProfile(context.Background(), "erp"); !errors.Is(err, ERR_IMPORT_PROFILE_NOT_FOUND) {
+		t.Errorf("got %v error want %v", err, ERR_IMPORT_PROFILE_NOT_FOUND)
+	}
+}
+
+func TestMapImportRows(t *testing.T) {
+	t.Run("mapping the columns", func(t *testing.T) {
+		rows := [][]string{
+			{"COMPANY NAME", "postal", "Site", "Sector", "Phone"},
+			{"acme   corp", "TX 1234", "acme.com", "Retail", "555-0100"},
+			{"other", "78229", "", "", ""},
+		}
+
+		got, err := MapImportRows(newImportProfile(), rows)
+		want := []*entity.Companies{
+			{Name: "ACME CORP", Zip: "01234", Website: "acme.com", Attributes: map[string]string{"industry": "Retail"}},
+			{Name: "OTHER", Zip: "78229"},
+		}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("without the name column", func(t *testing.T) {
+		_, err := MapImportRows(newImportProfile(), [][]string{{"postal", "Site"}, {"78229", "acme.com"}})
+
+		if !errors.Is(err, ERR_NOT_VALID_IMPORT_FILE) {
+			t.Errorf("got %v error want %v", err, ERR_NOT_VALID_IMPORT_FILE)
+		}
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		if _, err := MapImportRows(newImportProfile(), nil); !errors.Is(err, ERR_NOT_VALID_IMPORT_FILE) {
+			t.Errorf("got %v error want %v", err, ERR_NOT_VALID_IMPORT_FILE)
+		}
+	})
+}
+
+func TestProfileSource(t *testing.T) {
+	t.Run("without a profile", func(t *testing.T) {
+		source := ProfileSource(SOURCE_CLIENT, "clients.csv", nil)
+
+		if source.System != SOURCE_CLIENT || source.Priority != SourcePriority(SOURCE_CLIENT) {
+			t.Errorf("got %v, %v want %v, %v", source.System, source.Priority, SOURCE_CLIENT, SourcePriority(SOURCE_CLIENT))
+		}
+	})
+
+	t.Run("with the profile of a partner", func(t *testing.T) {
+		source := ProfileSource(SOURCE_CLIENT, "partner.csv", &entity.ImportProfile{Name: "partner", Priority: 30})
+
+		if source.System != "client-csv:partner" || source.Priority != 30 {
+			t.Errorf("got %v, %v want client-csv:partner, 30", source.System, source.Priority)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package company
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// Source systems known by the service
+const (
+	SOURCE_API          = "api"
+	SOURCE_CATALOG      = "catalog"
+	SOURCE_CLIENT       = "client-csv"
+	SOURCE_WATCH_FOLDER = "watch-folder"
+)
+
+// sourcePriorities ranks the known source systems, curated data first.
+// Unknown systems get the client priority.
+var sourcePriorities = map[string]int{
+	SOURCE_API:          100,
+	SOURCE_CATALOG:      50,
+	SOURCE_CLIENT:       10,
+	SOURCE_WATCH_FOLDER: 10,
+}
+
+// SourcePriority returns the default priority of a source system
+func SourcePriority(system string) int {
+	if priority, ok := sourcePriorities[system]; ok {
+		return priority
+	}
+	return sourcePriorities[SOURCE_CLIENT]
+}
+
+// NewSource describes a write made now by system, starting a new import job
+func NewSource(system string, file string) entity.Source {
+	return entity.Source{
+		System:    system,
+		File:      file,
+		ImportJob: uuid.NewString(),
+		Priority:  SourcePriority(system),
+		Timestamp: time.Now().UTC(),
+	}
+}
+
+// ProfileSource describes a file of system read with the import profile.
+// The profile names the partner sending the file, so its name is part of
+// the source system, and its priority, when set, is the one of the source.
+func ProfileSource(system string, file string, profile *entity.ImportProfile) entity.Source {
+	source := NewSource(system, file)
+	if profile == nil {
+		return source
+	}
+
+	if profile.Name != "" {
+		source.System = system + ":" + profile.Name
+	}
+	if profile.Priority > 0 {
+		source.Priority = profile.Priority
+	}
+	return source
+}
+
+// changedFields lists the fields whose incoming value is set and differs
+// from the stored one
+func changedFields(stored *entity.Companies, incoming *entity.Companies) []string {
+	fields := []string{}
+
+	if incoming.Name != "" && incoming.Name != stored.Name {
+		fields = append(fields, entity.FIELD_NAME)
+	}
+	if incoming.Zip != "" && incoming.Zip != stored.Zip {
+		fields = append(fields, entity.FIELD_ZIP)
+	}
+	if incoming.Website != "" && incoming.Website != stored.Website {
+		fields = append(fields, entity.FIELD_WEBSITE)
+	}
+
+	for _, name := range sortedKeys(incoming.Attributes) {
+		if incoming.Attributes[name] != "" && incoming.Attributes[name] != stored.Attributes[name] {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+func (s *CompanyService) recordProvenance(ctx context.Context, companyID uuid.UUID, fields []string, source entity.Source) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	err := s.dbRepository.SaveProvenance(ctx, provenanceRecords(companyID, fields, source))
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return nil
+}
+
+// provenanceRecords records source as the origin of the given fields
+func provenanceRecords(companyID uuid.UUID, fields []string, source entity.Source) []entity.Provenance {
+	recordedAt := source.Timestamp
+	if recordedAt.IsZero() {
+		recordedAt = time.Now().UTC()
+	}
+
+	provenance := []entity.Provenance{}
+	for _, field := range fields {
+		provenance = append(provenance, entity.Provenance{
+			CompanyID:    companyID,
+			Field:        field,
+			SourceSystem: source.System,
+			SourceFile:   source.File,
+			ImportJob:    source.ImportJob,
+			Priority:     source.Priority,
+			RecordedAt:   recordedAt,
+		})
+	}
+	return provenance
+}
+
+// GetProvenance returns the provenance of the given companies indexed by
+// company ID and field
+func (s *CompanyService) GetProvenance(ctx context.Context, companyIDs []uuid.UUID) (map[uuid.UUID]map[string]entity.Provenance, error) {
+	provenance := map[uuid.UUID]map[string]entity.Provenance{}
+	if len(companyIDs) == 0 {
+		return provenance, nil
+	}
+
+	records, err := s.dbRepository.ReadProvenance(ctx, companyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	for _, record := range records {
+		if provenance[record.CompanyID] == nil {
+			provenance[record.CompanyID] = map[string]entity.Provenance{}
+		}
+		provenance[record.CompanyID][record.Field] = *record
+	}
+	return provenance, nil
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
 	"github.com/google/uuid"
@@ -14,9 +17,49 @@ import (
 type dbCompanyRepository interface {
 	AddCompany(ctx context.Context, company entity.Companies) error
 	ReadCompanyByName(ctx context.Context, name string) (*entity.Companies, error)
+	ReadCompanyLocations(ctx context.Context, name string) ([]*entity.Companies, error)
+	ReadParentCompany(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error)
 	SearchCompanyByNameAndZip(ctx context.Context, name string, zip string) (*entity.Companies, error)
 	UpdateCompany(ctx context.Context, company entity.Companies) error
 	DeleteCompany(ctx context.Context, company entity.Companies) error
+	ReadCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error)
+	RestoreCompany(ctx context.Context, id uuid.UUID) (bool, error)
+	ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error)
+	SaveProvenance(ctx context.Context, provenance []entity.Provenance) error
+	ReadProvenance(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error)
+	ListAttributeDefinitions(ctx context.Context) ([]*entity.AttributeDefinition, error)
+	SaveAttributeDefinition(ctx context.Context, definition entity.AttributeDefinition) error
+	SaveDuplicateCandidates(ctx context.Context, candidates []entity.DuplicateCandidate) ([]entity.DuplicateCandidate, error)
+	ListDuplicateCandidates(ctx context.Context, status string) ([]*entity.DuplicateCandidate, error)
+	ReadDuplicateCandidate(ctx context.Context, id uuid.UUID) (*entity.DuplicateCandidate, error)
+	ResolveDuplicateCandidate(ctx context.Context, id uuid.UUID, status string) (bool, error)
+	SaveMergedCompany(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error
+	MergeCompanyInto(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error
+	ConfirmDuplicateCandidate(ctx context.Context, candidateID uuid.UUID, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error
+	ReadCompaniesByDomain(ctx context.Context, domain string) ([]*entity.Companies, error)
+	SaveCrosswalk(ctx context.Context, crosswalk entity.Crosswalk) error
+	ReadCrosswalk(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error)
+	ListZipReferences(ctx context.Context) ([]*entity.ZipReference, error)
+	SaveZipReferences(ctx context.Context, references []entity.ZipReference) error
+	ListCompaniesNearby(ctx context.Context, query entity.NearbyQuery) ([]*entity.NearbyCompany, int, error)
+	ListImportProfiles(ctx context.Context) ([]*entity.ImportProfile, error)
+	ReadImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error)
+	SaveImportProfile(ctx context.Context, profile entity.ImportProfile) error
+	DeleteImportProfile(ctx context.Context, name string) (bool, error)
+	StreamCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error
+	ListCompanyChanges(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error)
+	ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error)
+	ReadWebhookSubscription(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	SaveWebhookSubscription(ctx context.Context, subscription entity.WebhookSubscription) error
+	DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error)
+	ListPendingEvents(ctx context.Context, limit int) ([]*entity.CompanyEvent, error)
+	QueueWebhookDeliveries(ctx context.Context, eventIDs []int64, deliveries []entity.WebhookDelivery) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error)
+	SaveWebhookDeliveryAttempt(ctx context.Context, delivery entity.WebhookDelivery) error
+	ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string) ([]*entity.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, id uuid.UUID) (bool, error)
+	ReadIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error)
+	SaveIngestedFile(ctx context.Context, file entity.IngestedFile) error
 }
 type csvCompanyRepository interface {
 	GetCompany(ctx context.Context, key string) ([]*entity.Companies, error)
@@ -30,16 +73,23 @@ type CompanyRepository interface {
 type CompanyService struct {
 	dbRepository  CompanyRepository
 	csvRepository csvCompanyRepository
+	mergePolicies map[string]MergePolicy
+	attributes    *attributeSchema
+	matchOrder    []string
+	zips          map[string]entity.ZipReference
+	webhookClient *http.Client
 }
 
 var (
-	ERR_COMPANY_NOT_EXISTS      = errors.New("Erro: there is no company with this name")
+	ERR_COMPANY_NOT_EXISTS      = entity.ERR_COMPANY_NOT_EXISTS
 	ERR_COMPANY_EXISTS          = errors.New("Erro: there is a company with this name")
 	ERR_WHILE_MATCHING_NAME     = errors.New("Error while matching company Name")
 	ERR_WHILE_MATCHING_ZIP      = errors.New("Error while matching company ZIP")
 	ERR_WHILE_WRITING           = errors.New("Error while writing company")
 	ERR_NOT_VALID_COMPANY       = errors.New("Error: There is invalid company camps")
 	ERR_WHILE_GETTING_COMPANIES = errors.New("Error while getting companies from repository")
+	ERR_COMPANY_NOT_DELETED     = errors.New("Error: there is no deleted company with this ID")
+	ERR_VERSION_CONFLICT        = entity.ERR_VERSION_CONFLICT
 )
 
 func CheckNameValidity(name string) (bool, error) {
@@ -51,19 +101,27 @@ func CheckNameValidity(name string) (bool, error) {
 	return ok, nil
 }
 
-func CheckZipValidity(zip string) (bool, error) {
+// CheckZipValidity tells whether zip is a five digit zip code of the zip
+// code reference. Until a reference is loaded only the format is checked.
+func (s *CompanyService) CheckZipValidity(zip string) (bool, error) {
 	ok, err := regexp.MatchString("^[0-9]{5}$", zip)
 	if err != nil {
 		err = fmt.Errorf("%v: %w", ERR_WHILE_MATCHING_ZIP, err)
 		return false, err
 	}
-	return ok, nil
+	if !ok || len(s.zips) == 0 {
+		return ok, nil
+	}
+
+	_, known := s.zips[zip]
+	return known, nil
 }
 
+// CheckWebsiteValidity tells whether website is an http or https URL,
+// see CanonicalizeWebsite
 func CheckWebsiteValidity(website string) bool {
-	register := regexp.MustCompile(`(?mi)^((http:\/\/www\.|https:\/\/www\.|http:\/\/|https:\/\/)[a-z0-9]+([\-\.]{1}[a-z0-9]+)*\.[a-z]{2,5}(:[0-9]{1,5})?(\/.*)?)?$`)
-	ok := register.MatchString(website)
-	return ok
+	_, _, err := CanonicalizeWebsite(website)
+	return err == nil
 }
 
 func (s *CompanyService) InitializeDataBase(ctx context.Context, key string) error {
@@ -81,18 +139,26 @@ func (s *CompanyService) InitializeDataBase(ctx context.Context, key string) err
 
 		}
 
+		source := NewSource(SOURCE_CATALOG, filepath.Base(key))
+
 		for _, company := range companies {
 			company.ID = uuid.New()
+			company.Attributes = NormalizeAttributes(company.Attributes)
+			canonicalizeWebsite(company)
+			normalizePostalCode(company)
 
-			ok, _ := CheckAllValidity(company)
-
-			if ok {
+			if s.checkCatalogCompany(company) == nil {
 				company.ID = uuid.New()
 				err = s.dbRepository.AddCompany(ctx, *company)
 
 				if err != nil {
 					return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
 				}
+
+				err = s.recordProvenance(ctx, company.ID, changedFields(&entity.Companies{}, company), source)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -106,26 +172,31 @@ func (s *CompanyService) UpdateDataBaseFromCSV(ctx context.Context, key string)
 		return err
 	}
 
-	for _, company := range companies {
-		s.UpdateCompany(ctx, company)
-	}
+	source := NewSource(SOURCE_CLIENT, filepath.Base(key))
+
+	s.MergeCompanies(ctx, companies, source)
 
 	return nil
 }
 
+// CheckAllValidity tells whether the name, postal code and website of the
+// company are valid. The website is required.
 func CheckAllValidity(company *entity.Companies) (bool, error) {
+	return checkValidity(company, true)
+}
+
+// checkValidity checks the fields of a company, leaving a missing website
+// alone unless requireWebsite is set
+func checkValidity(company *entity.Companies, requireWebsite bool) (bool, error) {
 	CompanyNameIsValid, err := CheckNameValidity(company.Name)
 	if err != nil {
 		err = fmt.Errorf("%v: %w", ERR_WHILE_MATCHING_NAME, err)
 		return false, err
 	}
-	CompanyZIPIsValid, err := CheckZipValidity(company.Zip)
-	if err != nil {
-		err = fmt.Errorf("%v: %w", ERR_WHILE_MATCHING_ZIP, err)
-		return false, err
-	}
+	// the postal code follows the format of the country, US by default
+	CompanyZIPIsValid := CheckPostalCodeValidity(company.Country, company.Zip)
 
-	CompanyWebsiteIsValid := CheckWebsiteValidity(company.Website)
+	CompanyWebsiteIsValid := CheckWebsiteValidity(company.Website) || (!requireWebsite && company.Website == "")
 
 	if !CompanyNameIsValid || !CompanyZIPIsValid || !CompanyWebsiteIsValid {
 		return false, ERR_NOT_VALID_COMPANY
@@ -134,11 +205,36 @@ func CheckAllValidity(company *entity.Companies) (bool, error) {
 	return true, nil
 }
 
+// checkCompany validates the core fields and the attributes of a company,
+// setting its city and state from the zip code reference
+func (s *CompanyService) checkCompany(company *entity.Companies) error {
+	return s.checkFields(company, true)
+}
+
+// checkCatalogCompany checks a company of the catalog file, which has no
+// website column: its companies get their website from the merges
+func (s *CompanyService) checkCatalogCompany(company *entity.Companies) error {
+	return s.checkFields(company, false)
+}
+
+func (s *CompanyService) checkFields(company *entity.Companies, requireWebsite bool) error {
+	s.locateZip(company)
+	ok, err := checkValidity(company, requireWebsite)
+	if !ok {
+		return err
+	}
+	if err := s.checkZipKnown(company); err != nil {
+		return err
+	}
+	return s.CheckAttributesValidity(company.Attributes)
+}
+
 func (s *CompanyService) AddCompany(ctx context.Context, company *entity.Companies) error {
 	company.Name = strings.ToUpper(company.Name)
+	normalizePostalCode(company)
 
 	//fmt.Printf("service.go ID: %s, name: %s, zip: %s, webmail:%s\n", company.ID, company.Name, company.Zip, company.Website)
-	readCompany, err := s.dbRepository.SearchCompanyByNameAndZip(ctx, company.Name, company.Zip)
+	readCompany, err := s.dbRepository.SearchCompanyByNameAndZip(ctx, company.Name, PostalBase(company.Country, company.Zip))
 	if err != nil {
 		err = fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
 		return err
@@ -148,9 +244,10 @@ func (s *CompanyService) AddCompany(ctx context.Context, company *entity.Compani
 		return ERR_COMPANY_EXISTS
 	}
 
-	ok, err := CheckAllValidity(company)
-
-	if !ok {
+	company.Attributes = NormalizeAttributes(company.Attributes)
+	canonicalizeWebsite(company)
+	err = s.checkCompany(company)
+	if err != nil {
 		return err
 	}
 
@@ -162,38 +259,75 @@ func (s *CompanyService) AddCompany(ctx context.Context, company *entity.Compani
 		return err
 	}
 
-	return nil
+	return s.recordProvenance(ctx, company.ID, changedFields(&entity.Companies{}, company), NewSource(SOURCE_API, ""))
 }
 
 func (s *CompanyService) UpdateCompany(ctx context.Context, company *entity.Companies) error {
+	_, err := s.MergeCompany(ctx, company, NewSource(SOURCE_API, ""))
+	return err
+}
+
+// ReplaceCompany overwrites the company identified by company.ID. A zero
+// company.Version replaces whatever version is stored, any other value must
+// match the stored version or ERR_VERSION_CONFLICT is returned. A company
+// left as it is stored is not written, keeping its version.
+func (s *CompanyService) ReplaceCompany(ctx context.Context, company *entity.Companies) error {
 	company.Name = strings.ToUpper(company.Name)
 
-	readCompany, err := s.dbRepository.ReadCompanyByName(ctx, company.Name)
+	readCompany, err := s.dbRepository.ReadCompanyByID(ctx, company.ID, false)
 	if err != nil {
-		err = fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
-		return err
+		return fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
 	}
 
 	if readCompany == nil {
 		return ERR_COMPANY_NOT_EXISTS
 	}
-
+	// the ID may be the one of a company merged into readCompany
 	company.ID = readCompany.ID
 
-	ok, err := CheckAllValidity(company)
-	if !ok {
-		return err
+	if company.Version == 0 {
+		company.Version = readCompany.Version
 	}
 
-	err = s.dbRepository.UpdateCompany(ctx, *company)
-
+	company.Attributes = NormalizeAttributes(company.Attributes)
+	canonicalizeWebsite(company)
+	normalizePostalCode(company)
+	err = s.checkCompany(company)
 	if err != nil {
-		err = fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
 		return err
 	}
+	if company.Version == readCompany.Version && sameWritableFields(readCompany, company) {
+		return nil
+	}
+
+	provenance := provenanceRecords(company.ID, changedFields(readCompany, company), NewSource(SOURCE_API, ""))
+	err = s.dbRepository.SaveMergedCompany(ctx, company, nil, provenance)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
 	return nil
 }
 
+// sameWritableFields tells whether the fields the API writes are the stored
+// ones
+func sameWritableFields(stored *entity.Companies, company *entity.Companies) bool {
+	if company.Name != stored.Name || company.Zip != stored.Zip || company.Website != stored.Website {
+		return false
+	}
+	if country, _ := NormalizeCountry(stored.Country); company.Country != country {
+		return false
+	}
+	if len(company.Attributes) != len(stored.Attributes) {
+		return false
+	}
+	for name, value := range company.Attributes {
+		if storedValue, ok := stored.Attributes[name]; !ok || storedValue != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *CompanyService) DeleteCompany(ctx context.Context, entity entity.Companies) error {
 	err := s.dbRepository.DeleteCompany(ctx, entity)
 	if err != nil {
@@ -202,6 +336,64 @@ func (s *CompanyService) DeleteCompany(ctx context.Context, entity entity.Compan
 	return nil
 }
 
+func (s *CompanyService) RestoreCompany(ctx context.Context, id uuid.UUID) error {
+	restored, err := s.dbRepository.RestoreCompany(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	if !restored {
+		return ERR_COMPANY_NOT_DELETED
+	}
+	return nil
+}
+
+func (s *CompanyService) GetCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+	company, err := s.dbRepository.ReadCompanyByID(ctx, id, includeDeleted)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	return company, nil
+}
+
+// normalizeFilter spells the name, country and zip code of the filter the way
+// they are stored
+func normalizeFilter(filter entity.CompanyFilter) (entity.CompanyFilter, error) {
+	filter.Name = strings.ToUpper(filter.Name)
+	if filter.Country != "" {
+		country, err := NormalizeCountry(filter.Country)
+		if err != nil {
+			return filter, err
+		}
+		filter.Country = country
+	}
+	if filter.Zip != "" {
+		filter.Zip = SearchPostalBase(filter.Zip)
+		if filter.Country != "" {
+			filter.Zip = PostalBase(filter.Country, filter.Zip)
+		}
+	}
+	return filter, nil
+}
+
+func (s *CompanyService) ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]entity.Companies, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	companiesReferences, err := s.dbRepository.ListCompanies(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	companies := []entity.Companies{}
+	for _, values := range companiesReferences {
+		companies = append(companies, *values)
+	}
+	return companies, nil
+}
+
 func (s *CompanyService) GetCompanies() ([]entity.Companies, error) {
 	companiesReferences, err := s.dbRepository.GetCompany(context.Background(), "")
 	var companies []entity.Companies
@@ -212,7 +404,7 @@ func (s *CompanyService) GetCompanies() ([]entity.Companies, error) {
 }
 
 func (s *CompanyService) FindByNameAndZip(name string, zip string) (*entity.Companies, error) {
-	companies, err := s.dbRepository.SearchCompanyByNameAndZip(context.Background(), name, zip)
+	companies, err := s.dbRepository.SearchCompanyByNameAndZip(context.Background(), name, SearchPostalBase(zip))
 	return companies, err
 }
 
@@ -225,5 +417,9 @@ func NewCompanyService(dbRepository CompanyRepository, csvRepository csvCompanyR
 	return &CompanyService{
 		dbRepository:  dbRepository,
 		csvRepository: csvRepository,
+		mergePolicies: DefaultMergePolicies(),
+		attributes:    newAttributeSchema(),
+		matchOrder:    DefaultMatchStrategies(),
+		webhookClient: &http.Client{Timeout: WEBHOOK_TIMEOUT},
 	}
 }
@@ -5,18 +5,285 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/eduardojabes/data-integration-challenge/entity"
 	"github.com/google/uuid"
 )
 
 type MockCompanyRepository struct {
-	AddCompanyMock                func(ctx context.Context, company entity.Companies) error
-	ReadCompanyByNameMock         func(ctx context.Context, name string) (*entity.Companies, error)
-	SearchCompanyByNameAndZipMock func(ctx context.Context, name string, zip string) (*entity.Companies, error)
-	UpdateCompanyMock             func(ctx context.Context, company entity.Companies) error
-	GetCompanyMock                func(ctx context.Context, key string) ([]*entity.Companies, error)
-	DeleteCompanyMock             func(ctx context.Context, company entity.Companies) error
+	AddCompanyMock                 func(ctx context.Context, company entity.Companies) error
+	ReadCompanyByNameMock          func(ctx context.Context, name string) (*entity.Companies, error)
+	ReadCompanyLocationsMock       func(ctx context.Context, name string) ([]*entity.Companies, error)
+	ReadParentCompanyMock          func(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error)
+	ReadCompaniesByDomainMock      func(ctx context.Context, domain string) ([]*entity.Companies, error)
+	SearchCompanyByNameAndZipMock  func(ctx context.Context, name string, zip string) (*entity.Companies, error)
+	UpdateCompanyMock              func(ctx context.Context, company entity.Companies) error
+	GetCompanyMock                 func(ctx context.Context, key string) ([]*entity.Companies, error)
+	DeleteCompanyMock              func(ctx context.Context, company entity.Companies) error
+	ReadCompanyByIDMock            func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error)
+	RestoreCompanyMock             func(ctx context.Context, id uuid.UUID) (bool, error)
+	ListCompaniesMock              func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error)
+	SaveProvenanceMock             func(ctx context.Context, provenance []entity.Provenance) error
+	ReadProvenanceMock             func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error)
+	ListAttributeDefinitionsMock   func(ctx context.Context) ([]*entity.AttributeDefinition, error)
+	SaveAttributeDefinitionMock    func(ctx context.Context, definition entity.AttributeDefinition) error
+	SaveDuplicateCandidatesMock    func(ctx context.Context, candidates []entity.DuplicateCandidate) ([]entity.DuplicateCandidate, error)
+	ListDuplicateCandidatesMock    func(ctx context.Context, status string) ([]*entity.DuplicateCandidate, error)
+	ReadDuplicateCandidateMock     func(ctx context.Context, id uuid.UUID) (*entity.DuplicateCandidate, error)
+	ResolveDuplicateCandidateMock  func(ctx context.Context, id uuid.UUID, status string) (bool, error)
+	SaveMergedCompanyMock          func(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error
+	MergeCompanyIntoMock           func(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error
+	ConfirmDuplicateCandidateMock  func(ctx context.Context, candidateID uuid.UUID, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error
+	SaveCrosswalkMock              func(ctx context.Context, crosswalk entity.Crosswalk) error
+	ReadCrosswalkMock              func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error)
+	ListZipReferencesMock          func(ctx context.Context) ([]*entity.ZipReference, error)
+	SaveZipReferencesMock          func(ctx context.Context, references []entity.ZipReference) error
+	ListCompaniesNearbyMock        func(ctx context.Context, query entity.NearbyQuery) ([]*entity.NearbyCompany, int, error)
+	ListImportProfilesMock         func(ctx context.Context) ([]*entity.ImportProfile, error)
+	ReadImportProfileMock          func(ctx context.Context, name string) (*entity.ImportProfile, error)
+	SaveImportProfileMock          func(ctx context.Context, profile entity.ImportProfile) error
+	DeleteImportProfileMock        func(ctx context.Context, name string) (bool, error)
+	StreamCompaniesMock            func(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error
+	ListCompanyChangesMock         func(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error)
+	ListWebhookSubscriptionsMock   func(ctx context.Context) ([]*entity.WebhookSubscription, error)
+	ReadWebhookSubscriptionMock    func(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error)
+	SaveWebhookSubscriptionMock    func(ctx context.Context, subscription entity.WebhookSubscription) error
+	DeleteWebhookSubscriptionMock  func(ctx context.Context, id uuid.UUID) (bool, error)
+	ListPendingEventsMock          func(ctx context.Context, limit int) ([]*entity.CompanyEvent, error)
+	QueueWebhookDeliveriesMock     func(ctx context.Context, eventIDs []int64, deliveries []entity.WebhookDelivery) error
+	ClaimWebhookDeliveriesMock     func(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error)
+	SaveWebhookDeliveryAttemptMock func(ctx context.Context, delivery entity.WebhookDelivery) error
+	ListWebhookDeliveriesMock      func(ctx context.Context, subscriptionID uuid.UUID, status string) ([]*entity.WebhookDelivery, error)
+	RetryWebhookDeliveryMock       func(ctx context.Context, id uuid.UUID) (bool, error)
+	ReadIngestedFileMock           func(ctx context.Context, checksum string) (*entity.IngestedFile, error)
+	SaveIngestedFileMock           func(ctx context.Context, file entity.IngestedFile) error
+}
+
+func (mcr *MockCompanyRepository) ReadIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error) {
+	if mcr.ReadIngestedFileMock != nil {
+		return mcr.ReadIngestedFileMock(ctx, checksum)
+	}
+	return nil, errors.New("ReadIngestedFileMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveIngestedFile(ctx context.Context, file entity.IngestedFile) error {
+	if mcr.SaveIngestedFileMock != nil {
+		return mcr.SaveIngestedFileMock(ctx, file)
+	}
+	return errors.New("SaveIngestedFileMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListWebhookSubscriptions(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+	if mcr.ListWebhookSubscriptionsMock != nil {
+		return mcr.ListWebhookSubscriptionsMock(ctx)
+	}
+	return nil, errors.New("ListWebhookSubscriptionsMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadWebhookSubscription(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	if mcr.ReadWebhookSubscriptionMock != nil {
+		return mcr.ReadWebhookSubscriptionMock(ctx, id)
+	}
+	return nil, errors.New("ReadWebhookSubscriptionMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveWebhookSubscription(ctx context.Context, subscription entity.WebhookSubscription) error {
+	if mcr.SaveWebhookSubscriptionMock != nil {
+		return mcr.SaveWebhookSubscriptionMock(ctx, subscription)
+	}
+	return errors.New("SaveWebhookSubscriptionMock must be set")
+}
+
+func (mcr *MockCompanyRepository) DeleteWebhookSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	if mcr.DeleteWebhookSubscriptionMock != nil {
+		return mcr.DeleteWebhookSubscriptionMock(ctx, id)
+	}
+	return false, errors.New("DeleteWebhookSubscriptionMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListPendingEvents(ctx context.Context, limit int) ([]*entity.CompanyEvent, error) {
+	if mcr.ListPendingEventsMock != nil {
+		return mcr.ListPendingEventsMock(ctx, limit)
+	}
+	return nil, errors.New("ListPendingEventsMock must be set")
+}
+
+func (mcr *MockCompanyRepository) QueueWebhookDeliveries(ctx context.Context, eventIDs []int64, deliveries []entity.WebhookDelivery) error {
+	if mcr.QueueWebhookDeliveriesMock != nil {
+		return mcr.QueueWebhookDeliveriesMock(ctx, eventIDs, deliveries)
+	}
+	return errors.New("QueueWebhookDeliveriesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	if mcr.ClaimWebhookDeliveriesMock != nil {
+		return mcr.ClaimWebhookDeliveriesMock(ctx, limit, lease)
+	}
+	return nil, errors.New("ClaimWebhookDeliveriesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveWebhookDeliveryAttempt(ctx context.Context, delivery entity.WebhookDelivery) error {
+	if mcr.SaveWebhookDeliveryAttemptMock != nil {
+		return mcr.SaveWebhookDeliveryAttemptMock(ctx, delivery)
+	}
+	return errors.New("SaveWebhookDeliveryAttemptMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListWebhookDeliveries(ctx context.Context, subscriptionID uuid.UUID, status string) ([]*entity.WebhookDelivery, error) {
+	if mcr.ListWebhookDeliveriesMock != nil {
+		return mcr.ListWebhookDeliveriesMock(ctx, subscriptionID, status)
+	}
+	return nil, errors.New("ListWebhookDeliveriesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) RetryWebhookDelivery(ctx context.Context, id uuid.UUID) (bool, error) {
+	if mcr.RetryWebhookDeliveryMock != nil {
+		return mcr.RetryWebhookDeliveryMock(ctx, id)
+	}
+	return false, errors.New("RetryWebhookDeliveryMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListCompanyChanges(ctx context.Context, since int64, limit int) ([]*entity.CompanyChange, error) {
+	if mcr.ListCompanyChangesMock != nil {
+		return mcr.ListCompanyChangesMock(ctx, since, limit)
+	}
+	return nil, errors.New("ListCompanyChangesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) StreamCompanies(ctx context.Context, filter entity.CompanyFilter, each func(company *entity.Companies) error) error {
+	if mcr.StreamCompaniesMock != nil {
+		return mcr.StreamCompaniesMock(ctx, filter, each)
+	}
+	return errors.New("StreamCompaniesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListImportProfiles(ctx context.Context) ([]*entity.ImportProfile, error) {
+	if mcr.ListImportProfilesMock != nil {
+		return mcr.ListImportProfilesMock(ctx)
+	}
+	return nil, errors.New("ListImportProfilesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error) {
+	if mcr.ReadImportProfileMock != nil {
+		return mcr.ReadImportProfileMock(ctx, name)
+	}
+	return nil, errors.New("ReadImportProfileMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveImportProfile(ctx context.Context, profile entity.ImportProfile) error {
+	if mcr.SaveImportProfileMock != nil {
+		return mcr.SaveImportProfileMock(ctx, profile)
+	}
+	return errors.New("SaveImportProfileMock must be set")
+}
+
+func (mcr *MockCompanyRepository) DeleteImportProfile(ctx context.Context, name string) (bool, error) {
+	if mcr.DeleteImportProfileMock != nil {
+		return mcr.DeleteImportProfileMock(ctx, name)
+	}
+	return false, errors.New("DeleteImportProfileMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListCompaniesNearby(ctx context.Context, query entity.NearbyQuery) ([]*entity.NearbyCompany, int, error) {
+	if mcr.ListCompaniesNearbyMock != nil {
+		return mcr.ListCompaniesNearbyMock(ctx, query)
+	}
+	return nil, 0, errors.New("ListCompaniesNearbyMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListZipReferences(ctx context.Context) ([]*entity.ZipReference, error) {
+	if mcr.ListZipReferencesMock != nil {
+		return mcr.ListZipReferencesMock(ctx)
+	}
+	return nil, errors.New("ListZipReferencesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveZipReferences(ctx context.Context, references []entity.ZipReference) error {
+	if mcr.SaveZipReferencesMock != nil {
+		return mcr.SaveZipReferencesMock(ctx, references)
+	}
+	return errors.New("SaveZipReferencesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveCrosswalk(ctx context.Context, crosswalk entity.Crosswalk) error {
+	if mcr.SaveCrosswalkMock != nil {
+		return mcr.SaveCrosswalkMock(ctx, crosswalk)
+	}
+	return errors.New("SaveCrosswalkMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadCrosswalk(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+	if mcr.ReadCrosswalkMock != nil {
+		return mcr.ReadCrosswalkMock(ctx, sourceSystem, externalKey)
+	}
+	return nil, errors.New("ReadCrosswalkMock must be set")
+}
+
+func (mcr *MockCompanyRepository) MergeCompanyInto(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error {
+	if mcr.MergeCompanyIntoMock != nil {
+		return mcr.MergeCompanyIntoMock(ctx, survivor, merged, provenance)
+	}
+	return errors.New("MergeCompanyIntoMock must be set")
+}
+
+// SaveMergedCompany writes through the UpdateCompany, SaveCrosswalk and
+// SaveProvenance mocks unless SaveMergedCompanyMock is set, the way the
+// repository does in a single transaction
+func (mcr *MockCompanyRepository) SaveMergedCompany(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error {
+	if mcr.SaveMergedCompanyMock != nil {
+		return mcr.SaveMergedCompanyMock(ctx, company, crosswalk, provenance)
+	}
+	if company != nil {
+		if err := mcr.UpdateCompany(ctx, *company); err != nil {
+			return err
+		}
+	}
+	if crosswalk != nil {
+		if err := mcr.SaveCrosswalk(ctx, *crosswalk); err != nil {
+			return err
+		}
+	}
+	if len(provenance) == 0 {
+		return nil
+	}
+	return mcr.SaveProvenance(ctx, provenance)
+}
+
+func (mcr *MockCompanyRepository) ConfirmDuplicateCandidate(ctx context.Context, candidateID uuid.UUID, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error {
+	if mcr.ConfirmDuplicateCandidateMock != nil {
+		return mcr.ConfirmDuplicateCandidateMock(ctx, candidateID, survivor, merged, provenance)
+	}
+	return errors.New("ConfirmDuplicateCandidateMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveDuplicateCandidates(ctx context.Context, candidates []entity.DuplicateCandidate) ([]entity.DuplicateCandidate, error) {
+	if mcr.SaveDuplicateCandidatesMock != nil {
+		return mcr.SaveDuplicateCandidatesMock(ctx, candidates)
+	}
+	return nil, errors.New("SaveDuplicateCandidatesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListDuplicateCandidates(ctx context.Context, status string) ([]*entity.DuplicateCandidate, error) {
+	if mcr.ListDuplicateCandidatesMock != nil {
+		return mcr.ListDuplicateCandidatesMock(ctx, status)
+	}
+	return nil, errors.New("ListDuplicateCandidatesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadDuplicateCandidate(ctx context.Context, id uuid.UUID) (*entity.DuplicateCandidate, error) {
+	if mcr.ReadDuplicateCandidateMock != nil {
+		return mcr.ReadDuplicateCandidateMock(ctx, id)
+	}
+	return nil, errors.New("ReadDuplicateCandidateMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ResolveDuplicateCandidate(ctx context.Context, id uuid.UUID, status string) (bool, error) {
+	if mcr.ResolveDuplicateCandidateMock != nil {
+		return mcr.ResolveDuplicateCandidateMock(ctx, id, status)
+	}
+	return false, errors.New("ResolveDuplicateCandidateMock must be set")
 }
 
 func (mcr *MockCompanyRepository) AddCompany(ctx context.Context, company entity.Companies) error {
@@ -33,6 +300,27 @@ func (mcr *MockCompanyRepository) ReadCompanyByName(ctx context.Context, name st
 	return nil, errors.New("ReadCompanyByNameMock must be set")
 }
 
+func (mcr *MockCompanyRepository) ReadCompanyLocations(ctx context.Context, name string) ([]*entity.Companies, error) {
+	if mcr.ReadCompanyLocationsMock != nil {
+		return mcr.ReadCompanyLocationsMock(ctx, name)
+	}
+	return nil, errors.New("ReadCompanyLocationsMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadCompaniesByDomain(ctx context.Context, domain string) ([]*entity.Companies, error) {
+	if mcr.ReadCompaniesByDomainMock != nil {
+		return mcr.ReadCompaniesByDomainMock(ctx, domain)
+	}
+	return nil, errors.New("ReadCompaniesByDomainMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadParentCompany(ctx context.Context, id uuid.UUID) (*entity.ParentCompany, error) {
+	if mcr.ReadParentCompanyMock != nil {
+		return mcr.ReadParentCompanyMock(ctx, id)
+	}
+	return nil, errors.New("ReadParentCompanyMock must be set")
+}
+
 func (mcr *MockCompanyRepository) UpdateCompany(ctx context.Context, company entity.Companies) error {
 	if mcr.UpdateCompanyMock != nil {
 		return mcr.UpdateCompanyMock(ctx, company)
@@ -60,6 +348,55 @@ func (mcr *MockCompanyRepository) DeleteCompany(ctx context.Context, company ent
 	return errors.New("DeleteCompanyMock must be set")
 }
 
+func (mcr *MockCompanyRepository) ReadCompanyByID(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+	if mcr.ReadCompanyByIDMock != nil {
+		return mcr.ReadCompanyByIDMock(ctx, id, includeDeleted)
+	}
+	return nil, errors.New("ReadCompanyByIDMock must be set")
+}
+
+func (mcr *MockCompanyRepository) RestoreCompany(ctx context.Context, id uuid.UUID) (bool, error) {
+	if mcr.RestoreCompanyMock != nil {
+		return mcr.RestoreCompanyMock(ctx, id)
+	}
+	return false, errors.New("RestoreCompanyMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListCompanies(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+	if mcr.ListCompaniesMock != nil {
+		return mcr.ListCompaniesMock(ctx, filter)
+	}
+	return nil, errors.New("ListCompaniesMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveProvenance(ctx context.Context, provenance []entity.Provenance) error {
+	if mcr.SaveProvenanceMock != nil {
+		return mcr.SaveProvenanceMock(ctx, provenance)
+	}
+	return errors.New("SaveProvenanceMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ReadProvenance(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+	if mcr.ReadProvenanceMock != nil {
+		return mcr.ReadProvenanceMock(ctx, companyIDs)
+	}
+	return nil, errors.New("ReadProvenanceMock must be set")
+}
+
+func (mcr *MockCompanyRepository) ListAttributeDefinitions(ctx context.Context) ([]*entity.AttributeDefinition, error) {
+	if mcr.ListAttributeDefinitionsMock != nil {
+		return mcr.ListAttributeDefinitionsMock(ctx)
+	}
+	return nil, errors.New("ListAttributeDefinitionsMock must be set")
+}
+
+func (mcr *MockCompanyRepository) SaveAttributeDefinition(ctx context.Context, definition entity.AttributeDefinition) error {
+	if mcr.SaveAttributeDefinitionMock != nil {
+		return mcr.SaveAttributeDefinitionMock(ctx, definition)
+	}
+	return errors.New("SaveAttributeDefinitionMock must be set")
+}
+
 type MockCsvCompanyRepository struct {
 	GetCompanyMock func(ctx context.Context, key string) ([]*entity.Companies, error)
 }
@@ -92,9 +429,16 @@ func TestCheckNameValidity(t *testing.T) {
 }
 
 func TestCheckZipValidity(t *testing.T) {
+	repository := &MockCompanyRepository{
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			return []*entity.ZipReference{{Zip: "12345", City: "SCHENECTADY", State: "NY"}}, nil
+		},
+	}
+	service := NewCompanyService(repository, &MockCsvCompanyRepository{})
+
 	t.Run("Valid zip", func(t *testing.T) {
 		zip := "12345"
-		got, _ := CheckZipValidity(zip)
+		got, _ := service.CheckZipValidity(zip)
 
 		if !got {
 			t.Errorf("Expected true, but get %v", got)
@@ -103,12 +447,24 @@ func TestCheckZipValidity(t *testing.T) {
 
 	t.Run("Invalid zip", func(t *testing.T) {
 		zip := "123456"
-		got, _ := CheckZipValidity(zip)
+		got, _ := service.CheckZipValidity(zip)
 
 		if got {
 			t.Errorf("Expected false, but get %v", got)
 		}
 	})
+
+	t.Run("Zip missing from the reference", func(t *testing.T) {
+		service.LoadZipReference(context.Background())
+
+		for zip, want := range map[string]bool{"12345": true, "00000": false} {
+			got, _ := service.CheckZipValidity(zip)
+
+			if got != want {
+				t.Errorf("got %v for %v want %v", got, zip, want)
+			}
+		}
+	})
 }
 
 func TestCheckWebsiteValidity(t *testing.T) {
@@ -131,6 +487,29 @@ func TestCheckWebsiteValidity(t *testing.T) {
 	})
 }
 
+func TestCheckAllValidityWebsiteRequired(t *testing.T) {
+	service := NewCompanyService(&MockCompanyRepository{}, &MockCsvCompanyRepository{})
+
+	t.Run("Missing website", func(t *testing.T) {
+		company := &entity.Companies{Name: "COMPANY", Zip: "12345"}
+
+		if ok, err := CheckAllValidity(company); ok || !errors.Is(err, ERR_NOT_VALID_COMPANY) {
+			t.Errorf("expected %v, but got %v, %v", ERR_NOT_VALID_COMPANY, ok, err)
+		}
+		if err := service.checkCompany(company); !errors.Is(err, ERR_NOT_VALID_COMPANY) {
+			t.Errorf("expected %v, but got %v", ERR_NOT_VALID_COMPANY, err)
+		}
+	})
+
+	t.Run("Catalog company without website", func(t *testing.T) {
+		company := &entity.Companies{Name: "COMPANY", Zip: "12345"}
+
+		if err := service.checkCatalogCompany(company); err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+	})
+}
+
 func TestInitializeDataBase(t *testing.T) {
 
 	t.Run("Error getting data from database", func(t *testing.T) {
@@ -365,6 +744,9 @@ func TestAddCompany(t *testing.T) {
 			SearchCompanyByNameAndZipMock: func(ctx context.Context, name, zip string) (*entity.Companies, error) {
 				return nil, nil
 			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
 		}
 
 		csvRepository := &MockCsvCompanyRepository{}
@@ -416,7 +798,13 @@ func TestUpdateCompany(t *testing.T) {
 		}
 
 		dbRepository := &MockCompanyRepository{
-			ReadCompanyByNameMock: func(ctx context.Context, name string) (*entity.Companies, error) {
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
 				return nil, want
 			},
 			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
@@ -445,7 +833,16 @@ func TestUpdateCompany(t *testing.T) {
 		}
 
 		dbRepository := &MockCompanyRepository{
-			ReadCompanyByNameMock: func(ctx context.Context, name string) (*entity.Companies, error) {
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return nil, nil
+			},
+			ReadCompaniesByDomainMock: func(ctx context.Context, domain string) ([]*entity.Companies, error) {
 				return nil, nil
 			},
 			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
@@ -475,8 +872,14 @@ func TestUpdateCompany(t *testing.T) {
 		}
 
 		dbRepository := &MockCompanyRepository{
-			ReadCompanyByNameMock: func(ctx context.Context, name string) (*entity.Companies, error) {
-				return company, nil
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{company}, nil
 			},
 			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
 				return want
@@ -503,12 +906,24 @@ func TestUpdateCompany(t *testing.T) {
 		}
 
 		dbRepository := &MockCompanyRepository{
-			ReadCompanyByNameMock: func(ctx context.Context, name string) (*entity.Companies, error) {
-				return company, nil
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{company}, nil
 			},
 			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
 				return nil
 			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
 		}
 
 		csvRepository := &MockCsvCompanyRepository{}
@@ -623,3 +1038,480 @@ func TestFindByNameAndZip(t *testing.T) {
 		}
 	})
 }
+
+func TestRestoreCompany(t *testing.T) {
+	t.Run("Restoring deleted company", func(t *testing.T) {
+		dbRepository := &MockCompanyRepository{
+			RestoreCompanyMock: func(ctx context.Context, id uuid.UUID) (bool, error) {
+				return true, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.RestoreCompany(context.Background(), uuid.New())
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+	})
+
+	t.Run("Company is not deleted", func(t *testing.T) {
+		dbRepository := &MockCompanyRepository{
+			RestoreCompanyMock: func(ctx context.Context, id uuid.UUID) (bool, error) {
+				return false, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.RestoreCompany(context.Background(), uuid.New())
+
+		if !errors.Is(err, ERR_COMPANY_NOT_DELETED) {
+			t.Errorf("expected %v, but got %v", ERR_COMPANY_NOT_DELETED, err)
+		}
+	})
+}
+
+func TestListCompanies(t *testing.T) {
+	t.Run("Passing filter to repository", func(t *testing.T) {
+		company := &entity.Companies{
+			ID:      uuid.New(),
+			Name:    "COMPANY",
+			Zip:     "12345",
+			Website: "http://www.company.com",
+		}
+
+		var gotFilter entity.CompanyFilter
+		dbRepository := &MockCompanyRepository{
+			ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+				gotFilter = filter
+				return []*entity.Companies{company}, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		got, err := service.ListCompanies(context.Background(), entity.CompanyFilter{Name: "company", IncludeDeleted: true})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if gotFilter.Name != "COMPANY" || !gotFilter.IncludeDeleted {
+			t.Errorf("got filter %v", gotFilter)
+		}
+		if len(got) != 1 || !reflect.DeepEqual(*company, got[0]) {
+			t.Errorf("expected %v, but got %v", *company, got)
+		}
+	})
+
+	t.Run("Error getting data from database", func(t *testing.T) {
+		dbRepository := &MockCompanyRepository{
+			ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+				return nil, errors.New("error")
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		_, err := service.ListCompanies(context.Background(), entity.CompanyFilter{})
+
+		if err == nil {
+			t.Errorf("expected an error, but got %v", err)
+		}
+	})
+}
+
+func TestReplaceCompany(t *testing.T) {
+	t.Run("Keeping stored version", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 7}
+
+		var written entity.Companies
+		dbRepository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return stored, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				written = company
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.ReplaceCompany(context.Background(), &entity.Companies{ID: stored.ID, Name: "company", Zip: "54321", Website: "http://company.com"})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if written.Version != 7 || written.Name != "COMPANY" || written.Zip != "54321" {
+			t.Errorf("got %v", written)
+		}
+	})
+
+	t.Run("Nothing changed", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Country: entity.COUNTRY_US, Website: "http://company.com", Attributes: map[string]string{"phone": "210-555-0100"}, Version: 7}
+
+		dbRepository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return stored, nil
+			},
+			SaveMergedCompanyMock: func(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error {
+				t.Errorf("got a write of %v want none", company)
+				return nil
+			},
+		}
+
+		service := newServiceWithSchema(dbRepository)
+
+		err := service.ReplaceCompany(context.Background(), &entity.Companies{ID: stored.ID, Name: "company", Zip: "12345", Website: "HTTP://Company.com/", Attributes: map[string]string{"Phone": "210-555-0100"}, Version: 7})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+	})
+
+	t.Run("Removing an attribute", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Attributes: map[string]string{"phone": "210-555-0100"}, Version: 7}
+
+		var written *entity.Companies
+		dbRepository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return stored, nil
+			},
+			SaveMergedCompanyMock: func(ctx context.Context, company *entity.Companies, crosswalk *entity.Crosswalk, provenance []entity.Provenance) error {
+				written = company
+				return nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.ReplaceCompany(context.Background(), &entity.Companies{ID: stored.ID, Name: "COMPANY", Zip: "12345", Website: "http://company.com"})
+
+		if err != nil || written == nil || len(written.Attributes) != 0 {
+			t.Errorf("got %v, %v want the company written without attributes", written, err)
+		}
+	})
+
+	t.Run("Version conflict", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 7}
+
+		dbRepository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return stored, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				return entity.ERR_VERSION_CONFLICT
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.ReplaceCompany(context.Background(), &entity.Companies{ID: stored.ID, Name: "COMPANY", Zip: "12345", Website: "http://company.com", Version: 6})
+
+		if !errors.Is(err, ERR_VERSION_CONFLICT) {
+			t.Errorf("expected %v, but got %v", ERR_VERSION_CONFLICT, err)
+		}
+	})
+
+	t.Run("Not exists company", func(t *testing.T) {
+		dbRepository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return nil, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		err := service.ReplaceCompany(context.Background(), &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345"})
+
+		if !errors.Is(err, ERR_COMPANY_NOT_EXISTS) {
+			t.Errorf("expected %v, but got %v", ERR_COMPANY_NOT_EXISTS, err)
+		}
+	})
+}
+
+func TestMergeCompanyProvenance(t *testing.T) {
+	t.Run("Recording changed fields", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Version: 1}
+
+		var saved []entity.Provenance
+		dbRepository := &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{stored}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				saved = provenance
+				return nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+		source := NewSource(SOURCE_CLIENT, "q2_clientData.csv")
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "company", Zip: "12345", Website: "http://www.company.com"}, source)
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if result.Status != entity.MERGE_STATUS_MERGED {
+			t.Errorf("expected %s, but got %s", entity.MERGE_STATUS_MERGED, result.Status)
+		}
+		if len(saved) != 1 {
+			t.Fatalf("expected provenance only for the website, but got %v", saved)
+		}
+		if saved[0].CompanyID != stored.ID || saved[0].Field != entity.FIELD_WEBSITE || saved[0].SourceFile != "q2_clientData.csv" || saved[0].ImportJob != source.ImportJob || saved[0].Priority != source.Priority {
+			t.Errorf("got %v", saved[0])
+		}
+	})
+
+	t.Run("Nothing changed", func(t *testing.T) {
+		stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://www.company.com", Version: 1}
+
+		dbRepository := &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				return []*entity.Companies{stored}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		result, err := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Zip: "12345", Website: "http://www.company.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if result.Status != entity.MERGE_STATUS_UNCHANGED {
+			t.Errorf("expected %s, but got %s", entity.MERGE_STATUS_UNCHANGED, result.Status)
+		}
+	})
+}
+
+func TestMergePolicies(t *testing.T) {
+	stored := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://curated.com", Version: 1}
+	curated := &entity.Provenance{CompanyID: stored.ID, Field: entity.FIELD_WEBSITE, SourceSystem: SOURCE_API, Priority: SourcePriority(SOURCE_API), RecordedAt: time.Now()}
+
+	newRepository := func(written *entity.Companies) *MockCompanyRepository {
+		return &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				company := *stored
+				return []*entity.Companies{&company}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return []*entity.Provenance{curated}, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				*written = company
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+	}
+
+	website := func(result *entity.MergeResult) entity.FieldDecision {
+		for _, decision := range result.Fields {
+			if decision.Field == entity.FIELD_WEBSITE {
+				return decision
+			}
+		}
+		return entity.FieldDecision{}
+	}
+
+	t.Run("Lower priority source keeps curated value", func(t *testing.T) {
+		var written entity.Companies
+		service := NewCompanyService(newRepository(&written), &MockCsvCompanyRepository{})
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: "http://client.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if website(result).Applied || website(result).Policy != string(POLICY_PREFER_PRIORITY) {
+			t.Errorf("got decision %v", website(result))
+		}
+		if written.Website != "" {
+			t.Errorf("not expected an update, but got %v", written)
+		}
+	})
+
+	t.Run("Blank value never erases", func(t *testing.T) {
+		var written entity.Companies
+		service := NewCompanyService(newRepository(&written), &MockCsvCompanyRepository{})
+		service.SetMergePolicies(map[string]MergePolicy{entity.FIELD_WEBSITE: POLICY_OVERWRITE})
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: ""}, NewSource(SOURCE_API, ""))
+
+		if website(result).Applied {
+			t.Errorf("got decision %v", website(result))
+		}
+	})
+
+	t.Run("Overwrite policy", func(t *testing.T) {
+		var written entity.Companies
+		service := NewCompanyService(newRepository(&written), &MockCsvCompanyRepository{})
+		service.SetMergePolicies(map[string]MergePolicy{entity.FIELD_WEBSITE: POLICY_OVERWRITE})
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: "http://client.com"}, NewSource(SOURCE_CLIENT, ""))
+
+		if !website(result).Applied || written.Website != "http://client.com" {
+			t.Errorf("got decision %v, written %v", website(result), written)
+		}
+	})
+
+	t.Run("Fill if empty policy", func(t *testing.T) {
+		var written entity.Companies
+		service := NewCompanyService(newRepository(&written), &MockCsvCompanyRepository{})
+		service.SetMergePolicies(map[string]MergePolicy{entity.FIELD_WEBSITE: POLICY_FILL_IF_EMPTY})
+
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: "http://client.com"}, NewSource(SOURCE_API, ""))
+
+		if website(result).Applied {
+			t.Errorf("got decision %v", website(result))
+		}
+	})
+
+	t.Run("Newest wins policy", func(t *testing.T) {
+		var written entity.Companies
+		service := NewCompanyService(newRepository(&written), &MockCsvCompanyRepository{})
+		service.SetMergePolicies(map[string]MergePolicy{entity.FIELD_WEBSITE: POLICY_NEWEST_WINS})
+
+		older := NewSource(SOURCE_CLIENT, "")
+		older.Timestamp = curated.RecordedAt.Add(-time.Hour)
+		result, _ := service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: "http://client.com"}, older)
+		if website(result).Applied {
+			t.Errorf("got decision %v", website(result))
+		}
+
+		newer := NewSource(SOURCE_CLIENT, "")
+		newer.Timestamp = curated.RecordedAt.Add(time.Hour)
+		result, _ = service.MergeCompany(context.Background(), &entity.Companies{Name: "COMPANY", Website: "http://client.com"}, newer)
+		if !website(result).Applied {
+			t.Errorf("got decision %v", website(result))
+		}
+	})
+
+	t.Run("Rejecting unknown policies", func(t *testing.T) {
+		service := NewCompanyService(&MockCompanyRepository{}, &MockCsvCompanyRepository{})
+
+		if err := service.SetMergePolicies(map[string]MergePolicy{entity.FIELD_WEBSITE: "random"}); !errors.Is(err, ERR_UNKNOWN_MERGE_POLICY) {
+			t.Errorf("expected %v, but got %v", ERR_UNKNOWN_MERGE_POLICY, err)
+		}
+		if err := service.SetMergePolicies(map[string]MergePolicy{"phone": POLICY_OVERWRITE}); !errors.Is(err, ERR_UNKNOWN_MERGE_FIELD) {
+			t.Errorf("expected %v, but got %v", ERR_UNKNOWN_MERGE_FIELD, err)
+		}
+	})
+}
+
+func TestParseMergePolicies(t *testing.T) {
+	got, err := ParseMergePolicies("website=fill-if-empty, zipCode=overwrite")
+
+	if err != nil {
+		t.Errorf("not expected an error, but got %v", err)
+	}
+	want := map[string]MergePolicy{entity.FIELD_WEBSITE: POLICY_FILL_IF_EMPTY, entity.FIELD_ZIP: POLICY_OVERWRITE}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, but got %v", want, got)
+	}
+}
+
+func TestMergeCompanies(t *testing.T) {
+	t.Run("Reporting each record", func(t *testing.T) {
+		dbRepository := &MockCompanyRepository{
+			ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+				return nil, nil
+			},
+			SaveCrosswalkMock: func(ctx context.Context, crosswalk entity.Crosswalk) error {
+				return nil
+			},
+			ReadCompaniesByDomainMock: func(ctx context.Context, domain string) ([]*entity.Companies, error) {
+				return nil, nil
+			},
+			ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+				if name == "MISSING" {
+					return nil, nil
+				}
+				return []*entity.Companies{{ID: uuid.New(), Name: name, Zip: "12345"}}, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+				return nil
+			},
+			SaveProvenanceMock: func(ctx context.Context, provenance []entity.Provenance) error {
+				return nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		companies := []*entity.Companies{
+			{Name: "COMPANY", Zip: "12345", Website: "http://www.company.com"},
+			{Name: "MISSING", Zip: "12345", Website: "http://www.missing.com"},
+			{Name: "COMPANY", Zip: "12345", Website: "not a website"},
+		}
+
+		report := service.MergeCompanies(context.Background(), companies, NewSource(SOURCE_CLIENT, "client.csv"))
+
+		if report.Total != 3 || report.Merged != 1 || report.NotFound != 1 || report.Rejected != 1 {
+			t.Errorf("got report %v", report)
+		}
+		if report.Results[1].Line != 3 || report.Results[1].Status != entity.MERGE_STATUS_NOT_FOUND {
+			t.Errorf("got result %v", report.Results[1])
+		}
+	})
+}
+
+func TestGetProvenance(t *testing.T) {
+	t.Run("Indexing by company and field", func(t *testing.T) {
+		id := uuid.New()
+		record := &entity.Provenance{CompanyID: id, Field: entity.FIELD_WEBSITE, SourceSystem: SOURCE_CLIENT}
+
+		dbRepository := &MockCompanyRepository{
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return []*entity.Provenance{record}, nil
+			},
+		}
+
+		service := NewCompanyService(dbRepository, &MockCsvCompanyRepository{})
+
+		got, err := service.GetProvenance(context.Background(), []uuid.UUID{id})
+
+		if err != nil {
+			t.Errorf("not expected an error, but got %v", err)
+		}
+		if !reflect.DeepEqual(got[id][entity.FIELD_WEBSITE], *record) {
+			t.Errorf("expected %v, but got %v", *record, got)
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package company
+
+import (
+	"strings"
+	"unicode"
+)
+
+// companySuffixes are legal-form words that do not tell companies apart
+var companySuffixes = map[string]bool{
+	"THE": true, "INC": true, "INCORPORATED": true, "LLC": true, "LLP": true, "LP": true,
+	"CORP": true, "CORPORATION": true, "CO": true, "COMPANY": true, "LTD": true, "LIMITED": true,
+}
+
+// normalizeCompanyName reduces a name to the words that identify the company:
+// uppercase, punctuation removed ("L.L.C" reads LLC), "&" spelled out and legal-form suffixes dropped
+func normalizeCompanyName(name string) string {
+	name = strings.ReplaceAll(strings.ToUpper(name), "&", " AND ")
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsSpace(r) {
+			return r
+		}
+		if r == '\'' || r == '.' {
+			return -1
+		}
+		return ' '
+	}, name)
+
+	words := []string{}
+	for _, word := range strings.Fields(name) {
+		if !companySuffixes[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// nameSimilarity scores from 0 to 1 how likely two company names refer to the
+// same company, using the Jaro-Winkler similarity of the normalized names
+func nameSimilarity(a string, b string) float64 {
+	return jaroWinkler(normalizeCompanyName(a), normalizeCompanyName(b))
+}
+
+func jaroWinkler(a string, b string) float64 {
+	if a == b {
+		return 1
+	}
+	first, second := []rune(a), []rune(b)
+	if len(first) == 0 || len(second) == 0 {
+		return 0
+	}
+
+	window := max(len(first), len(second))/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	firstMatched := make([]bool, len(first))
+	secondMatched := make([]bool, len(second))
+	matches := 0
+	for i := range first {
+		for j := max(0, i-window); j < min(len(second), i+window+1); j++ {
+			if !secondMatched[j] && first[i] == second[j] {
+				firstMatched[i], secondMatched[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range first {
+		if !firstMatched[i] {
+			continue
+		}
+		for !secondMatched[j] {
+			j++
+		}
+		if first[i] != second[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(first)) + m/float64(len(second)) + (m-float64(transpositions)/2)/m) / 3
+
+	prefix := 0
+	for prefix < min(4, min(len(first), len(second))) && first[prefix] == second[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func max(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a int, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package company
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizeCompanyName(t *testing.T) {
+	tests := map[string]string{
+		"TOLA SALES GROUP INC":    "TOLA SALES GROUP",
+		"Tola Sales Group, L.L.C": "TOLA SALES GROUP",
+		"THE SMITH & SONS CO":     "SMITH AND SONS",
+		"O'REILLY CORPORATION":    "OREILLY",
+	}
+
+	for name, want := range tests {
+		if got := normalizeCompanyName(name); got != want {
+			t.Errorf("normalizeCompanyName(%q) got %q want %q", name, got, want)
+		}
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"MARTHA", "MARHTA", 0.961},
+		{"TOLA SALES GROUP", "TOLA SALES GROUP INC", 1},
+		{"SMITH & SONS", "SMITH AND SONS CO", 1},
+		{"ACME", "", 0},
+	}
+
+	for _, test := range tests {
+		got := nameSimilarity(test.a, test.b)
+		if math.Abs(got-test.want) > 0.001 {
+			t.Errorf("nameSimilarity(%q, %q) got %v want %v", test.a, test.b, got, test.want)
+		}
+	}
+
+	if score := nameSimilarity("TOLA SALES GROUP", "ACME WIDGETS"); score >= DUPLICATE_THRESHOLD {
+		t.Errorf("got %v want a score under %v", score, DUPLICATE_THRESHOLD)
+	}
+}
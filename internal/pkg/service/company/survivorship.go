@@ -0,0 +1,130 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// SOURCE_MERGE records values a company took from a merged record whose
+// origin is unknown
+const SOURCE_MERGE = "merge"
+
+var ERR_SAME_COMPANY = errors.New("Error: a company cannot be merged into itself")
+
+// MergeInto combines the company id into targetID. For every field the
+// survivor takes the value with the best provenance, see survive, and the
+// company id is deleted; later lookups of its ID resolve to the survivor.
+func (s *CompanyService) MergeInto(ctx context.Context, id uuid.UUID, targetID uuid.UUID) (*entity.CompanyMerge, error) {
+	return s.mergeInto(ctx, id, targetID, s.dbRepository.MergeCompanyInto)
+}
+
+// mergeInto combines the companies like MergeInto, writing the result with
+// write
+func (s *CompanyService) mergeInto(ctx context.Context, id uuid.UUID, targetID uuid.UUID, write func(ctx context.Context, survivor entity.Companies, merged entity.Companies, provenance []entity.Provenance) error) (*entity.CompanyMerge, error) {
+	merged, err := s.dbRepository.ReadCompanyByID(ctx, id, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	target, err := s.dbRepository.ReadCompanyByID(ctx, targetID, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	if merged == nil || target == nil {
+		return nil, ERR_COMPANY_NOT_EXISTS
+	}
+
+	if merged.ID == target.ID {
+		return nil, ERR_SAME_COMPANY
+	}
+
+	provenance, err := s.GetProvenance(ctx, []uuid.UUID{merged.ID, target.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.CompanyMerge{Survivor: *target, MergedID: merged.ID}
+	inherited := []entity.Provenance{}
+	source := NewSource(SOURCE_MERGE, "")
+
+	for _, field := range s.mergeFields() {
+		var targetRecord, mergedRecord *entity.Provenance
+		if record, ok := provenance[target.ID][field.name]; ok {
+			targetRecord = &record
+		}
+		if record, ok := provenance[merged.ID][field.name]; ok {
+			mergedRecord = &record
+		}
+
+		take, reason := survive(field.get(target), field.get(merged), targetRecord, mergedRecord)
+		if take {
+			field.take(&result.Survivor, merged)
+
+			record := entity.Provenance{
+				Field:        field.name,
+				SourceSystem: source.System,
+				ImportJob:    source.ImportJob,
+				Priority:     source.Priority,
+				RecordedAt:   source.Timestamp,
+			}
+			if mergedRecord != nil {
+				record = *mergedRecord
+			}
+			record.CompanyID = target.ID
+			inherited = append(inherited, record)
+		}
+
+		result.Fields = append(result.Fields, entity.FieldDecision{
+			Field:   field.name,
+			Policy:  "survivorship",
+			Applied: take,
+			Reason:  reason,
+		})
+	}
+
+	canonicalizeWebsite(&result.Survivor)
+	err = write(ctx, result.Survivor, *merged, inherited)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	stored, err := s.dbRepository.ReadCompanyByID(ctx, target.ID, false)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	if stored != nil {
+		result.Survivor = *stored
+	}
+	return result, nil
+}
+
+// survive tells whether the value of the merged record replaces the one of the
+// survivor. Set values beat blank ones; between two set values the one from
+// the higher-priority source wins, then the most recently recorded one, and
+// the survivor keeps its value when nothing tells them apart.
+func survive(target string, merged string, targetRecord *entity.Provenance, mergedRecord *entity.Provenance) (bool, string) {
+	switch {
+	case merged == "" || merged == target:
+		return false, "merged record has no other value"
+	case target == "":
+		return true, "survivor has no value"
+	case mergedRecord == nil:
+		return false, "merged value has no recorded source"
+	case targetRecord == nil:
+		return true, "survivor value has no recorded source"
+	case mergedRecord.Priority != targetRecord.Priority:
+		if mergedRecord.Priority > targetRecord.Priority {
+			return true, fmt.Sprintf("source %q outranks %q", mergedRecord.SourceSystem, targetRecord.SourceSystem)
+		}
+		return false, fmt.Sprintf("source %q outranks %q", targetRecord.SourceSystem, mergedRecord.SourceSystem)
+	case mergedRecord.RecordedAt.After(targetRecord.RecordedAt):
+		return true, "merged value is newer"
+	default:
+		return false, "survivor value is as new or newer"
+	}
+}
@@ -0,0 +1,133 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestSurvive(t *testing.T) {
+	now := time.Now()
+	catalog := &entity.Provenance{SourceSystem: SOURCE_CATALOG, Priority: SourcePriority(SOURCE_CATALOG), RecordedAt: now}
+	client := &entity.Provenance{SourceSystem: SOURCE_CLIENT, Priority: SourcePriority(SOURCE_CLIENT), RecordedAt: now}
+	newerClient := &entity.Provenance{SourceSystem: SOURCE_CLIENT, Priority: SourcePriority(SOURCE_CLIENT), RecordedAt: now.Add(time.Hour)}
+
+	tests := []struct {
+		name         string
+		target       string
+		merged       string
+		targetRecord *entity.Provenance
+		mergedRecord *entity.Provenance
+		want         bool
+	}{
+		{"blank merged value", "a", "", nil, client, false},
+		{"same value", "a", "a", nil, client, false},
+		{"blank survivor value", "", "b", catalog, nil, true},
+		{"merged value without source", "a", "b", nil, nil, false},
+		{"survivor value without source", "a", "b", nil, client, true},
+		{"higher priority survivor", "a", "b", catalog, client, false},
+		{"higher priority merged", "a", "b", client, catalog, true},
+		{"newer merged", "a", "b", client, newerClient, true},
+		{"as new", "a", "b", client, client, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, reason := survive(test.target, test.merged, test.targetRecord, test.mergedRecord)
+			if got != test.want {
+				t.Errorf("got %v (%s) want %v", got, reason, test.want)
+			}
+		})
+	}
+}
+
+func TestMergeInto(t *testing.T) {
+	target := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "http://old.com", Version: 2}
+	merged := &entity.Companies{ID: uuid.New(), Name: "COMPANY INC", Zip: "12345", Website: "http://new.com", Version: 1}
+
+	t.Run("merging", func(t *testing.T) {
+		var written entity.Companies
+		var inherited []entity.Provenance
+		repository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				if id == merged.ID {
+					return merged, nil
+				}
+				return target, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return []*entity.Provenance{
+					{CompanyID: target.ID, Field: entity.FIELD_WEBSITE, SourceSystem: SOURCE_CLIENT, Priority: 10},
+					{CompanyID: merged.ID, Field: entity.FIELD_WEBSITE, SourceSystem: SOURCE_API, Priority: 100},
+				}, nil
+			},
+			MergeCompanyIntoMock: func(ctx context.Context, survivor entity.Companies, loser entity.Companies, provenance []entity.Provenance) error {
+				if loser.ID != merged.ID {
+					t.Errorf("got %v want %v", loser.ID, merged.ID)
+				}
+				written = survivor
+				inherited = provenance
+				return nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		got, err := service.MergeInto(context.Background(), merged.ID, target.ID)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if written.ID != target.ID || written.Name != target.Name || written.Website != merged.Website || written.Version != target.Version {
+			t.Errorf("got %v", written)
+		}
+		if len(inherited) != 1 || inherited[0].CompanyID != target.ID || inherited[0].SourceSystem != SOURCE_API {
+			t.Errorf("got %v", inherited)
+		}
+		if got.MergedID != merged.ID {
+			t.Errorf("got %v want %v", got.MergedID, merged.ID)
+		}
+	})
+
+	t.Run("into itself", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				return target, nil
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		_, err := service.MergeInto(context.Background(), target.ID, target.ID)
+
+		if !errors.Is(err, ERR_SAME_COMPANY) {
+			t.Errorf("got %v error want %v", err, ERR_SAME_COMPANY)
+		}
+	})
+
+	t.Run("conflicting write", func(t *testing.T) {
+		repository := &MockCompanyRepository{
+			ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+				if id == merged.ID {
+					return merged, nil
+				}
+				return target, nil
+			},
+			ReadProvenanceMock: func(ctx context.Context, companyIDs []uuid.UUID) ([]*entity.Provenance, error) {
+				return nil, nil
+			},
+			MergeCompanyIntoMock: func(ctx context.Context, survivor entity.Companies, loser entity.Companies, provenance []entity.Provenance) error {
+				return entity.ERR_VERSION_CONFLICT
+			},
+		}
+		service := NewCompanyService(repository, repository)
+
+		_, err := service.MergeInto(context.Background(), merged.ID, target.ID)
+
+		if !errors.Is(err, ERR_VERSION_CONFLICT) {
+			t.Errorf("got %v error want %v", err, ERR_VERSION_CONFLICT)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var ERR_NOT_VALID_NAME = errors.New("Error: the name must only hold letters, spaces, & and '")
+
+// ValidateCompanies normalizes and checks every record the way a merge would,
+// reporting each problem found instead of stopping at the first one. Records
+// are numbered from line 2, after the header. With a source, the records are
+// also matched to the stored companies like a merge of the source would,
+// without writing anything.
+func (s *CompanyService) ValidateCompanies(ctx context.Context, companies []*entity.Companies, source *entity.Source) (*entity.ValidationReport, error) {
+	report := &entity.ValidationReport{Results: []entity.ValidationResult{}}
+
+	for index, company := range companies {
+		company.Name = strings.ToUpper(company.Name)
+		company.Attributes = NormalizeAttributes(company.Attributes)
+		canonicalizeWebsite(company)
+		normalizePostalCode(company)
+		s.locateZip(company)
+
+		result := entity.ValidationResult{Line: index + 2, Name: company.Name, Zip: company.Zip}
+		for _, err := range s.validationErrors(company) {
+			result.Errors = append(result.Errors, err.Error())
+		}
+
+		if source != nil {
+			readCompany, matchedBy, err := s.matchCompany(ctx, company, *source)
+			switch {
+			case errors.Is(err, ERR_AMBIGUOUS_LOCATION):
+				result.Errors = append(result.Errors, err.Error())
+			case err != nil:
+				return nil, err
+			case readCompany == nil:
+				result.Warnings = append(result.Warnings, ERR_COMPANY_NOT_EXISTS.Error())
+				report.NotFound++
+			default:
+				result.CompanyID = &readCompany.ID
+				result.MatchedBy = matchedBy
+				report.Matched++
+			}
+		}
+		result.Valid = len(result.Errors) == 0
+
+		report.Total++
+		if result.Valid {
+			report.Valid++
+		} else {
+			report.Invalid++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// validationErrors runs the checks of CheckAllValidity and of the attribute
+// schema one by one
+func (s *CompanyService) validationErrors(company *entity.Companies) []error {
+	errs := []error{}
+
+	ok, err := CheckNameValidity(company.Name)
+	if err != nil {
+		errs = append(errs, err)
+	} else if !ok {
+		errs = append(errs, ERR_NOT_VALID_NAME)
+	}
+
+	if _, err := NormalizePostalCode(company.Country, company.Zip); err != nil {
+		errs = append(errs, err)
+	} else if err := s.checkZipKnown(company); err != nil {
+		errs = append(errs, err)
+	}
+
+	if company.Website != "" {
+		if _, _, err := CanonicalizeWebsite(company.Website); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, name := range sortedKeys(company.Attributes) {
+		definition, pattern, ok := s.attributes.lookup(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: %s", ERR_UNKNOWN_ATTRIBUTE, name))
+			continue
+		}
+		if !checkAttributeValue(definition, pattern, company.Attributes[name]) {
+			errs = append(errs, fmt.Errorf("%w: %s", ERR_NOT_VALID_ATTRIBUTE, name))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,129 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestValidateCompanies(t *testing.T) {
+	repository := &MockCompanyRepository{
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			return []*entity.ZipReference{{Zip: "78229", City: "San Antonio", State: "TX"}}, nil
+		},
+		SaveAttributeDefinitionMock: func(ctx context.Context, definition entity.AttributeDefinition) error {
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, &MockCsvCompanyRepository{})
+	service.LoadZipReference(context.Background())
+	service.RegisterAttribute(context.Background(), entity.AttributeDefinition{Name: "employees", Type: entity.ATTRIBUTE_TYPE_INTEGER})
+
+	companies := []*entity.Companies{
+		{Name: "tola sales group", Zip: "78229", Website: "HTTP://RepSources.com/"},
+		{Name: "tola sales group 2", Zip: "7822", Website: "ftp://repsources.com", Attributes: map[string]string{"employees": "many", "color": "red"}},
+		{Name: "tola sales group", Zip: "10001"},
+	}
+
+	report, err := service.ValidateCompanies(context.Background(), companies, nil)
+
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	if report.Total != 3 || report.Valid != 1 || report.Invalid != 2 {
+		t.Fatalf("got %+v want 1 valid record out of 3", report)
+	}
+
+	valid := report.Results[0]
+	if valid.Line != 2 || !valid.Valid || valid.Name != "TOLA SALES GROUP" || len(valid.Warnings) != 0 {
+		t.Errorf("got %+v want a valid record", valid)
+	}
+	if companies[0].Website != "http://repsources.com" {
+		t.Errorf("got %v want the website normalized", companies[0].Website)
+	}
+
+	invalid := report.Results[1]
+	if invalid.Valid || len(invalid.Errors) != 5 {
+		t.Fatalf("got %+v want every problem reported", invalid)
+	}
+	if invalid.Errors[0] != ERR_NOT_VALID_NAME.Error() || invalid.Errors[1] != ERR_NOT_VALID_POSTAL_CODE.Error() {
+		t.Errorf("got %v", invalid.Errors)
+	}
+
+	unknown := report.Results[2]
+	if unknown.Valid || len(unknown.Errors) != 1 || unknown.Errors[0] != ERR_ZIP_UNKNOWN.Error() {
+		t.Errorf("got %+v want the zip code rejected", unknown)
+	}
+}
+
+func TestValidateCompaniesMatching(t *testing.T) {
+	stored := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "78229", Country: entity.COUNTRY_US}
+	other := &entity.Companies{ID: uuid.New(), Name: "TOLA SALES GROUP", Zip: "10001", Country: entity.COUNTRY_US}
+
+	repository := &MockCompanyRepository{
+		ReadCrosswalkMock: func(ctx context.Context, sourceSystem string, externalKey string) (*entity.Crosswalk, error) {
+			return nil, nil
+		},
+		ReadCompanyLocationsMock: func(ctx context.Context, name string) ([]*entity.Companies, error) {
+			if name == stored.Name {
+				return []*entity.Companies{stored, other}, nil
+			}
+			return nil, nil
+		},
+		ReadCompaniesByDomainMock: func(ctx context.Context, domain string) ([]*entity.Companies, error) {
+			return nil, nil
+		},
+	}
+	service := NewCompanyService(repository, &MockCsvCompanyRepository{})
+	service.SetMatchStrategies([]string{entity.MATCH_CROSSWALK, entity.MATCH_NAME})
+	source := NewSource(SOURCE_CLIENT, "partner.csv")
+
+	t.Run("matching without writing", func(t *testing.T) {
+		companies := []*entity.Companies{
+			{Name: "tola sales group", Zip: "78229"},
+			{Name: "unknown company", Zip: "78229"},
+			{Name: "tola sales group", Zip: "90210"},
+		}
+
+		report, err := service.ValidateCompanies(context.Background(), companies, &source)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if report.Matched != 1 || report.NotFound != 1 || report.Invalid != 1 {
+			t.Fatalf("got %+v", report)
+		}
+		if matched := report.Results[0]; matched.CompanyID == nil || *matched.CompanyID != stored.ID || matched.MatchedBy != entity.MATCH_NAME {
+			t.Errorf("got %+v want the stored company", matched)
+		}
+		if notFound := report.Results[1]; !notFound.Valid || notFound.Warnings[0] != ERR_COMPANY_NOT_EXISTS.Error() {
+			t.Errorf("got %+v want a warning", notFound)
+		}
+		if ambiguous := report.Results[2]; ambiguous.Valid || ambiguous.Errors[0] != ERR_AMBIGUOUS_LOCATION.Error() {
+			t.Errorf("got %+v want rejected as ambiguous", ambiguous)
+		}
+	})
+
+	t.Run("error in database", func(t *testing.T) {
+		repository.ReadCrosswalkMock = nil
+
+		_, err := service.ValidateCompanies(context.Background(), []*entity.Companies{{ExternalID: "C-1001", Name: "tola sales group", Zip: "78229"}}, &source)
+
+		if err == nil {
+			t.Errorf("got nil error want the database error")
+		}
+	})
+}
+
+func TestValidationErrors(t *testing.T) {
+	service := NewCompanyService(&MockCompanyRepository{}, &MockCsvCompanyRepository{})
+
+	errs := service.validationErrors(&entity.Companies{Name: "COMPANY", Zip: "12345", Country: "FR"})
+
+	if len(errs) != 1 || !errors.Is(errs[0], ERR_UNKNOWN_COUNTRY) {
+		t.Errorf("got %v want %v", errs, ERR_UNKNOWN_COUNTRY)
+	}
+}
@@ -0,0 +1,417 @@
+package company
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+// Headers of the webhook requests. The signature is the hex HMAC-SHA256 of
+// the timestamp, a dot and the body, keyed by the secret of the subscription.
+const (
+	WEBHOOK_EVENT_HEADER     = "X-Webhook-Event"
+	WEBHOOK_DELIVERY_HEADER  = "X-Webhook-Delivery"
+	WEBHOOK_TIMESTAMP_HEADER = "X-Webhook-Timestamp"
+	WEBHOOK_SIGNATURE_HEADER = "X-Webhook-Signature"
+)
+
+const (
+	// WEBHOOK_BATCH_SIZE is the number of events or deliveries handled at once
+	WEBHOOK_BATCH_SIZE = 100
+	// WEBHOOK_MAX_ATTEMPTS failed attempts make a delivery dead
+	WEBHOOK_MAX_ATTEMPTS = 10
+	// the wait before retrying doubles from WEBHOOK_BACKOFF_BASE up to
+	// WEBHOOK_BACKOFF_MAX
+	WEBHOOK_BACKOFF_BASE = 30 * time.Second
+	WEBHOOK_BACKOFF_MAX  = 6 * time.Hour
+	// WEBHOOK_TIMEOUT bounds a request. Deliveries are claimed one at a time
+	// for WEBHOOK_LEASE, longer than a request, so a delivery being sent is
+	// not claimed again meanwhile
+	WEBHOOK_TIMEOUT = 10 * time.Second
+	WEBHOOK_LEASE   = time.Minute
+)
+
+var (
+	ERR_NOT_VALID_WEBHOOK         = errors.New("Error: webhook subscription is not valid")
+	ERR_WEBHOOK_NOT_FOUND         = errors.New("Error: there is no webhook subscription with this ID")
+	ERR_WEBHOOK_REJECTED          = errors.New("Error: the webhook receiver rejected the delivery")
+	ERR_NOT_VALID_DELIVERY_STATUS = errors.New("Error: unknown delivery status")
+	ERR_WEBHOOK_DELIVERY_NOT_DEAD = errors.New("Error: there is no dead delivery with this ID")
+)
+
+func isWebhookEvent(event string) bool {
+	switch event {
+	case entity.EVENT_CREATED, entity.EVENT_UPDATED, entity.EVENT_DELETED, entity.EVENT_MERGED:
+		return true
+	}
+	return false
+}
+
+func subscribes(subscription *entity.WebhookSubscription, event string) bool {
+	for _, subscribed := range subscription.Events {
+		if subscribed == event {
+			return true
+		}
+	}
+	return false
+}
+
+func validateWebhook(subscription *entity.WebhookSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: the url must be an http or https URL", ERR_NOT_VALID_WEBHOOK)
+	}
+
+	if len(subscription.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ERR_NOT_VALID_WEBHOOK)
+	}
+	events := []string{}
+	seen := map[string]bool{}
+	for _, event := range subscription.Events {
+		if !isWebhookEvent(event) {
+			return fmt.Errorf("%w: unknown event %q", ERR_NOT_VALID_WEBHOOK, event)
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	subscription.Events = events
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// SignWebhook returns the signature header of a payload sent at timestamp
+func SignWebhook(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff is the wait after the given number of failed attempts
+func webhookBackoff(attempts int) time.Duration {
+	backoff := WEBHOOK_BACKOFF_BASE
+	for attempt := 1; attempt < attempts && backoff < WEBHOOK_BACKOFF_MAX; attempt++ {
+		backoff *= 2
+	}
+	if backoff > WEBHOOK_BACKOFF_MAX {
+		return WEBHOOK_BACKOFF_MAX
+	}
+	return backoff
+}
+
+// ListWebhooks returns the subscriptions, without their secrets
+func (s *CompanyService) ListWebhooks(ctx context.Context) ([]entity.WebhookSubscription, error) {
+	references, err := s.dbRepository.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	subscriptions := []entity.WebhookSubscription{}
+	for _, subscription := range references {
+		subscription.Secret = ""
+		subscriptions = append(subscriptions, *subscription)
+	}
+	return subscriptions, nil
+}
+
+// GetWebhook returns the subscription without its secret, or nil if there is
+// none with the ID
+func (s *CompanyService) GetWebhook(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+	subscription, err := s.dbRepository.ReadWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	if subscription != nil {
+		subscription.Secret = ""
+	}
+	return subscription, nil
+}
+
+// CreateWebhook validates and saves a new subscription, generating its
+// secret unless one is given
+func (s *CompanyService) CreateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	err := validateWebhook(subscription)
+	if err != nil {
+		return err
+	}
+
+	if subscription.Secret == "" {
+		subscription.Secret, err = newWebhookSecret()
+		if err != nil {
+			return err
+		}
+	}
+	subscription.ID = uuid.New()
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	err = s.dbRepository.SaveWebhookSubscription(ctx, *subscription)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return nil
+}
+
+// UpdateWebhook replaces the URL and events of a subscription. Its secret is
+// kept unless a new one is given.
+func (s *CompanyService) UpdateWebhook(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	err := validateWebhook(subscription)
+	if err != nil {
+		return err
+	}
+
+	stored, err := s.dbRepository.ReadWebhookSubscription(ctx, subscription.ID)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	if stored == nil {
+		return ERR_WEBHOOK_NOT_FOUND
+	}
+
+	secret := subscription.Secret
+	if secret == "" {
+		secret = stored.Secret
+	}
+	updated := *subscription
+	updated.Secret = secret
+	err = s.dbRepository.SaveWebhookSubscription(ctx, updated)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	subscription.CreatedAt = stored.CreatedAt
+	subscription.UpdatedAt = time.Now()
+	return nil
+}
+
+func (s *CompanyService) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	deleted, err := s.dbRepository.DeleteWebhookSubscription(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	if !deleted {
+		return ERR_WEBHOOK_NOT_FOUND
+	}
+	return nil
+}
+
+// ListWebhookDeliveries returns the deliveries of a subscription, only those
+// with the status when it is set
+func (s *CompanyService) ListWebhookDeliveries(ctx context.Context, id uuid.UUID, status string) ([]entity.WebhookDelivery, error) {
+	switch status {
+	case "", entity.DELIVERY_PENDING, entity.DELIVERY_DELIVERED, entity.DELIVERY_DEAD:
+	default:
+		return nil, fmt.Errorf("%w: %s", ERR_NOT_VALID_DELIVERY_STATUS, status)
+	}
+
+	subscription, err := s.dbRepository.ReadWebhookSubscription(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	if subscription == nil {
+		return nil, ERR_WEBHOOK_NOT_FOUND
+	}
+
+	references, err := s.dbRepository.ListWebhookDeliveries(ctx, id, status)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	deliveries := []entity.WebhookDelivery{}
+	for _, delivery := range references {
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery queues a dead delivery again
+func (s *CompanyService) RetryWebhookDelivery(ctx context.Context, id uuid.UUID) error {
+	retried, err := s.dbRepository.RetryWebhookDelivery(ctx, id)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+
+	if !retried {
+		return ERR_WEBHOOK_DELIVERY_NOT_DEAD
+	}
+	return nil
+}
+
+// webhookPayload builds the body sent for an event, with the state of the
+// company recorded with the event. Events recorded without it carry the
+// current state of the company unless it is gone.
+func (s *CompanyService) webhookPayload(ctx context.Context, event *entity.CompanyEvent) ([]byte, error) {
+	payload := entity.WebhookPayload{
+		EventID:       event.ID,
+		Event:         event.Event,
+		OccurredAt:    event.OccurredAt,
+		CompanyID:     event.CompanyID,
+		MergedInto:    event.MergedInto,
+		ChangedFields: event.ChangedFields,
+		Company:       event.Company,
+	}
+
+	if payload.Company == nil && (event.Event == entity.EVENT_CREATED || event.Event == entity.EVENT_UPDATED) {
+		company, err := s.dbRepository.ReadCompanyByID(ctx, event.CompanyID, false)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+		}
+		// the company may have been merged into another one since
+		if company != nil && company.ID == event.CompanyID {
+			payload.Company = company
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// DispatchWebhookEvents turns the pending events of the outbox into a
+// delivery for each subscription asking for them, returning the number of
+// events dispatched. Events are only marked dispatched along with their
+// deliveries, so an interrupted dispatch is resumed by the next one.
+func (s *CompanyService) DispatchWebhookEvents(ctx context.Context) (int, error) {
+	events, err := s.dbRepository.ListPendingEvents(ctx, WEBHOOK_BATCH_SIZE)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	subscriptions, err := s.dbRepository.ListWebhookSubscriptions(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	eventIDs := []int64{}
+	deliveries := []entity.WebhookDelivery{}
+	for _, event := range events {
+		eventIDs = append(eventIDs, event.ID)
+
+		var payload []byte
+		for _, subscription := range subscriptions {
+			if !subscribes(subscription, event.Event) {
+				continue
+			}
+			if payload == nil {
+				payload, err = s.webhookPayload(ctx, event)
+				if err != nil {
+					return 0, err
+				}
+			}
+			deliveries = append(deliveries, entity.WebhookDelivery{
+				ID:             uuid.New(),
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+				Event:          event.Event,
+				Payload:        payload,
+				Status:         entity.DELIVERY_PENDING,
+			})
+		}
+	}
+
+	err = s.dbRepository.QueueWebhookDeliveries(ctx, eventIDs, deliveries)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return len(events), nil
+}
+
+// DeliverWebhooks sends up to WEBHOOK_BATCH_SIZE deliveries that are due,
+// returning the number of them accepted by their receiver. A failed delivery
+// is retried after an exponential backoff until WEBHOOK_MAX_ATTEMPTS, when it
+// becomes dead.
+func (s *CompanyService) DeliverWebhooks(ctx context.Context) (int, error) {
+	delivered := 0
+	for sent := 0; sent < WEBHOOK_BATCH_SIZE; sent++ {
+		// each delivery is claimed right before it is sent, the lease only
+		// has to outlast one request
+		deliveries, err := s.dbRepository.ClaimWebhookDeliveries(ctx, 1, WEBHOOK_LEASE)
+		if err != nil {
+			return delivered, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+		}
+		if len(deliveries) == 0 {
+			return delivered, nil
+		}
+		delivery := deliveries[0]
+
+		sendErr := s.sendWebhook(ctx, delivery)
+		if ctx.Err() != nil {
+			// the claim expires and the delivery is sent again later
+			return delivered, ctx.Err()
+		}
+
+		now := time.Now()
+		delivery.Attempts++
+		if sendErr == nil {
+			delivery.Status = entity.DELIVERY_DELIVERED
+			delivery.DeliveredAt = &now
+			delivery.LastError = ""
+			delivered++
+		} else {
+			delivery.LastError = sendErr.Error()
+			if delivery.Attempts >= WEBHOOK_MAX_ATTEMPTS {
+				delivery.Status = entity.DELIVERY_DEAD
+			} else {
+				delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+			}
+		}
+
+		err = s.dbRepository.SaveWebhookDeliveryAttempt(ctx, *delivery)
+		if err != nil {
+			return delivered, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+		}
+	}
+	return delivered, nil
+}
+
+// sendWebhook posts the signed payload of a delivery, failing unless the
+// receiver answers with a 2xx status
+func (s *CompanyService) sendWebhook(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WEBHOOK_EVENT_HEADER, delivery.Event)
+	request.Header.Set(WEBHOOK_DELIVERY_HEADER, delivery.ID.String())
+	request.Header.Set(WEBHOOK_TIMESTAMP_HEADER, timestamp)
+	request.Header.Set(WEBHOOK_SIGNATURE_HEADER, SignWebhook(delivery.Secret, timestamp, delivery.Payload))
+
+	response, err := s.webhookClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(response.Body, 64<<10))
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("%w: status %d", ERR_WEBHOOK_REJECTED, response.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package company
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	var saved entity.WebhookSubscription
+	repository := &MockCompanyRepository{
+		SaveWebhookSubscriptionMock: func(ctx context.Context, subscription entity.WebhookSubscription) error {
+			saved = subscription
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, nil)
+
+	t.Run("generating the secret", func(t *testing.T) {
+		subscription := &entity.WebhookSubscription{URL: "https://crm.example.com/hooks", Events: []string{entity.EVENT_MERGED, entity.EVENT_UPDATED, entity.EVENT_MERGED}}
+
+		err := service.CreateWebhook(context.Background(), subscription)
+
+		if err != nil {
+			t.Fatalf("got %v error, it should be nil", err)
+		}
+		if len(subscription.Secret) != 64 || saved.Secret != subscription.Secret || saved.ID == uuid.Nil {
+			t.Errorf("got %+v saved %+v", subscription, saved)
+		}
+		if len(saved.Events) != 2 {
+			t.Errorf("got %v want the events once", saved.Events)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		for _, subscription := range []entity.WebhookSubscription{
+			{URL: "ftp://crm.example.com", Events: []string{entity.EVENT_CREATED}},
+			{URL: "https://crm.example.com/hooks"},
+			{URL: "https://crm.example.com/hooks", Events: []string{"renamed"}},
+		} {
+			if err := service.CreateWebhook(context.Background(), &subscription); !errors.Is(err, ERR_NOT_VALID_WEBHOOK) {
+				t.Errorf("got %v error want %v", err, ERR_NOT_VALID_WEBHOOK)
+			}
+		}
+	})
+}
+
+func TestUpdateWebhook(t *testing.T) {
+	stored := &entity.WebhookSubscription{ID: uuid.New(), URL: "https://crm.example.com/hooks", Secret: "secret", Events: []string{entity.EVENT_CREATED}}
+
+	var saved entity.WebhookSubscription
+	repository := &MockCompanyRepository{
+		ReadWebhookSubscriptionMock: func(ctx context.Context, id uuid.UUID) (*entity.WebhookSubscription, error) {
+			if id == stored.ID {
+				return stored, nil
+			}
+			return nil, nil
+		},
+		SaveWebhookSubscriptionMock: func(ctx context.Context, subscription entity.WebhookSubscription) error {
+			saved = subscription
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, nil)
+
+	err := service.UpdateWebhook(context.Background(), &entity.WebhookSubscription{ID: stored.ID, URL: "https://crm.example.com/v2/hooks", Events: []string{entity.EVENT_MERGED}})
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if saved.Secret != "secret" || saved.URL != "https://crm.example.com/v2/hooks" {
+		t.Errorf("got %+v want the secret kept", saved)
+	}
+
+	err = service.UpdateWebhook(context.Background(), &entity.WebhookSubscription{ID: uuid.New(), URL: "https://crm.example.com/hooks", Events: []string{entity.EVENT_MERGED}})
+	if !errors.Is(err, ERR_WEBHOOK_NOT_FOUND) {
+		t.Errorf("got %v error want %v", err, ERR_WEBHOOK_NOT_FOUND)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{5, 8 * time.Minute},
+		{20, WEBHOOK_BACKOFF_MAX},
+	}
+
+	for _, test := range tests {
+		if got := webhookBackoff(test.attempts); got != test.want {
+			t.Errorf("%d attempts: got %v want %v", test.attempts, got, test.want)
+		}
+	}
+}
+
+func TestDispatchWebhookEvents(t *testing.T) {
+	company := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "12345", Website: "https://company.com"}
+	survivor := uuid.New()
+	recorded := &entity.Companies{ID: uuid.New(), Name: "OTHER COMPANY", Zip: "12345", Website: "https://other.com", Version: 2}
+	events := []*entity.CompanyEvent{
+		{ID: 1, Event: entity.EVENT_UPDATED, CompanyID: company.ID, ChangedFields: []string{entity.FIELD_WEBSITE}},
+		{ID: 2, Event: entity.EVENT_MERGED, CompanyID: uuid.New(), MergedInto: &survivor},
+		{ID: 3, Event: entity.EVENT_CREATED, CompanyID: uuid.New()},
+		{ID: 4, Event: entity.EVENT_UPDATED, CompanyID: recorded.ID, ChangedFields: []string{entity.FIELD_NAME}, Company: recorded},
+	}
+	crm := &entity.WebhookSubscription{ID: uuid.New(), Events: []string{entity.EVENT_UPDATED, entity.EVENT_MERGED}}
+	audit := &entity.WebhookSubscription{ID: uuid.New(), Events: []string{entity.EVENT_MERGED}}
+
+	var gotEventIDs []int64
+	var gotDeliveries []entity.WebhookDelivery
+	repository := &MockCompanyRepository{
+		ListPendingEventsMock: func(ctx context.Context, limit int) ([]*entity.CompanyEvent, error) {
+			return events, nil
+		},
+		ListWebhookSubscriptionsMock: func(ctx context.Context) ([]*entity.WebhookSubscription, error) {
+			return []*entity.WebhookSubscription{crm, audit}, nil
+		},
+		ReadCompanyByIDMock: func(ctx context.Context, id uuid.UUID, includeDeleted bool) (*entity.Companies, error) {
+			if id != company.ID {
+				t.Errorf("got a read of %v, the event records its company", id)
+			}
+			return company, nil
+		},
+		QueueWebhookDeliveriesMock: func(ctx context.Context, eventIDs []int64, deliveries []entity.WebhookDelivery) error {
+			gotEventIDs, gotDeliveries = eventIDs, deliveries
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, nil)
+
+	count, err := service.DispatchWebhookEvents(context.Background())
+
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	if count != 4 || len(gotEventIDs) != 4 {
+		t.Errorf("got %v events dispatched, %v marked want 4", count, gotEventIDs)
+	}
+	if len(gotDeliveries) != 4 {
+		t.Fatalf("got %v deliveries want 4", gotDeliveries)
+	}
+
+	var payload entity.WebhookPayload
+	json.Unmarshal(gotDeliveries[0].Payload, &payload)
+	if gotDeliveries[0].SubscriptionID != crm.ID || payload.Company == nil || payload.Company.Website != company.Website || payload.ChangedFields[0] != entity.FIELD_WEBSITE {
+		t.Errorf("got %+v, %+v", gotDeliveries[0], payload)
+	}
+
+	json.Unmarshal(gotDeliveries[2].Payload, &payload)
+	if gotDeliveries[2].SubscriptionID != audit.ID || payload.Event != entity.EVENT_MERGED || *payload.MergedInto != survivor {
+		t.Errorf("got %+v, %+v", gotDeliveries[2], payload)
+	}
+
+	payload = entity.WebhookPayload{}
+	json.Unmarshal(gotDeliveries[3].Payload, &payload)
+	if payload.Company == nil || payload.Company.Name != recorded.Name || payload.Company.Version != recorded.Version {
+		t.Errorf("got %+v want the company recorded with the event", payload)
+	}
+}
+
+func TestDeliverWebhooks(t *testing.T) {
+	newService := func(delivery *entity.WebhookDelivery, saved *entity.WebhookDelivery) *CompanyService {
+		claimed := false
+		repository := &MockCompanyRepository{
+			ClaimWebhookDeliveriesMock: func(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+				if limit != 1 || lease <= WEBHOOK_TIMEOUT {
+					t.Errorf("got a claim of %d for %v want 1 for longer than %v", limit, lease, WEBHOOK_TIMEOUT)
+				}
+				if claimed {
+					return nil, nil
+				}
+				claimed = true
+				return []*entity.WebhookDelivery{delivery}, nil
+			},
+			SaveWebhookDeliveryAttemptMock: func(ctx context.Context, delivery entity.WebhookDelivery) error {
+				*saved = delivery
+				return nil
+			},
+		}
+		return NewCompanyService(repository, nil)
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		var gotHeader http.Header
+		var gotBody []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotHeader = r.Header
+			gotBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		delivery := &entity.WebhookDelivery{ID: uuid.New(), Event: entity.EVENT_MERGED, Payload: []byte(`{"eventId":2}`), Status: entity.DELIVERY_PENDING, URL: receiver.URL, Secret: "secret"}
+		var saved entity.WebhookDelivery
+		service := newService(delivery, &saved)
+
+		delivered, err := service.DeliverWebhooks(context.Background())
+
+		if err != nil || delivered != 1 {
+			t.Fatalf("got %v, %v want 1, nil", delivered, err)
+		}
+		if string(gotBody) != `{"eventId":2}` || gotHeader.Get(WEBHOOK_EVENT_HEADER) != entity.EVENT_MERGED || gotHeader.Get(WEBHOOK_DELIVERY_HEADER) != delivery.ID.String() {
+			t.Errorf("got %v, %s", gotHeader, gotBody)
+		}
+		if want := SignWebhook("secret", gotHeader.Get(WEBHOOK_TIMESTAMP_HEADER), gotBody); gotHeader.Get(WEBHOOK_SIGNATURE_HEADER) != want {
+			t.Errorf("got signature %v want %v", gotHeader.Get(WEBHOOK_SIGNATURE_HEADER), want)
+		}
+		if saved.Status != entity.DELIVERY_DELIVERED || saved.Attempts != 1 || saved.DeliveredAt == nil {
+			t.Errorf("got %+v want delivered", saved)
+		}
+	})
+
+	t.Run("retrying later", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		delivery := &entity.WebhookDelivery{ID: uuid.New(), Payload: []byte(`{}`), Status: entity.DELIVERY_PENDING, Attempts: 2, URL: receiver.URL}
+		var saved entity.WebhookDelivery
+		service := newService(delivery, &saved)
+
+		delivered, err := service.DeliverWebhooks(context.Background())
+
+		if err != nil || delivered != 0 {
+			t.Fatalf("got %v, %v want 0, nil", delivered, err)
+		}
+		if saved.Status != entity.DELIVERY_PENDING || saved.Attempts != 3 || saved.LastError == "" {
+			t.Errorf("got %+v want pending", saved)
+		}
+		if wait := time.Until(saved.NextAttemptAt); wait < time.Minute || wait > 2*time.Minute {
+			t.Errorf("got the next attempt in %v want 2 minutes", wait)
+		}
+	})
+
+	t.Run("dead letter", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		delivery := &entity.WebhookDelivery{ID: uuid.New(), Payload: []byte(`{}`), Status: entity.DELIVERY_PENDING, Attempts: WEBHOOK_MAX_ATTEMPTS - 1, URL: receiver.URL}
+		var saved entity.WebhookDelivery
+		service := newService(delivery, &saved)
+
+		service.DeliverWebhooks(context.Background())
+
+		if saved.Status != entity.DELIVERY_DEAD || saved.Attempts != WEBHOOK_MAX_ATTEMPTS {
+			t.Errorf("got %+v want dead", saved)
+		}
+	})
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	repository := &MockCompanyRepository{
+		RetryWebhookDeliveryMock: func(ctx context.Context, id uuid.UUID) (bool, error) {
+			return false, nil
+		},
+	}
+	service := NewCompanyService(repository, nil)
+
+	if err := service.RetryWebhookDelivery(context.Background(), uuid.New()); !errors.Is(err, ERR_WEBHOOK_DELIVERY_NOT_DEAD) {
+		t.Errorf("got %v error want %v", err, ERR_WEBHOOK_DELIVERY_NOT_DEAD)
+	}
+}
@@ -0,0 +1,164 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"golang.org/x/net/idna"
+	"golang.org/x/net/publicsuffix"
+)
+
+var ERR_NOT_VALID_WEBSITE = errors.New("Error: the website is not a valid http or https URL")
+
+var (
+	hostLabel = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	topLabel  = regexp.MustCompile(`^([a-z]{2,63}|xn--[a-z0-9-]{1,59})$`)
+)
+
+// defaultPorts are dropped from canonical URLs
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// trackingParams are query parameters added by campaigns and ad networks,
+// they never identify a different page
+var trackingParams = map[string]bool{
+	"gclid": true, "dclid": true, "fbclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "_ga": true, "_gl": true, "igshid": true,
+}
+
+func isTrackingParam(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "utm_") || trackingParams[name]
+}
+
+// CanonicalizeWebsite parses an http or https URL and returns it in canonical
+// form along with its registrable domain: scheme and host lowercased, default
+// port, tracking parameters, fragment and trailing slash removed. IDN hosts
+// are accepted and kept in Unicode, e.g.
+//
+//	HTTPS://WWW.Example.co.uk:443/About/?utm_source=x  =>  https://www.example.co.uk/About, example.co.uk
+func CanonicalizeWebsite(raw string) (string, string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ERR_NOT_VALID_WEBSITE, err.Error())
+	}
+
+	scheme := strings.ToLower(parsed.Scheme)
+	if _, ok := defaultPorts[scheme]; !ok || parsed.Opaque != "" || parsed.User != nil {
+		return "", "", ERR_NOT_VALID_WEBSITE
+	}
+
+	host, err := canonicalHost(parsed.Hostname())
+	if err != nil {
+		return "", "", err
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s", ERR_NOT_VALID_WEBSITE, err.Error())
+	}
+
+	address := host
+	if port := parsed.Port(); port != "" && port != defaultPorts[scheme] {
+		number, err := strconv.Atoi(port)
+		if err != nil || number < 1 || number > 65535 {
+			return "", "", ERR_NOT_VALID_WEBSITE
+		}
+		address += ":" + port
+	}
+
+	query := parsed.Query()
+	for name := range query {
+		if isTrackingParam(name) {
+			query.Del(name)
+		}
+	}
+
+	canonical := url.URL{
+		Scheme:   scheme,
+		Host:     address,
+		Path:     strings.TrimRight(parsed.Path, "/"),
+		RawQuery: query.Encode(),
+	}
+	website := strings.Replace(canonical.String(), "//"+host, "//"+toUnicode(host), 1)
+	return website, toUnicode(domain), nil
+}
+
+// canonicalHost validates a host name, returning it lowercased in ASCII
+// (punycode) form
+func canonicalHost(host string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(strings.TrimSuffix(host, "."))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ERR_NOT_VALID_WEBSITE, err.Error())
+	}
+
+	labels := strings.Split(ascii, ".")
+	if len(labels) < 2 || !topLabel.MatchString(labels[len(labels)-1]) {
+		return "", ERR_NOT_VALID_WEBSITE
+	}
+	for _, label := range labels {
+		if !hostLabel.MatchString(label) {
+			return "", ERR_NOT_VALID_WEBSITE
+		}
+	}
+	return ascii, nil
+}
+
+// toUnicode turns a punycode host name back into Unicode
+func toUnicode(host string) string {
+	unicode, err := idna.Lookup.ToUnicode(host)
+	if err != nil {
+		return host
+	}
+	return unicode
+}
+
+// canonicalizeWebsite replaces the website of a company by its canonical form
+// and sets its domain. Invalid websites are left as they are for the
+// validation to reject.
+func canonicalizeWebsite(company *entity.Companies) {
+	if company.Website == "" {
+		company.Domain = ""
+		return
+	}
+
+	website, domain, err := CanonicalizeWebsite(company.Website)
+	if err != nil {
+		return
+	}
+	company.Website = website
+	company.Domain = domain
+}
+
+// CanonicalizeWebsites rewrites the stored websites that are not in canonical
+// form or lack their domain, returning how many companies were updated
+func (s *CompanyService) CanonicalizeWebsites(ctx context.Context) (int, error) {
+	companies, err := s.dbRepository.ListCompanies(ctx, entity.CompanyFilter{})
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	updated := 0
+	for _, company := range companies {
+		canonical := *company
+		canonicalizeWebsite(&canonical)
+		if canonical.Website == company.Website && canonical.Domain == company.Domain {
+			continue
+		}
+
+		err = s.dbRepository.UpdateCompany(ctx, canonical)
+		if err != nil {
+			return updated, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
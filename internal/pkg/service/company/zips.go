@@ -0,0 +1,183 @@
+package company
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+var (
+	ERR_NOT_VALID_ZIP_REFERENCE = errors.New("Error: the zip reference is not in the GeoNames postal code format")
+	ERR_ZIP_UNKNOWN             = errors.New("Error: the zip code is not in the zip code reference")
+)
+
+// locationFields are set from the zip code reference, they cannot be
+// registered as attributes
+var locationFields = []string{"city", "state"}
+
+func isLocationField(field string) bool {
+	for _, locationField := range locationFields {
+		if locationField == field {
+			return true
+		}
+	}
+	return false
+}
+
+var usZip = regexp.MustCompile(`^[0-9]{5}$`)
+
+// LoadZipReference reads the zip code reference from the repository. Until
+// one is loaded zip codes are only checked for their format.
+func (s *CompanyService) LoadZipReference(ctx context.Context) error {
+	references, err := s.dbRepository.ListZipReferences(ctx)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	zips := make(map[string]entity.ZipReference, len(references))
+	for _, reference := range references {
+		zips[reference.Zip] = *reference
+	}
+	s.zips = zips
+	return nil
+}
+
+// LookupZip returns the reference of a zip code
+func (s *CompanyService) LookupZip(zip string) (entity.ZipReference, bool) {
+	reference, ok := s.zips[zip]
+	return reference, ok
+}
+
+// locateZip sets the city and state of a US company from its zip code,
+// flagging the zip codes missing from the reference. The reference has no
+// postal code of other countries.
+func (s *CompanyService) locateZip(company *entity.Companies) {
+	if len(s.zips) == 0 {
+		return
+	}
+
+	if country, _ := NormalizeCountry(company.Country); country != entity.COUNTRY_US {
+		company.City = ""
+		company.State = ""
+		company.ZipUnknown = false
+		return
+	}
+
+	reference, ok := s.zips[PostalBase(entity.COUNTRY_US, company.Zip)]
+	company.City = reference.City
+	company.State = reference.State
+	company.ZipUnknown = !ok
+}
+
+// checkZipKnown rejects the US companies whose zip code is missing from the
+// reference. Postal codes of other countries are only checked for their format.
+func (s *CompanyService) checkZipKnown(company *entity.Companies) error {
+	if country, _ := NormalizeCountry(company.Country); country != entity.COUNTRY_US {
+		return nil
+	}
+
+	ok, err := s.CheckZipValidity(PostalBase(entity.COUNTRY_US, company.Zip))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ERR_ZIP_UNKNOWN
+	}
+	return nil
+}
+
+// ParseZipReference reads the US rows of a GeoNames postal code file
+// (https://download.geonames.org/export/zip/), tab separated:
+//
+//	country  zip  city  state name  state code  county  county code  community  community code  latitude  longitude  accuracy
+func ParseZipReference(reader io.Reader) ([]entity.ZipReference, error) {
+	references := []entity.ZipReference{}
+	scanner := bufio.NewScanner(reader)
+
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		columns := strings.Split(scanner.Text(), "\t")
+		if len(columns) < 11 {
+			return nil, fmt.Errorf("%w: line %d has %d columns", ERR_NOT_VALID_ZIP_REFERENCE, line, len(columns))
+		}
+		if columns[0] != "US" || !usZip.MatchString(columns[1]) {
+			continue
+		}
+
+		latitude, err := strconv.ParseFloat(columns[9], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d latitude %q", ERR_NOT_VALID_ZIP_REFERENCE, line, columns[9])
+		}
+		longitude, err := strconv.ParseFloat(columns[10], 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d longitude %q", ERR_NOT_VALID_ZIP_REFERENCE, line, columns[10])
+		}
+
+		references = append(references, entity.ZipReference{
+			Zip:       columns[1],
+			City:      strings.ToUpper(columns[2]),
+			State:     columns[4],
+			Latitude:  latitude,
+			Longitude: longitude,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ERR_NOT_VALID_ZIP_REFERENCE, err.Error())
+	}
+	return references, nil
+}
+
+// ImportZipReference stores the zip codes of a GeoNames postal code file and
+// reloads the reference, returning how many zip codes were read
+func (s *CompanyService) ImportZipReference(ctx context.Context, reader io.Reader) (int, error) {
+	references, err := ParseZipReference(reader)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.dbRepository.SaveZipReferences(ctx, references)
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+	}
+	return len(references), s.LoadZipReference(ctx)
+}
+
+// LocateCompanies updates the city, state and unknown zip flag of the stored
+// companies from the zip reference, returning how many companies changed
+func (s *CompanyService) LocateCompanies(ctx context.Context) (int, error) {
+	if len(s.zips) == 0 {
+		return 0, nil
+	}
+
+	companies, err := s.dbRepository.ListCompanies(ctx, entity.CompanyFilter{})
+	if err != nil {
+		return 0, fmt.Errorf("%v: %w", ERR_WHILE_GETTING_COMPANIES, err)
+	}
+
+	updated := 0
+	for _, company := range companies {
+		located := *company
+		s.locateZip(&located)
+		if located.City == company.City && located.State == company.State && located.ZipUnknown == company.ZipUnknown {
+			continue
+		}
+
+		err = s.dbRepository.UpdateCompany(ctx, located)
+		if err != nil {
+			return updated, fmt.Errorf("%v: %w", ERR_WHILE_WRITING, err)
+		}
+		updated++
+	}
+	return updated, nil
+}
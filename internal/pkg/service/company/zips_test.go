@@ -0,0 +1,175 @@
+package company
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	"github.com/google/uuid"
+)
+
+const zipReferenceFile = "US\t78229\tSan Antonio\tTexas\tTX\tBexar\t029\t\t\t29.5042\t-98.5697\t4\n" +
+	"US\t94002\tBelmont\tCalifornia\tCA\tSan Mateo\t081\t\t\t37.5155\t-122.2947\t4\n" +
+	"CA\tM5V\tToronto\tOntario\tON\t\t\t\t\t43.6426\t-79.3871\t6\n"
+
+func TestParseZipReference(t *testing.T) {
+	t.Run("US rows", func(t *testing.T) {
+		got, err := ParseZipReference(strings.NewReader(zipReferenceFile))
+		want := []entity.ZipReference{
+			{Zip: "78229", City: "SAN ANTONIO", State: "TX", Latitude: 29.5042, Longitude: -98.5697},
+			{Zip: "94002", City: "BELMONT", State: "CA", Latitude: 37.5155, Longitude: -122.2947},
+		}
+
+		if err != nil {
+			t.Errorf("got %v error, it should be nil", err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("got %v want %v", got, want)
+		}
+	})
+
+	t.Run("the bundled reference", func(t *testing.T) {
+		file, err := os.Open("../../../../data/zip_reference.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+
+		got, err := ParseZipReference(file)
+
+		if err != nil || len(got) == 0 {
+			t.Errorf("got %v zip codes, %v error want the bundled zip codes", len(got), err)
+		}
+	})
+
+	t.Run("not valid", func(t *testing.T) {
+		for _, file := range []string{"78229;San Antonio;TX\n", "US\t78229\tSan Antonio\tTexas\tTX\tBexar\t029\t\t\tnorth\t-98.5697\t4\n"} {
+			if _, err := ParseZipReference(strings.NewReader(file)); !errors.Is(err, ERR_NOT_VALID_ZIP_REFERENCE) {
+				t.Errorf("got %v error want %v", err, ERR_NOT_VALID_ZIP_REFERENCE)
+			}
+		}
+	})
+}
+
+func TestImportZipReference(t *testing.T) {
+	var saved []entity.ZipReference
+	repository := &MockCompanyRepository{
+		SaveZipReferencesMock: func(ctx context.Context, references []entity.ZipReference) error {
+			saved = references
+			return nil
+		},
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			references := []*entity.ZipReference{}
+			for index := range saved {
+				references = append(references, &saved[index])
+			}
+			return references, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	count, err := service.ImportZipReference(context.Background(), strings.NewReader(zipReferenceFile))
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if count != 2 || len(saved) != 2 {
+		t.Errorf("got %v zip codes want 2", count)
+	}
+	if reference, ok := service.LookupZip("78229"); !ok || reference.City != "SAN ANTONIO" {
+		t.Errorf("got %v, %v want SAN ANTONIO", reference, ok)
+	}
+}
+
+func TestLocateZip(t *testing.T) {
+	repository := &MockCompanyRepository{
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			return []*entity.ZipReference{{Zip: "78229", City: "SAN ANTONIO", State: "TX"}}, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+
+	company := &entity.Companies{Name: "COMPANY", Zip: "00000"}
+	service.locateZip(company)
+	if company.ZipUnknown || company.City != "" {
+		t.Errorf("got %v, without reference the zip code should not be flagged", company)
+	}
+
+	service.LoadZipReference(context.Background())
+
+	service.locateZip(company)
+	if !company.ZipUnknown {
+		t.Errorf("got %v want the zip code flagged", company)
+	}
+
+	company.Zip = "78229"
+	service.locateZip(company)
+	want := &entity.Companies{Name: "COMPANY", Zip: "78229", City: "SAN ANTONIO", State: "TX"}
+	if !reflect.DeepEqual(want, company) {
+		t.Errorf("got %v want %v", company, want)
+	}
+}
+
+func TestLocateCompanies(t *testing.T) {
+	located := &entity.Companies{ID: uuid.New(), Name: "COMPANY", Zip: "78229", City: "SAN ANTONIO", State: "TX"}
+	unknown := &entity.Companies{ID: uuid.New(), Name: "OTHER", Zip: "00000"}
+
+	updated := []entity.Companies{}
+	repository := &MockCompanyRepository{
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			return []*entity.ZipReference{{Zip: "78229", City: "SAN ANTONIO", State: "TX"}}, nil
+		},
+		ListCompaniesMock: func(ctx context.Context, filter entity.CompanyFilter) ([]*entity.Companies, error) {
+			return []*entity.Companies{located, unknown}, nil
+		},
+		UpdateCompanyMock: func(ctx context.Context, company entity.Companies) error {
+			updated = append(updated, company)
+			return nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+	service.LoadZipReference(context.Background())
+
+	count, err := service.LocateCompanies(context.Background())
+
+	if err != nil {
+		t.Errorf("got %v error, it should be nil", err)
+	}
+	if count != 1 || len(updated) != 1 || updated[0].ID != unknown.ID || !updated[0].ZipUnknown {
+		t.Errorf("got %v updated want %v flagged", updated, unknown.ID)
+	}
+}
+
+func TestCheckCompanyZipKnown(t *testing.T) {
+	repository := &MockCompanyRepository{
+		ListZipReferencesMock: func(ctx context.Context) ([]*entity.ZipReference, error) {
+			return []*entity.ZipReference{{Zip: "78229", City: "SAN ANTONIO", State: "TX"}}, nil
+		},
+	}
+	service := NewCompanyService(repository, repository)
+	service.LoadZipReference(context.Background())
+
+	tests := []struct {
+		name    string
+		company entity.Companies
+		want    error
+	}{
+		{"known zip code", entity.Companies{Name: "COMPANY", Zip: "78229-1234", Website: "http://company.com"}, nil},
+		{"unknown zip code", entity.Companies{Name: "COMPANY", Zip: "00000", Website: "http://company.com"}, ERR_ZIP_UNKNOWN},
+		{"postal code of another country", entity.Companies{Name: "COMPANY", Zip: "M5V 3L9", Country: entity.COUNTRY_CA, Website: "http://company.ca"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.checkCompany(&tt.company)
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v error want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package company
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+	ingest "github.com/eduardojabes/data-integration-challenge/internal/pkg/ingest/company"
+	recordsRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/records"
+	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
+)
+
+// Folders of the watched directory the handled files are moved to
+const (
+	PROCESSED_DIR = "processed"
+	FAILED_DIR    = "failed"
+)
+
+// REPORT_SUFFIX is appended to the name of a handled file to name its report
+const REPORT_SUFFIX = ".report.json"
+
+var ERR_EMPTY_FILE = ingest.ERR_EMPTY_FILE
+var ERR_RECORDS_NOT_MERGED = errors.New("Error: records of the file were rejected or could not be merged")
+
+type CompanyService interface {
+	MergeCompanies(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport
+	GetImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error)
+	FindIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error)
+	RecordIngestedFile(ctx context.Context, file entity.IngestedFile) error
+}
+
+// Options tune a Watcher. Profile names the import profile the files are
+// read with, the default CSV layout when empty.
+type Options struct {
+	Interval time.Duration
+	Source   string
+	Profile  string
+}
+
+// fileState is the size and modification time a file had on a scan
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher merges the CSV files dropped in a directory. A file is only picked
+// up once it is the same on two scans in a row, so files still being written
+// are left alone.
+type Watcher struct {
+	service CompanyService
+	dir     string
+	options Options
+	seen    map[string]fileState
+	now     func() time.Time
+}
+
+func NewWatcher(service CompanyService, dir string, options Options) *Watcher {
+	if options.Source == "" {
+		options.Source = companyService.SOURCE_WATCH_FOLDER
+	}
+	return &Watcher{
+		service: service,
+		dir:     dir,
+		options: options,
+		seen:    map[string]fileState{},
+		now:     time.Now,
+	}
+}
+
+// isWatchedFile tells whether a file of the directory is a CSV file to merge,
+// leaving out hidden files and the partial files of uploads
+func isWatchedFile(name string) bool {
+	name = strings.ToLower(name)
+	if strings.HasPrefix(name, ".") {
+		return false
+	}
+	return strings.HasSuffix(name, ".csv") || strings.HasSuffix(name, ".csv.gz")
+}
+
+// Run scans the directory every interval until the context is done
+func (w *Watcher) Run(ctx context.Context) error {
+	for _, dir := range []string{PROCESSED_DIR, FAILED_DIR} {
+		if err := os.MkdirAll(filepath.Join(w.dir, dir), 0o755); err != nil {
+			return err
+		}
+	}
+
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Scan(ctx); err != nil {
+			log.Printf("Unable to scan %s: %v", w.dir, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Scan handles the files that did not change since the previous scan,
+// returning their reports
+func (w *Watcher) Scan(ctx context.Context) ([]*entity.WatchReport, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	current := map[string]fileState{}
+	ready := []string{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || !isWatchedFile(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		if previous, ok := w.seen[entry.Name()]; ok && previous == state {
+			ready = append(ready, entry.Name())
+			continue
+		}
+		current[entry.Name()] = state
+	}
+	w.seen = current
+	sort.Strings(ready)
+
+	reports := []*entity.WatchReport{}
+	for _, name := range ready {
+		if ctx.Err() != nil {
+			break
+		}
+		report := w.process(ctx, name)
+		if report.Error != "" {
+			log.Printf("Watched file %s %s: %s", name, report.Status, report.Error)
+		} else {
+			log.Printf("Watched file %s %s", name, report.Status)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// process merges a file and moves it along with its report to the processed
+// or failed folder. A file fails when one of its records was rejected or could
+// not be merged. Only merged files are recorded, so a file that failed can be
+// dropped again once fixed.
+func (w *Watcher) process(ctx context.Context, name string) *entity.WatchReport {
+	path := filepath.Join(w.dir, name)
+	report := &entity.WatchReport{File: name, Status: entity.WATCH_STATUS_PROCESSED}
+
+	err := w.merge(ctx, path, report)
+	if err != nil {
+		report.Status = entity.WATCH_STATUS_FAILED
+		report.Error = err.Error()
+	}
+	report.ProcessedAt = w.now().UTC()
+
+	dir := PROCESSED_DIR
+	if report.Status == entity.WATCH_STATUS_FAILED {
+		dir = FAILED_DIR
+	}
+	if err := w.move(path, dir, report); err != nil {
+		log.Printf("Unable to move %s to %s: %v", name, dir, err)
+	}
+	return report
+}
+
+func (w *Watcher) merge(ctx context.Context, path string, report *entity.WatchReport) error {
+	checksum, err := fileChecksum(path)
+	if err != nil {
+		return err
+	}
+	report.Checksum = checksum
+
+	ingested, err := w.service.FindIngestedFile(ctx, checksum)
+	if err != nil {
+		return err
+	}
+	if ingested != nil {
+		report.Status = entity.WATCH_STATUS_DUPLICATE
+		report.DuplicateOf = ingested
+		return nil
+	}
+
+	var profile *entity.ImportProfile
+	if w.options.Profile != "" {
+		profile, err = w.service.GetImportProfile(ctx, w.options.Profile)
+		if err != nil {
+			return err
+		}
+		if profile == nil {
+			return fmt.Errorf("%w: %s", companyService.ERR_IMPORT_PROFILE_NOT_FOUND, w.options.Profile)
+		}
+	}
+
+	companies, err := ingest.ReadFile(ctx, path, ingest.Options{Format: recordsRepository.FORMAT_CSV, Profile: profile})
+	if err != nil {
+		return err
+	}
+
+	source := companyService.ProfileSource(w.options.Source, filepath.Base(path), profile)
+	report.Report = w.service.MergeCompanies(ctx, companies, source)
+	if report.Report != nil && (report.Report.Rejected > 0 || report.Report.Failed > 0) {
+		return fmt.Errorf("%w: %d rejected, %d failed", ERR_RECORDS_NOT_MERGED, report.Report.Rejected, report.Report.Failed)
+	}
+
+	return w.service.RecordIngestedFile(ctx, entity.IngestedFile{Checksum: checksum, FileName: filepath.Base(path), ProcessedAt: w.now().UTC()})
+}
+
+func fileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// move puts the file in the folder under a name starting with the time it
+// was handled, so files dropped twice under the same name are both kept, and
+// writes its report next to it
+func (w *Watcher) move(path string, dir string, report *entity.WatchReport) error {
+	name := report.ProcessedAt.Format("20060102T150405.000000000Z") + "_" + filepath.Base(path)
+	target := filepath.Join(w.dir, dir, name)
+
+	if err := os.MkdirAll(filepath.Join(w.dir, dir), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(path, target); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(target+REPORT_SUFFIX, content, 0o644)
+}
@@ -0,0 +1,237 @@
+package company
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/entity"
+)
+
+type MockCompanyService struct {
+	MergeCompaniesMock     func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport
+	GetImportProfileMock   func(ctx context.Context, name string) (*entity.ImportProfile, error)
+	FindIngestedFileMock   func(ctx context.Context, checksum string) (*entity.IngestedFile, error)
+	RecordIngestedFileMock func(ctx context.Context, file entity.IngestedFile) error
+}
+
+func (mcs *MockCompanyService) MergeCompanies(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+	if mcs.MergeCompaniesMock != nil {
+		return mcs.MergeCompaniesMock(ctx, companies, source)
+	}
+	return nil
+}
+
+func (mcs *MockCompanyService) GetImportProfile(ctx context.Context, name string) (*entity.ImportProfile, error) {
+	if mcs.GetImportProfileMock != nil {
+		return mcs.GetImportProfileMock(ctx, name)
+	}
+	return nil, errors.New("GetImportProfileMock")
+}
+
+func (mcs *MockCompanyService) FindIngestedFile(ctx context.Context, checksum string) (*entity.IngestedFile, error) {
+	if mcs.FindIngestedFileMock != nil {
+		return mcs.FindIngestedFileMock(ctx, checksum)
+	}
+	return nil, errors.New("FindIngestedFileMock")
+}
+
+func (mcs *MockCompanyService) RecordIngestedFile(ctx context.Context, file entity.IngestedFile) error {
+	if mcs.RecordIngestedFileMock != nil {
+		return mcs.RecordIngestedFileMock(ctx, file)
+	}
+	return errors.New("RecordIngestedFileMock")
+}
+
+// newMergingService merges every file into a report of its companies,
+// remembering the checksums like the database does
+func newMergingService(merged *[]*entity.Companies) *MockCompanyService {
+	ingested := map[string]entity.IngestedFile{}
+	return &MockCompanyService{
+		MergeCompaniesMock: func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+			*merged = append(*merged, companies...)
+			return &entity.MergeReport{SourceSystem: source.System, SourceFile: source.File, Total: len(companies)}
+		},
+		FindIngestedFileMock: func(ctx context.Context, checksum string) (*entity.IngestedFile, error) {
+			if file, ok := ingested[checksum]; ok {
+				return &file, nil
+			}
+			return nil, nil
+		},
+		RecordIngestedFileMock: func(ctx context.Context, file entity.IngestedFile) error {
+			ingested[file.Checksum] = file
+			return nil
+		},
+	}
+}
+
+func readReport(t *testing.T, dir string) *entity.WatchReport {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"+REPORT_SUFFIX))
+	if len(matches) != 1 {
+		t.Fatalf("got reports %v in %v want one", matches, dir)
+	}
+	content, _ := os.ReadFile(matches[0])
+
+	var report entity.WatchReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		t.Fatalf("got %v error reading the report", err)
+	}
+	if _, err := os.Stat(matches[0][:len(matches[0])-len(REPORT_SUFFIX)]); err != nil {
+		t.Errorf("got %v want the file next to its report", err)
+	}
+	return &report
+}
+
+func TestScan(t *testing.T) {
+	const file = "name;addresszip;website\ntola sales group;78229;http://repsources.com\n"
+
+	t.Run("waiting for the file to settle", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "partner.csv"), []byte(file), 0o644)
+		os.WriteFile(filepath.Join(dir, "partner.csv.part"), []byte(file), 0o644)
+
+		var merged []*entity.Companies
+		watcher := NewWatcher(newMergingService(&merged), dir, Options{})
+
+		reports, err := watcher.Scan(context.Background())
+		if err != nil || len(reports) != 0 {
+			t.Fatalf("got %v, %v want nothing on the first scan", reports, err)
+		}
+
+		reports, _ = watcher.Scan(context.Background())
+		if len(reports) != 1 || reports[0].Status != entity.WATCH_STATUS_PROCESSED || len(merged) != 1 || merged[0].Name != "TOLA SALES GROUP" {
+			t.Fatalf("got %v, %v", reports, merged)
+		}
+		if reports[0].Report.SourceSystem != "watch-folder" || reports[0].Report.SourceFile != "partner.csv" {
+			t.Errorf("got %+v", reports[0].Report)
+		}
+
+		report := readReport(t, filepath.Join(dir, PROCESSED_DIR))
+		if report.Checksum == "" || report.Report.Total != 1 {
+			t.Errorf("got %+v", report)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "partner.csv")); !os.IsNotExist(err) {
+			t.Errorf("got %v want the file moved", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "partner.csv.part")); err != nil {
+			t.Errorf("got %v want the partial file left alone", err)
+		}
+	})
+
+	t.Run("changing file", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "partner.csv")
+		os.WriteFile(path, []byte("name;addresszip;website\n"), 0o644)
+
+		var merged []*entity.Companies
+		watcher := NewWatcher(newMergingService(&merged), dir, Options{})
+		watcher.Scan(context.Background())
+
+		os.WriteFile(path, []byte(file), 0o644)
+		os.Chtimes(path, time.Now(), time.Now().Add(time.Second))
+
+		if reports, _ := watcher.Scan(context.Background()); len(reports) != 0 {
+			t.Errorf("got %v want the file left while it changes", reports)
+		}
+	})
+
+	t.Run("same file twice", func(t *testing.T) {
+		dir := t.TempDir()
+
+		var merged []*entity.Companies
+		watcher := NewWatcher(newMergingService(&merged), dir, Options{})
+
+		for _, name := range []string{"monday.csv", "tuesday.csv"} {
+			os.WriteFile(filepath.Join(dir, name), []byte(file), 0o644)
+			watcher.Scan(context.Background())
+			watcher.Scan(context.Background())
+		}
+
+		if len(merged) != 1 {
+			t.Errorf("got %v merged want the content merged once", merged)
+		}
+		matches, _ := filepath.Glob(filepath.Join(dir, PROCESSED_DIR, "*tuesday.csv"+REPORT_SUFFIX))
+		if len(matches) != 1 {
+			t.Fatalf("got %v want the report of the duplicate", matches)
+		}
+		content, _ := os.ReadFile(matches[0])
+		var report entity.WatchReport
+		json.Unmarshal(content, &report)
+		if report.Status != entity.WATCH_STATUS_DUPLICATE || report.DuplicateOf.FileName != "monday.csv" {
+			t.Errorf("got %+v want a duplicate of monday.csv", report)
+		}
+	})
+
+	t.Run("failed file", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "empty.csv"), []byte(""), 0o644)
+
+		var merged []*entity.Companies
+		service := newMergingService(&merged)
+		recorded := false
+		service.RecordIngestedFileMock = func(ctx context.Context, file entity.IngestedFile) error {
+			recorded = true
+			return nil
+		}
+		watcher := NewWatcher(service, dir, Options{})
+		watcher.Scan(context.Background())
+		watcher.Scan(context.Background())
+
+		report := readReport(t, filepath.Join(dir, FAILED_DIR))
+		if report.Status != entity.WATCH_STATUS_FAILED || report.Error != ERR_EMPTY_FILE.Error() {
+			t.Errorf("got %+v want failed", report)
+		}
+		if recorded {
+			t.Errorf("got the failed file recorded, it should be merged when dropped again")
+		}
+	})
+
+	t.Run("records not merged", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "partner.csv"), []byte(file), 0o644)
+
+		var merged []*entity.Companies
+		service := newMergingService(&merged)
+		service.MergeCompaniesMock = func(ctx context.Context, companies []*entity.Companies, source entity.Source) *entity.MergeReport {
+			return &entity.MergeReport{Total: len(companies), Failed: len(companies)}
+		}
+		recorded := false
+		service.RecordIngestedFileMock = func(ctx context.Context, file entity.IngestedFile) error {
+			recorded = true
+			return nil
+		}
+		watcher := NewWatcher(service, dir, Options{})
+		watcher.Scan(context.Background())
+		watcher.Scan(context.Background())
+
+		report := readReport(t, filepath.Join(dir, FAILED_DIR))
+		if report.Status != entity.WATCH_STATUS_FAILED || report.Report == nil || report.Report.Failed != 1 {
+			t.Errorf("got %+v want failed with its merge report", report)
+		}
+		if recorded {
+			t.Errorf("got the failed file recorded, it should be merged when dropped again")
+		}
+	})
+
+	t.Run("with a profile", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "partner.csv"), []byte("Company|Postal\ntola sales group|78229\n"), 0o644)
+
+		var merged []*entity.Companies
+		service := newMergingService(&merged)
+		service.GetImportProfileMock = func(ctx context.Context, name string) (*entity.ImportProfile, error) {
+			return &entity.ImportProfile{Name: name, Delimiter: "|", Columns: map[string]string{"Company": entity.IMPORT_FIELD_NAME, "Postal": entity.IMPORT_FIELD_ZIP}}, nil
+		}
+		watcher := NewWatcher(service, dir, Options{Profile: "partner"})
+		watcher.Scan(context.Background())
+		watcher.Scan(context.Background())
+
+		if len(merged) != 1 || merged[0].Zip != "78229" {
+			t.Errorf("got %v want the file read with the profile", merged)
+		}
+	})
+}
@@ -13,6 +13,10 @@ type Route struct {
 	HandlerFunc http.HandlerFunc
 }
 
+// uuidPattern restricts {id} route variables so they never shadow the
+// fixed paths under /v1/companies
+const uuidPattern = "[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}"
+
 //var connector = CompanyConnector.NewCompanyConnector()
 
 type Routes []Route
@@ -54,6 +58,180 @@ func (c *Handler) AddRoutesToConnector() {
 			"/v1/companies/merge-all-companies",
 			c.connector.MergeCompanies,
 		},
+		Route{
+			"ValidateCompanies",
+			"POST",
+			"/v1/companies/validate",
+			c.connector.ValidateCompanies,
+		},
+		Route{
+			"GetCompany",
+			"GET",
+			"/v1/companies/{id:" + uuidPattern + "}",
+			c.connector.GetCompanyByID,
+		},
+		Route{
+			"UpdateCompany",
+			"PUT",
+			"/v1/companies/{id:" + uuidPattern + "}",
+			c.connector.UpdateCompany,
+		},
+		Route{
+			"PatchCompany",
+			"PATCH",
+			"/v1/companies/{id:" + uuidPattern + "}",
+			c.connector.PatchCompany,
+		},
+		Route{
+			"DeleteCompany",
+			"DELETE",
+			"/v1/companies/{id:" + uuidPattern + "}",
+			c.connector.DeleteCompany,
+		},
+		Route{
+			"GetAttributeSchema",
+			"GET",
+			"/v1/company-attributes",
+			c.connector.GetAttributeSchema,
+		},
+		Route{
+			"RegisterAttribute",
+			"PUT",
+			"/v1/company-attributes/{name}",
+			c.connector.RegisterAttribute,
+		},
+		Route{
+			"GetImportProfiles",
+			"GET",
+			"/v1/import-profiles",
+			c.connector.GetImportProfiles,
+		},
+		Route{
+			"GetImportProfile",
+			"GET",
+			"/v1/import-profiles/{name}",
+			c.connector.GetImportProfile,
+		},
+		Route{
+			"PutImportProfile",
+			"PUT",
+			"/v1/import-profiles/{name}",
+			c.connector.PutImportProfile,
+		},
+		Route{
+			"DeleteImportProfile",
+			"DELETE",
+			"/v1/import-profiles/{name}",
+			c.connector.DeleteImportProfile,
+		},
+		Route{
+			"GetWebhooks",
+			"GET",
+			"/v1/webhooks",
+			c.connector.GetWebhooks,
+		},
+		Route{
+			"PostWebhook",
+			"POST",
+			"/v1/webhooks",
+			c.connector.PostWebhook,
+		},
+		Route{
+			"GetWebhook",
+			"GET",
+			"/v1/webhooks/{id:" + uuidPattern + "}",
+			c.connector.GetWebhook,
+		},
+		Route{
+			"PutWebhook",
+			"PUT",
+			"/v1/webhooks/{id:" + uuidPattern + "}",
+			c.connector.PutWebhook,
+		},
+		Route{
+			"DeleteWebhook",
+			"DELETE",
+			"/v1/webhooks/{id:" + uuidPattern + "}",
+			c.connector.DeleteWebhook,
+		},
+		Route{
+			"GetWebhookDeliveries",
+			"GET",
+			"/v1/webhooks/{id:" + uuidPattern + "}/deliveries",
+			c.connector.GetWebhookDeliveries,
+		},
+		Route{
+			"RetryWebhookDelivery",
+			"POST",
+			"/v1/webhooks/deliveries/{id:" + uuidPattern + "}/retry",
+			c.connector.RetryWebhookDelivery,
+		},
+		Route{
+			"RestoreCompany",
+			"POST",
+			"/v1/companies/{id:" + uuidPattern + "}/restore",
+			c.connector.RestoreCompany,
+		},
+		Route{
+			"GetCompanyBySource",
+			"GET",
+			"/v1/companies/by-source/{source}/{externalId}",
+			c.connector.GetCompanyBySource,
+		},
+		Route{
+			"MergeInto",
+			"POST",
+			"/v1/companies/{id:" + uuidPattern + "}/merge-into/{targetId:" + uuidPattern + "}",
+			c.connector.MergeInto,
+		},
+		Route{
+			"GetLocations",
+			"GET",
+			"/v1/companies/{id:" + uuidPattern + "}/locations",
+			c.connector.GetLocations,
+		},
+		Route{
+			"ExportCompanies",
+			"GET",
+			"/v1/companies/export",
+			c.connector.ExportCompanies,
+		},
+		Route{
+			"GetCompanyChanges",
+			"GET",
+			"/v1/companies/changes",
+			c.connector.GetCompanyChanges,
+		},
+		Route{
+			"GetNearbyCompanies",
+			"GET",
+			"/v1/companies/nearby",
+			c.connector.GetNearbyCompanies,
+		},
+		Route{
+			"GetDuplicates",
+			"GET",
+			"/v1/companies/duplicates",
+			c.connector.GetDuplicates,
+		},
+		Route{
+			"DetectDuplicates",
+			"POST",
+			"/v1/companies/duplicates/detect",
+			c.connector.DetectDuplicates,
+		},
+		Route{
+			"ConfirmDuplicate",
+			"POST",
+			"/v1/companies/duplicates/{id:" + uuidPattern + "}/confirm",
+			c.connector.ConfirmDuplicate,
+		},
+		Route{
+			"RejectDuplicate",
+			"POST",
+			"/v1/companies/duplicates/{id:" + uuidPattern + "}/reject",
+			c.connector.RejectDuplicate,
+		},
 	}
 }
 
@@ -12,6 +12,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
 	"testing"
 
@@ -20,7 +21,7 @@ import (
 	dbRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/postgreSQL"
 	companyService "github.com/eduardojabes/data-integration-challenge/internal/pkg/service/company"
 	routes "github.com/eduardojabes/data-integration-challenge/module/features/routes/company"
-	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
 )
 
 var (
@@ -80,7 +81,7 @@ func TestIntegration(t *testing.T) {
 	ctx, _ := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	ctx, cancel := context.WithCancel(ctx)
 	// Set up connection to DB
-	conn, err := pgx.Connect(context.Background(), DatabaseUrl)
+	conn, err := pgxpool.Connect(context.Background(), DatabaseUrl)
 	if err != nil {
 		log.Fatalf("Unable to connect to database: %v\n", err)
 	}
@@ -129,8 +130,8 @@ func TestIntegration(t *testing.T) {
 
 		var emptyCompany entity.Companies
 
-		if readCompany == emptyCompany {
-			t.Errorf("The request need a response, but got %s", readCompany)
+		if reflect.DeepEqual(readCompany, emptyCompany) {
+			t.Errorf("The request need a response, but got %v", readCompany)
 		}
 	})
 
@@ -146,8 +147,8 @@ func TestIntegration(t *testing.T) {
 
 		var emptyCompany entity.Companies
 
-		if readCompany != emptyCompany {
-			t.Errorf("The request need a response, but got %s", readCompany)
+		if !reflect.DeepEqual(readCompany, emptyCompany) {
+			t.Errorf("The request need a response, but got %v", readCompany)
 		}
 
 	})
@@ -185,9 +186,5 @@ func TestIntegration(t *testing.T) {
 	<-ctx.Done()
 
 	log.Print("The server has been closed")
-	shutdownCtx := context.Background()
-	err = conn.Close(shutdownCtx)
-	if err != nil {
-		panic(err)
-	}
+	conn.Close()
 }
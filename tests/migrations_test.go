@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/eduardojabes/data-integration-challenge/deployment/migrations"
+	dbRepository "github.com/eduardojabes/data-integration-challenge/internal/pkg/repository/company/postgreSQL"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TestMigrateFreshDatabase applies the embedded migrations to an empty
+// schema, as on a new deployment, then checks nothing is left to apply
+func TestMigrateFreshDatabase(t *testing.T) {
+	ctx := context.Background()
+	conn, err := pgxpool.Connect(ctx, DatabaseUrl)
+	if err != nil {
+		t.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer conn.Close()
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	if _, err := conn.Exec(ctx, "CREATE SCHEMA "+schema); err != nil {
+		t.Fatalf("Unable to create the schema: %v", err)
+	}
+	defer conn.Exec(context.Background(), "DROP SCHEMA "+schema+" CASCADE")
+
+	// every table of the migrations is created in the new schema
+	config, err := pgxpool.ParseConfig(DatabaseUrl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.ConnConfig.RuntimeParams["search_path"] = schema
+	fresh, err := pgxpool.ConnectConfig(ctx, config)
+	if err != nil {
+		t.Fatalf("Unable to connect to database: %v", err)
+	}
+	defer fresh.Close()
+
+	repository := dbRepository.NewPostgreCompanyRepository(fresh)
+	files, _ := fs.Glob(migrations.FS, "*.sql")
+
+	applied, err := repository.Migrate(ctx, migrations.FS)
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	if len(applied) != len(files) {
+		t.Errorf("got %d migrations applied want %d", len(applied), len(files))
+	}
+
+	status, err := repository.MigrationStatus(ctx, migrations.FS)
+	if err != nil {
+		t.Fatalf("got %v error, it should be nil", err)
+	}
+	for _, migration := range status {
+		if !migration.Applied {
+			t.Errorf("got %d_%s pending want applied", migration.Version, migration.Name)
+		}
+	}
+
+	again, err := repository.Migrate(ctx, migrations.FS)
+	if err != nil || len(again) != 0 {
+		t.Errorf("got %v, %v want nothing left to apply", again, err)
+	}
+}